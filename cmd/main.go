@@ -1,59 +1,421 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	stdtime "time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/abac"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/apikey"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/app"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/audit"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/auth"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/certs"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/config"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/event"
 	fiber_handler "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/handler"
+	fiber_middleware "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/middleware"
 	fiber_server "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/server"
 	grpc_server "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/grpc/server"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/health"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/jwt"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/logging"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/metrics"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/rbac"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/secret"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/time"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tracing"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/validation"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/proto/pb"
 	common_crypto "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/crypto"
-	common_fiber "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/fiber"
-	common_grpc "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/grpc"
-	common_vault "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/vault"
 	user_pb "github.com/Bit-Bridge-Source/BitBridge-UserService-Go/proto/pb"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
-	// Initialize vault client and read secret for authentication
-	vaultClient, err := common_vault.NewVault("http://127.0.0.1:8200", "XZ5!Ojk88#Ox8PoM!yZhiJfHs")
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
 		panic(err)
 	}
-	vaultSecret, err := vaultClient.ReadSecret("secret/data/jwt_secret")
+
+	logger, err := newLogger(&cfg.Logging)
+	if err != nil {
+		panic(err)
+	}
+	// Packages log through the default logger, with the request ID of the context they are given
+	slog.SetDefault(logger)
+
+	// Cancelled on SIGINT or SIGTERM, which stops the background work and drains the servers
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	secrets, err := newSecretProvider(ctx, cfg)
+	if err != nil {
+		panic(err)
+	}
+	// Fail fast when the signing key cannot be read
+	if _, err := secrets.Secret(ctx, cfg.Vault.JWTSecretPath); err != nil {
+		panic(err)
+	}
+
+	// Trace context is passed on to the user service whether or not spans are recorded
+	otel.SetTextMapPropagator(tracing.Propagator())
+	closers := []io.Closer{}
+	tracerProvider, err := newTracerProvider(ctx, &cfg.Tracing)
+	if err != nil {
+		panic(err)
+	}
+	if tracerProvider != nil {
+		tracerProvider.Install()
+		closers = append(closers, tracerProvider)
+	}
+
+	// Metrics of the service and of the Go runtime are served on /metrics
+	metricsRegistry := prometheus.NewRegistry()
+	metricsRegistry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	appMetrics, err := metrics.NewMetrics(metricsRegistry)
 	if err != nil {
 		panic(err)
 	}
 
 	// Initialize gRPC connection to user service
-	grpcConnector := &common_grpc.GrpcConnector{}
-	grpcUserConnection, err := grpcConnector.Connect("localhost:3001")
+	userServiceCredentials, err := clientCredentials(ctx, cfg, cfg.UserService.Address, &cfg.UserService.TLS)
+	if err != nil {
+		panic(err)
+	}
+	grpcUserConnection, err := grpc.Dial(cfg.UserService.Address,
+		grpc.WithTransportCredentials(userServiceCredentials),
+		grpc.WithChainUnaryInterceptor(tracing.UserServiceInterceptor(), logging.UserServiceInterceptor(), metrics.UserServiceInterceptor(appMetrics)),
+	)
 	if err != nil {
 		panic(err)
 	}
 
 	// Initialize gRPC client for user service
 	grpUserClient := user_pb.NewUserServiceClient(grpcUserConnection)
-	jwtHandler := jwt.NewSimpleJWTHandler(vaultSecret)
-	tokenService := token.NewTokenService(time.NewSystemTime(), jwtHandler)
+	// Each tenant signs its tokens with its own key; requests without a tenant belong to the default tenant.
+	tenantKeys := make(map[string]string, len(cfg.Tenants))
+	hostTenants := map[string]string{}
+	for tenantID, tenantConfig := range cfg.Tenants {
+		tenantKeys[tenantID] = "secret/data/tenants/" + tenantID + "/jwt_secret"
+		if _, err := secrets.Secret(ctx, tenantKeys[tenantID]); err != nil {
+			panic(err)
+		}
+		for _, host := range tenantConfig.Hosts {
+			hostTenants[host] = tenantID
+		}
+	}
+	tenantResolver := tenant.NewResolver(tenant.DefaultHeader, hostTenants)
+	jwtHandler := jwt.NewTenantJWTHandler(secrets, cfg.Vault.JWTSecretPath, tenantKeys)
+	tokenPolicies := newTokenPolicies(&cfg.Token)
+	if err := tokenPolicies.Validate(); err != nil {
+		panic(err)
+	}
+	// Each client's maximum session is enforced when its tokens are issued; sessions past the longest
+	// of them can no longer be refreshed by any client and are dropped
+	sessionTimeouts := session.Timeouts{Idle: cfg.Session.IdleTimeout, Absolute: tokenPolicies.LongestSession()}
+	rbacPolicy, err := rbac.LoadPolicy(cfg.Policy.RBACFile)
+	if err != nil {
+		panic(err)
+	}
+	rbacEvaluator, err := rbac.NewEvaluator(rbacPolicy)
+	if err != nil {
+		panic(err)
+	}
+	userFieldsEnricher, err := token.NewUserFieldsEnricher(map[string]string{"username": "preferred_username", "email": "email"})
+	if err != nil {
+		panic(err)
+	}
+	claimsEnricher := token.NewEnricherChain(
+		token.EnricherStep{
+			Name:      "default-grant",
+			Enricher:  &token.StaticGrant{Scopes: []string{"profile", "sessions"}, Roles: []string{"user"}},
+			OnFailure: token.FailClosed,
+		},
+		token.EnricherStep{
+			Name:      "assigned-roles",
+			Enricher:  &token.AssignedRoles{Source: rbacEvaluator},
+			OnFailure: token.FailClosed,
+		},
+		token.EnricherStep{
+			Name:      "user-fields",
+			Enricher:  userFieldsEnricher,
+			Timeout:   100 * stdtime.Millisecond,
+			OnFailure: token.FailOpen,
+		},
+	)
+	tokenService := metrics.NewTokenService(token.NewTokenService(time.NewSystemTime(), jwtHandler, tokenPolicies, claimsEnricher), appMetrics)
+	sessionStore := session.NewMemoryStore()
+	sessionService := session.NewSessionService(sessionStore, time.NewSystemTime(), sessionTimeouts)
 	cryptoService := common_crypto.NewCrypto()
 
-	authService := auth.NewAuthService(tokenService, cryptoService, grpUserClient)
+	// Audit events go to the log and to a JSON-lines file that admins can query
+	auditFile, err := audit.NewFileSink(cfg.Audit.File)
+	if err != nil {
+		panic(err)
+	}
+	auditRecorder := audit.NewRecorder(audit.NewMultiSink(audit.NewLogSink(), auditFile), time.NewSystemTime())
+	auditService := audit.NewAuditService(auditFile)
+	auditedSessionService := audit.NewSessionService(sessionService, auditRecorder)
+
+	// Domain events wait in a local outbox until they are delivered to the webhook. Without a webhook
+	// they are discarded.
+	var eventPublisher event.IPublisher = event.NopPublisher{}
+	if cfg.Events.WebhookURL != "" {
+		webhookSecret, err := secrets.Secret(ctx, cfg.Events.WebhookSecretPath)
+		if err != nil {
+			panic(err)
+		}
+		eventOutbox, err := event.NewFileOutbox(cfg.Events.OutboxDir)
+		if err != nil {
+			panic(err)
+		}
+		webhookSink := event.NewWebhookSink(cfg.Events.WebhookURL, webhookSecret, &http.Client{Timeout: cfg.Events.WebhookTimeout}, time.NewSystemTime())
+		eventDispatcher := event.NewDispatcher(eventOutbox, webhookSink, time.NewSystemTime(), event.DefaultRetryPolicy())
+		go eventDispatcher.Run(ctx, cfg.Events.DispatchInterval)
+		eventPublisher = event.NewOutboxPublisher(eventOutbox, time.NewSystemTime())
+	}
+
+	authService := metrics.NewAuthService(auth.NewAuthService(tokenService, cryptoService, grpUserClient, sessionService, auditRecorder, eventPublisher), appMetrics)
+	apiKeyService := apikey.NewAPIKeyService(apikey.NewMemoryStore(), time.NewSystemTime(), tokenService, apikey.Lifetimes{
+		Default: cfg.APIKeys.DefaultLifetime,
+		Max:     cfg.APIKeys.MaxLifetime,
+	})
+
+	permissionService := rbac.NewPermissionService(tokenService, rbacEvaluator)
 
-	fiberHandler := fiber_handler.NewFiberServerHandler(authService)
+	abacEngine, err := abac.NewEngine(cfg.Policy.ABACDir)
+	if err != nil {
+		panic(err)
+	}
+	go abacEngine.Watch(ctx, cfg.Policy.ReloadInterval)
+
+	// Readiness depends on the signing keys, the user service and the session store
+	healthRegistry := health.NewRegistry(cfg.Health.CheckTimeout)
+	healthRegistry.Register("signing_keys", jwtHandler)
+	healthRegistry.Register("user_service", health.ConnChecker(grpcUserConnection))
+	healthRegistry.Register("session_store", health.CheckerFunc(sessionStore.Ping))
+
+	fiberHandler := fiber_handler.NewFiberServerHandler(authService, auditedSessionService, apiKeyService, auditService)
+	healthHandler := fiber_handler.NewHealthHandler(healthRegistry)
+	metricsHandler := adaptor.HTTPHandler(promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
 	fiberServer := fiber_server.NewAuthFiberServer(&fiber.Config{
-		ErrorHandler: common_fiber.FiberErrorHandler,
-	}, fiberHandler)
+		ErrorHandler: fiber_handler.ErrorHandler,
+		BodyLimit:    validation.MaxRequestBodySize,
+	}, fiberHandler, healthHandler, metricsHandler, []fiber.Handler{
+		fiber_middleware.RequestID(), fiber_middleware.Tracing(), fiber_middleware.AccessLog(logger), fiber_middleware.Metrics(appMetrics),
+	},
+		fiber_middleware.Tenant(tenantResolver), fiber_middleware.Authenticate(tokenService), fiber_middleware.Authorize(rbacEvaluator))
+	fiberServer.TLSConfig, err = serverTLSConfig(ctx, cfg, &cfg.HTTP.TLS)
+	if err != nil {
+		panic(err)
+	}
 
-	var publicMethods = map[string]struct{}{"/AuthService/Login": {}, "/AuthService/Register": {}}
-	authInterceptor := common_grpc.AuthUnaryInterceptor(vaultSecret, publicMethods)
-	errorInterceptor := common_grpc.GRPCErrorHandler
-	grpcServer := grpc_server.NewAuthGRPCServer(authService, []grpc.UnaryServerInterceptor{authInterceptor, errorInterceptor})
+	tracingInterceptor := grpc_server.TracingInterceptor()
+	requestIDInterceptor := grpc_server.RequestIDInterceptor
+	serviceIdentityInterceptor := grpc_server.ServiceIdentityInterceptor
+	accessLogInterceptor := grpc_server.AccessLogInterceptor(logger)
+	metricsInterceptor := grpc_server.MetricsInterceptor(appMetrics)
+	errorInterceptor := grpc_server.ErrorInterceptor
+	tenantInterceptor := grpc_server.TenantInterceptor(tenantResolver)
+	authInterceptor := grpc_server.AuthInterceptor(tokenService, rbacEvaluator.PublicMethods())
+	authorizationInterceptor := grpc_server.AuthorizationInterceptor(rbacEvaluator)
+	clientInfoInterceptor := grpc_server.ClientInfoInterceptor
+	recoveryInterceptor := grpc_server.RecoveryInterceptor
+	grpcServer := grpc_server.NewAuthGRPCServer(authService, auditedSessionService, apiKeyService, auditService, permissionService, abacEngine, health.NewGRPCServer(healthRegistry, pb.AuthService_ServiceDesc.ServiceName), []grpc.UnaryServerInterceptor{
+		tracingInterceptor, requestIDInterceptor, serviceIdentityInterceptor, accessLogInterceptor, metricsInterceptor, recoveryInterceptor, errorInterceptor, tenantInterceptor, authInterceptor, authorizationInterceptor, clientInfoInterceptor,
+	}, []grpc.StreamServerInterceptor{
+		// The same chain for streaming calls
+		grpc_server.TracingStreamInterceptor(),
+		grpc_server.RequestIDStreamInterceptor,
+		grpc_server.ServiceIdentityStreamInterceptor,
+		grpc_server.AccessLogStreamInterceptor(logger),
+		grpc_server.MetricsStreamInterceptor(appMetrics),
+		grpc_server.RecoveryStreamInterceptor,
+		grpc_server.ErrorStreamInterceptor,
+		grpc_server.TenantStreamInterceptor(tenantResolver),
+		grpc_server.AuthStreamInterceptor(tokenService, rbacEvaluator.PublicMethods()),
+		grpc_server.AuthorizationStreamInterceptor(rbacEvaluator),
+		grpc_server.ClientInfoStreamInterceptor,
+	})
+	// gRPC needs HTTP/2, negotiated over ALPN
+	grpcTLSConfig, err := serverTLSConfig(ctx, cfg, &cfg.GRPC.TLS, "h2")
+	if err != nil {
+		panic(err)
+	}
+	if grpcTLSConfig != nil {
+		grpcServer.Credentials = credentials.NewTLS(grpcTLSConfig)
+	}
+
+	// The tracer provider is closed last, so that the spans of the drained requests are exported
+	app := app.NewApp(cfg, fiberServer, grpcServer, append([]io.Closer{grpcUserConnection, auditFile}, closers...)...)
+	if err := app.Run(ctx); err != nil {
+		slog.Error("Auth service stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+// newTokenPolicies returns the token lifetimes the configuration sets, with an override for each client.
+func newTokenPolicies(cfg *config.Token) token.Policies {
+	policies := token.Policies{
+		Default: token.Policy{
+			AccessTTL:  cfg.AccessTTL,
+			RefreshTTL: cfg.RefreshTTL,
+			MaxSession: cfg.MaxSession,
+			Leeway:     cfg.Leeway,
+		},
+		ServiceTokenTTL: cfg.ServiceTokenTTL,
+		APIKeyTokenTTL:  cfg.APIKeyTokenTTL,
+		ImpersonateTTL:  cfg.ImpersonationTTL,
+		Clients:         make(map[string]token.Policy, len(cfg.Clients)),
+	}
+	for clientID, client := range cfg.Clients {
+		policies.Clients[clientID] = token.Policy{
+			AccessTTL:  client.AccessTTL,
+			RefreshTTL: client.RefreshTTL,
+			MaxSession: client.MaxSession,
+			Leeway:     client.Leeway,
+		}
+	}
+	return policies
+}
 
-	app := app.NewApp(fiberServer, grpcServer)
-	app.Run(":3002", ":3003")
+// serverTLSConfig returns the TLS configuration of a server, or nil when it serves plaintext. The
+// certificate files are reloaded when they change until ctx is done.
+func serverTLSConfig(ctx context.Context, cfg *config.Config, tlsCfg *config.ServerTLS, nextProtos ...string) (*tls.Config, error) {
+	if !tlsCfg.Enabled() {
+		return nil, nil
+	}
+	reloader, err := certs.NewReloader(tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	go reloader.Watch(ctx, cfg.TLS.ReloadInterval)
+	return reloader.ServerConfig(tlsCfg.ClientAuth(), nextProtos...), nil
+}
+
+// clientCredentials returns the transport credentials of a gRPC client connection to the address. The
+// certificate files are reloaded when they change until ctx is done.
+func clientCredentials(ctx context.Context, cfg *config.Config, address string, tlsCfg *config.ClientTLS) (credentials.TransportCredentials, error) {
+	if !tlsCfg.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+	reloader, err := certs.NewReloader(tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	go reloader.Watch(ctx, cfg.TLS.ReloadInterval)
+	return credentials.NewTLS(reloader.ClientConfig(address, tlsCfg.ServerName)), nil
+}
+
+// newLogger returns the logger the configuration selects, writing to standard error.
+func newLogger(cfg *config.Logging) (*slog.Logger, error) {
+	level, err := cfg.SlogLevel()
+	if err != nil {
+		return nil, err
+	}
+	options := &slog.HandlerOptions{Level: level, ReplaceAttr: logging.Redact}
+	var handler slog.Handler = slog.NewJSONHandler(os.Stderr, options)
+	if cfg.Format == config.LogFormatText {
+		handler = slog.NewTextHandler(os.Stderr, options)
+	}
+	return slog.New(logging.NewHandler(handler)), nil
+}
+
+// newSecretProvider returns the secrets provider the configuration selects. Vault secrets are cached and
+// the Vault token is renewed in the background.
+func newSecretProvider(ctx context.Context, cfg *config.Config) (secret.ISecretProvider, error) {
+	var provider secret.ISecretProvider
+	switch cfg.Secrets.Provider {
+	case config.SecretsEnv:
+		provider = secret.NewEnvProvider(cfg.Secrets.EnvPrefix, os.LookupEnv)
+	case config.SecretsFile:
+		provider = secret.NewFileProvider(cfg.Secrets.Dir)
+	case config.SecretsDev:
+		provider = secret.NewEphemeralProvider()
+	default:
+		vaultProvider, err := secret.NewVaultProvider(cfg.Vault.Address, vaultAuthMethod(&cfg.Vault), time.NewSystemTime())
+		if err != nil {
+			return nil, err
+		}
+		if err := vaultProvider.Login(ctx); err != nil {
+			return nil, err
+		}
+		go vaultProvider.Run(ctx)
+		provider = vaultProvider
+	}
+	// Keys are looked up on every token operation, so no provider is read on each one
+	return secret.NewCachedProvider(provider, cfg.Secrets.CacheTTL, time.NewSystemTime()), nil
+}
+
+// newTracerProvider returns the tracer provider exporting spans as the configuration selects, or nil
+// when spans are not recorded.
+func newTracerProvider(ctx context.Context, cfg *config.Tracing) (*tracing.Provider, error) {
+	switch cfg.Exporter {
+	case config.TracingStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, err
+		}
+		return tracing.NewProvider(exporter, cfg.ServiceName, cfg.SampleRatio), nil
+	case config.TracingFile:
+		file, err := os.OpenFile(cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			return nil, errors.Join(err, file.Close())
+		}
+		return tracing.NewProvider(exporter, cfg.ServiceName, cfg.SampleRatio, file), nil
+	case config.TracingOTLP:
+		options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, options...)
+		if err != nil {
+			return nil, err
+		}
+		return tracing.NewProvider(exporter, cfg.ServiceName, cfg.SampleRatio), nil
+	}
+	return nil, nil
+}
+
+// vaultAuthMethod returns the auth method the Vault configuration selects.
+func vaultAuthMethod(cfg *config.Vault) secret.IAuthMethod {
+	switch cfg.Auth.Method {
+	case config.VaultAuthTokenFile:
+		return &secret.TokenFile{Path: cfg.Auth.TokenFile}
+	case config.VaultAuthAppRole:
+		return &secret.AppRole{Mount: cfg.Auth.Mount, RoleID: cfg.Auth.RoleID, SecretIDFile: cfg.Auth.SecretIDFile}
+	case config.VaultAuthKubernetes:
+		return &secret.Kubernetes{Mount: cfg.Auth.Mount, Role: cfg.Auth.Role, TokenPath: cfg.Auth.JWTFile}
+	default:
+		return &secret.StaticToken{Token: cfg.Token}
+	}
 }
@@ -0,0 +1,122 @@
+package abac
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+)
+
+// IAuthorizer makes attribute-based authorization decisions.
+type IAuthorizer interface {
+	Authorize(ctx context.Context, claims *public_model.CustomClaims, action string, resource map[string]interface{}) Decision
+}
+
+// Engine is an IAuthorizer over the policy files in a directory. Reload picks up changed
+// files; a broken change is logged and the previous policies stay in force.
+type Engine struct {
+	Dir         string // Directory holding the policy files
+	policies    atomic.Pointer[PolicySet]
+	fingerprint atomic.Value // Names, sizes and modification times of the loaded files
+}
+
+// NewEngine loads the policies in the directory. It fails if they cannot be loaded.
+func NewEngine(dir string) (*Engine, error) {
+	e := &Engine{Dir: dir}
+	if _, err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Authorize implements IAuthorizer. The subject attributes come from the token claims.
+func (e *Engine) Authorize(ctx context.Context, claims *public_model.CustomClaims, action string, resource map[string]interface{}) Decision {
+	return e.policies.Load().Evaluate(Request{
+		Subject:  SubjectFromClaims(claims),
+		Action:   action,
+		Resource: resource,
+	})
+}
+
+// Reload loads the policies again if any file was added, removed or changed since the last
+// load. It reports whether new policies were loaded.
+func (e *Engine) Reload() (bool, error) {
+	fingerprint, err := e.fingerprintFiles()
+	if err != nil {
+		return false, err
+	}
+	if previous, ok := e.fingerprint.Load().(string); ok && previous == fingerprint {
+		return false, nil
+	}
+
+	policies, err := LoadDir(e.Dir)
+	if err != nil {
+		return false, err
+	}
+	e.policies.Store(policies)
+	e.fingerprint.Store(fingerprint)
+	return true, nil
+}
+
+// Watch reloads the policies every interval until the context is done.
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := e.Reload()
+			if err != nil {
+				slog.ErrorContext(ctx, "Keeping previous authorization policies, reload failed", "dir", e.Dir, "error", err)
+			} else if reloaded {
+				slog.InfoContext(ctx, "Reloaded authorization policies", "dir", e.Dir)
+			}
+		}
+	}
+}
+
+// fingerprintFiles summarizes the policy files so that changes can be detected cheaply.
+func (e *Engine) fingerprintFiles() (string, error) {
+	paths, err := policyFiles(e.Dir)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
+
+// SubjectFromClaims turns token claims into subject attributes: id, session, roles, scopes, tenant,
+// audience and every extra claim.
+func SubjectFromClaims(claims *public_model.CustomClaims) map[string]interface{} {
+	subject := map[string]interface{}{}
+	if claims == nil {
+		return subject
+	}
+	for name, value := range claims.Extra {
+		subject[name] = value
+	}
+	subject["id"] = claims.UserID
+	subject["session"] = claims.SessionID
+	subject["roles"] = normalize(claims.Roles)
+	subject["scopes"] = normalize(strings.Fields(claims.Scope))
+	subject["audience"] = claims.Audience
+	subject["tenant"] = claims.Tenant
+	return subject
+}
+
+// Ensure Engine implements IAuthorizer.
+var _ IAuthorizer = (*Engine)(nil)
@@ -0,0 +1,111 @@
+package abac_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/abac"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const ownerRule = `
+rules:
+  - id: owner
+    effect: allow
+    actions: [project:edit]
+    condition: resource.owner == subject.id
+`
+
+const tenantRule = `{"rules": [{"id": "tenant", "effect": "allow", "actions": ["project:view"], "condition": "resource.tenant == subject.tenant"}]}`
+
+// writeFile writes a policy file with a modification time that differs from any earlier write
+func writeFile(t *testing.T, path string, content string, age time.Duration) {
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	modTime := time.Now().Add(-age)
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestEngine_Authorize(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "owner.yaml"), ownerRule, 0)
+	engine, err := abac.NewEngine(dir)
+	assert.NoError(t, err)
+
+	claims := &public_model.CustomClaims{UserID: "user", Tenant: "acme"}
+
+	decision := engine.Authorize(context.TODO(), claims, "project:edit", map[string]interface{}{"owner": "user"})
+	assert.True(t, decision.Allowed)
+
+	decision = engine.Authorize(context.TODO(), claims, "project:view", map[string]interface{}{"tenant": "acme"})
+	assert.False(t, decision.Allowed)
+}
+
+func TestEngine_Reload(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "owner.yaml"), ownerRule, time.Hour)
+	engine, err := abac.NewEngine(dir)
+	assert.NoError(t, err)
+
+	reloaded, err := engine.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// A new file is picked up
+	writeFile(t, filepath.Join(dir, "tenant.json"), tenantRule, 0)
+	reloaded, err = engine.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+
+	claims := &public_model.CustomClaims{UserID: "user", Tenant: "acme"}
+	decision := engine.Authorize(context.TODO(), claims, "project:view", map[string]interface{}{"tenant": "acme"})
+	assert.True(t, decision.Allowed)
+
+	// A broken change keeps the previous policies in force
+	writeFile(t, filepath.Join(dir, "tenant.json"), `{"rules": [`, time.Minute)
+	_, err = engine.Reload()
+	assert.Error(t, err)
+
+	decision = engine.Authorize(context.TODO(), claims, "project:view", map[string]interface{}{"tenant": "acme"})
+	assert.True(t, decision.Allowed)
+}
+
+func TestEngine_Watch(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "owner.yaml"), ownerRule, time.Hour)
+	engine, err := abac.NewEngine(dir)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Watch(ctx, 5*time.Millisecond)
+
+	writeFile(t, filepath.Join(dir, "tenant.json"), tenantRule, 0)
+
+	claims := &public_model.CustomClaims{UserID: "user", Tenant: "acme"}
+	assert.Eventually(t, func() bool {
+		return engine.Authorize(context.TODO(), claims, "project:view", map[string]interface{}{"tenant": "acme"}).Allowed
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestNewEngine_MissingDir(t *testing.T) {
+	_, err := abac.NewEngine(filepath.Join(t.TempDir(), "missing"))
+
+	assert.Error(t, err)
+}
+
+func TestSubjectFromClaims(t *testing.T) {
+	claims := &public_model.CustomClaims{UserID: "user", SessionID: "session", Scope: "profile sessions", Roles: []string{"user"}, Tenant: "acme", Extra: map[string]interface{}{"plan": "pro", "id": "spoofed"}}
+
+	subject := abac.SubjectFromClaims(claims)
+
+	assert.Equal(t, "user", subject["id"])
+	assert.Equal(t, "session", subject["session"])
+	assert.Equal(t, []interface{}{"user"}, subject["roles"])
+	assert.Equal(t, []interface{}{"profile", "sessions"}, subject["scopes"])
+	assert.Equal(t, "acme", subject["tenant"])
+	assert.Equal(t, "pro", subject["plan"])
+}
@@ -0,0 +1,655 @@
+package abac
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression is a compiled condition written in a small CEL-style language:
+//
+//	resource.owner == subject.id && action in ["project:edit", "project:view"]
+//
+// It supports string, number, boolean, null and list literals; field access with '.' and '[]';
+// the operators ! - == != < <= > >= in && ||; the functions size(x) and has(x); and the
+// methods startsWith, endsWith and contains. Missing fields evaluate to null.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Compile parses the source of an expression.
+func Compile(source string) (*Expression, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.peek().text, p.peek().pos)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression against the given variables.
+func (e *Expression) Eval(vars map[string]interface{}) (interface{}, error) {
+	return e.root.eval(vars)
+}
+
+// EvalBool evaluates the expression and requires a boolean result.
+func (e *Expression) EvalBool(vars map[string]interface{}) (bool, error) {
+	value, err := e.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q is %s, not a boolean", e.source, typeName(value))
+	}
+	return b, nil
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators lists the operator and punctuation tokens, longest first.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "-", "(", ")", "[", "]", ".", ","}
+
+// escapes maps the characters allowed after a backslash in string literals to their values.
+var escapes = map[byte]byte{'\\': '\\', '"': '"', '\'': '\'', 'n': '\n', 't': '\t'}
+
+func lex(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			var text strings.Builder
+			end := i + 1
+			for ; end < len(source) && rune(source[end]) != c; end++ {
+				if source[end] == '\\' && end+1 < len(source) {
+					end++
+					if escaped, ok := escapes[source[end]]; ok {
+						text.WriteByte(escaped)
+						continue
+					}
+					return nil, fmt.Errorf("invalid escape at offset %d", end-1)
+				}
+				text.WriteByte(source[end])
+			}
+			if end >= len(source) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text.String(), pos: i})
+			i = end + 1
+		case unicode.IsDigit(c):
+			end := i
+			for end < len(source) && (unicode.IsDigit(rune(source[end])) || source[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[i:end], pos: i})
+			i = end
+		case c == '_' || unicode.IsLetter(c):
+			end := i
+			for end < len(source) && (source[end] == '_' || unicode.IsLetter(rune(source[end])) || unicode.IsDigit(rune(source[end]))) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[i:end], pos: i})
+			i = end
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(source[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(source)}), nil
+}
+
+// Parser
+
+// binaryPrecedence gives the binding power of each binary operator.
+var binaryPrecedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3, "in": 3,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(text string) error {
+	t := p.next()
+	if t.kind != tokenOperator || t.text != text {
+		return fmt.Errorf("expected %q at offset %d", text, t.pos)
+	}
+	return nil
+}
+
+// parseExpr parses binary operators binding tighter than minPrecedence by precedence climbing.
+func (p *parser) parseExpr(minPrecedence int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokenOperator && !(t.kind == tokenIdent && t.text == "in") {
+			return left, nil
+		}
+		precedence, ok := binaryPrecedence[t.text]
+		if !ok || precedence <= minPrecedence {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseExpr(precedence)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t := p.peek()
+	if t.kind == tokenOperator && (t.text == "!" || t.text == "-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: t.text, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenOperator && t.text == ".":
+			p.next()
+			name := p.next()
+			if name.kind != tokenIdent {
+				return nil, fmt.Errorf("expected field name at offset %d", name.pos)
+			}
+			if p.peek().kind == tokenOperator && p.peek().text == "(" {
+				args, err := p.parseArgs()
+				if err != nil {
+					return nil, err
+				}
+				n = &callNode{name: name.text, target: n, args: args}
+			} else {
+				n = &selectNode{target: n, field: &literalNode{value: name.text}}
+			}
+		case t.kind == tokenOperator && t.text == "[":
+			p.next()
+			index, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &selectNode{target: n, field: index}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return &literalNode{value: t.text}, nil
+	case tokenNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at offset %d", t.text, t.pos)
+		}
+		return &literalNode{value: f}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		if p.peek().kind == tokenOperator && p.peek().text == "(" {
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			return &callNode{name: t.text, args: args}, nil
+		}
+		return &identNode{name: t.text}, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			n, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			var items []node
+			for !(p.peek().kind == tokenOperator && p.peek().text == "]") {
+				item, err := p.parseExpr(0)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+				if p.peek().kind == tokenOperator && p.peek().text == "," {
+					p.next()
+				} else {
+					break
+				}
+			}
+			return &listNode{items: items}, p.expect("]")
+		}
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", t.text, t.pos)
+}
+
+func (p *parser) parseArgs() ([]node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []node
+	for !(p.peek().kind == tokenOperator && p.peek().text == ")") {
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek().kind == tokenOperator && p.peek().text == "," {
+			p.next()
+		} else {
+			break
+		}
+	}
+	return args, p.expect(")")
+}
+
+// Evaluation
+
+type node interface {
+	eval(vars map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+type identNode struct {
+	name string
+}
+
+func (n *identNode) eval(vars map[string]interface{}) (interface{}, error) {
+	value, ok := vars[n.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %q", n.name)
+	}
+	return normalize(value), nil
+}
+
+type listNode struct {
+	items []node
+}
+
+func (n *listNode) eval(vars map[string]interface{}) (interface{}, error) {
+	list := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+type selectNode struct {
+	target node
+	field  node
+}
+
+func (n *selectNode) eval(vars map[string]interface{}) (interface{}, error) {
+	target, err := n.target.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	field, err := n.field.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		key, ok := field.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key must be a string, not %s", typeName(field))
+		}
+		return normalize(t[key]), nil
+	case []interface{}:
+		index, ok := field.(float64)
+		if !ok || index != float64(int(index)) {
+			return nil, fmt.Errorf("list index must be an integer")
+		}
+		if int(index) < 0 || int(index) >= len(t) {
+			return nil, nil
+		}
+		return t[int(index)], nil
+	}
+	return nil, fmt.Errorf("cannot select a field of %s", typeName(target))
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(vars map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "!":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("! needs a boolean, not %s", typeName(value))
+		}
+		return !b, nil
+	default:
+		f, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("- needs a number, not %s", typeName(value))
+		}
+		return -f, nil
+	}
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(vars map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	// && and || short-circuit
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s needs booleans, not %s", n.op, typeName(left))
+		}
+		if (n.op == "&&" && !l) || (n.op == "||" && l) {
+			return l, nil
+		}
+		right, err := n.right.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s needs booleans, not %s", n.op, typeName(right))
+		}
+		return r, nil
+	}
+
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		list, ok := right.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("in needs a list, not %s", typeName(right))
+		}
+		for _, item := range list {
+			if equal(left, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return compare(n.op, left, right)
+}
+
+type callNode struct {
+	name   string
+	target node // Receiver of a method call, nil for functions
+	args   []node
+}
+
+func (n *callNode) eval(vars map[string]interface{}) (interface{}, error) {
+	var values []interface{}
+	if n.target != nil {
+		target, err := n.target.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, target)
+	}
+	for _, arg := range n.args {
+		value, err := arg.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	switch n.name {
+	case "has":
+		if n.target != nil || len(values) != 1 {
+			return nil, fmt.Errorf("has takes one argument")
+		}
+		return values[0] != nil, nil
+	case "size":
+		if len(values) != 1 {
+			return nil, fmt.Errorf("size takes one argument")
+		}
+		switch v := values[0].(type) {
+		case string:
+			return float64(len(v)), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("size needs a string, list or map, not %s", typeName(values[0]))
+	case "startsWith", "endsWith", "contains":
+		if n.target == nil || len(values) != 2 {
+			return nil, fmt.Errorf("%s is a method taking one argument", n.name)
+		}
+		if list, ok := values[0].([]interface{}); ok && n.name == "contains" {
+			for _, item := range list {
+				if equal(item, values[1]) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		s, ok1 := values[0].(string)
+		arg, ok2 := values[1].(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("%s needs strings", n.name)
+		}
+		switch n.name {
+		case "startsWith":
+			return strings.HasPrefix(s, arg), nil
+		case "endsWith":
+			return strings.HasSuffix(s, arg), nil
+		default:
+			return strings.Contains(s, arg), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown function %q", n.name)
+}
+
+// normalize converts Go values from variables into the types expressions work with:
+// float64 numbers, []interface{} lists and map[string]interface{} maps.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, string, float64, []interface{}, map[string]interface{}:
+		return v
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return list
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	case reflect.Slice:
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = normalize(rv.Index(i).Interface())
+		}
+		return list
+	}
+	return value
+}
+
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func compare(op string, left, right interface{}) (interface{}, error) {
+	var c int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare number with %s", typeName(right))
+		}
+		c = compareOrdered(l, r)
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare string with %s", typeName(right))
+		}
+		c = strings.Compare(l, r)
+	default:
+		return nil, fmt.Errorf("cannot order %s", typeName(left))
+	}
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,98 @@
+package abac_test
+
+import (
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/abac"
+	"github.com/stretchr/testify/assert"
+)
+
+var vars = map[string]interface{}{
+	"subject": map[string]interface{}{
+		"id":    "user-1",
+		"roles": []string{"user", "editor"},
+		"age":   42,
+	},
+	"action": "project:edit",
+	"resource": map[string]interface{}{
+		"owner":   "user-1",
+		"path":    "/projects/alpha",
+		"members": []interface{}{"user-1", "user-2"},
+		"size":    3.5,
+	},
+}
+
+func eval(t *testing.T, source string) interface{} {
+	expression, err := abac.Compile(source)
+	assert.NoError(t, err, source)
+	value, err := expression.Eval(vars)
+	assert.NoError(t, err, source)
+	return value
+}
+
+func TestExpression_Evaluates(t *testing.T) {
+	for source, want := range map[string]interface{}{
+		`resource.owner == subject.id`:                                true,
+		`resource.owner != subject.id`:                                false,
+		`"editor" in subject.roles`:                                   true,
+		`action in ["project:view", "project:edit"]`:                  true,
+		`subject.age >= 18 && subject.age < 65`:                       true,
+		`resource.size > 4 || resource.path.startsWith("/projects/")`: true,
+		`!(subject.age == 42)`:                                        false,
+		`-subject.age`:                                                float64(-42),
+		`resource["owner"]`:                                           "user-1",
+		`resource.members[1]`:                                         "user-2",
+		`size(resource.members)`:                                      float64(2),
+		`has(resource.owner) && !has(resource.missing)`:               true,
+		`resource.missing == null`:                                    true,
+		`resource.missing.deeper == null`:                             true,
+		`resource.path.endsWith('alpha')`:                             true,
+		`resource.members.contains("user-2")`:                         true,
+		`'it\'s' == "it's"`:                                           true,
+		`1 + 1`:                                                       nil,
+	} {
+		if want == nil {
+			_, err := abac.Compile(source)
+			assert.Error(t, err, source)
+			continue
+		}
+		assert.Equal(t, want, eval(t, source), source)
+	}
+}
+
+func TestExpression_ShortCircuit(t *testing.T) {
+	// The right side would fail, because a string cannot be ordered against a number
+	assert.Equal(t, false, eval(t, `false && resource.owner > 1`))
+	assert.Equal(t, true, eval(t, `true || resource.owner > 1`))
+}
+
+func TestExpression_EvalErrors(t *testing.T) {
+	for _, source := range []string{
+		`unknown == 1`,
+		`resource.owner > 1`,
+		`subject.id && true`,
+		`action in "project:edit"`,
+		`nope(1)`,
+		`size(1)`,
+	} {
+		expression, err := abac.Compile(source)
+		assert.NoError(t, err, source)
+		_, err = expression.Eval(vars)
+		assert.Error(t, err, source)
+	}
+}
+
+func TestExpression_CompileErrors(t *testing.T) {
+	for _, source := range []string{``, `(a == b`, `a ==`, `"open`, `a # b`, `a.`, `[1, 2`} {
+		_, err := abac.Compile(source)
+		assert.Error(t, err, source)
+	}
+}
+
+func TestExpression_EvalBool(t *testing.T) {
+	expression, _ := abac.Compile(`subject.id`)
+
+	_, err := expression.EvalBool(vars)
+
+	assert.EqualError(t, err, `expression "subject.id" is string, not a boolean`)
+}
@@ -0,0 +1,209 @@
+package abac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Effect is the outcome a rule produces when it applies.
+type Effect string
+
+const (
+	Allow Effect = "allow"
+	Deny  Effect = "deny"
+)
+
+// Rule grants or denies actions when its condition holds.
+type Rule struct {
+	ID          string   `json:"id" yaml:"id"`
+	Description string   `json:"description" yaml:"description"`
+	Effect      Effect   `json:"effect" yaml:"effect"`
+	Actions     []string `json:"actions" yaml:"actions"`     // Action names, "*" or "prefix:*"
+	Condition   string   `json:"condition" yaml:"condition"` // Expression over subject, action and resource; empty always holds
+}
+
+// File is the content of a policy file.
+type File struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Request is the input of an authorization decision.
+type Request struct {
+	Subject  map[string]interface{} // Attributes of the caller, taken from the token
+	Action   string                 // Action the caller wants to perform
+	Resource map[string]interface{} // Attributes of the resource acted on
+}
+
+// Decision is the outcome of an authorization request.
+type Decision struct {
+	Allowed bool
+	Reasons []string // Rules that decided the outcome, or why none did
+}
+
+type compiledRule struct {
+	Rule
+	condition *Expression
+}
+
+// PolicySet is a compiled set of rules. Deny rules override allow rules, and actions no rule
+// allows are denied.
+type PolicySet struct {
+	rules []compiledRule
+}
+
+// ParseFile decodes a policy file. The format is ".json" for JSON and YAML otherwise.
+func ParseFile(data []byte, format string) (*File, error) {
+	file := &File{}
+	var err error
+	if strings.EqualFold(format, ".json") {
+		err = json.Unmarshal(data, file)
+	} else {
+		err = yaml.Unmarshal(data, file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// NewPolicySet validates the rules and compiles their conditions.
+func NewPolicySet(rules []Rule) (*PolicySet, error) {
+	set := &PolicySet{}
+	seen := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		if rule.ID == "" {
+			return nil, fmt.Errorf("abac policy: rule without id")
+		}
+		if _, ok := seen[rule.ID]; ok {
+			return nil, fmt.Errorf("abac policy: duplicate rule %q", rule.ID)
+		}
+		seen[rule.ID] = struct{}{}
+		if rule.Effect != Allow && rule.Effect != Deny {
+			return nil, fmt.Errorf("abac policy: rule %q: effect must be allow or deny", rule.ID)
+		}
+		if len(rule.Actions) == 0 {
+			return nil, fmt.Errorf("abac policy: rule %q: no actions", rule.ID)
+		}
+
+		compiled := compiledRule{Rule: rule}
+		if strings.TrimSpace(rule.Condition) != "" {
+			condition, err := Compile(rule.Condition)
+			if err != nil {
+				return nil, fmt.Errorf("abac policy: rule %q: %w", rule.ID, err)
+			}
+			compiled.condition = condition
+		}
+		set.rules = append(set.rules, compiled)
+	}
+	return set, nil
+}
+
+// LoadDir reads every .yaml, .yml and .json file in the directory, in name order, into one policy set.
+func LoadDir(dir string) (*PolicySet, error) {
+	paths, err := policyFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []Rule
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		file, err := ParseFile(data, filepath.Ext(path))
+		if err != nil {
+			return nil, fmt.Errorf("abac policy %s: %w", filepath.Base(path), err)
+		}
+		rules = append(rules, file.Rules...)
+	}
+	return NewPolicySet(rules)
+}
+
+// policyFiles lists the policy files in the directory, sorted by name.
+func policyFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			if !entry.IsDir() {
+				paths = append(paths, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Evaluate decides the request. A deny rule whose condition fails to evaluate still denies,
+// while an allow rule whose condition fails never allows.
+func (s *PolicySet) Evaluate(req Request) Decision {
+	vars := map[string]interface{}{
+		"subject":  req.Subject,
+		"action":   req.Action,
+		"resource": req.Resource,
+	}
+	if req.Subject == nil {
+		vars["subject"] = map[string]interface{}{}
+	}
+	if req.Resource == nil {
+		vars["resource"] = map[string]interface{}{}
+	}
+
+	var allows, denies []string
+	for _, rule := range s.rules {
+		if !matchAction(rule.Actions, req.Action) {
+			continue
+		}
+		holds, err := true, error(nil)
+		if rule.condition != nil {
+			holds, err = rule.condition.EvalBool(vars)
+		}
+		switch {
+		case rule.Effect == Deny && err != nil:
+			denies = append(denies, fmt.Sprintf("denied by %s: condition failed: %v", rule.ID, err))
+		case rule.Effect == Deny && holds:
+			denies = append(denies, describe("denied by", rule.Rule))
+		case rule.Effect == Allow && err == nil && holds:
+			allows = append(allows, describe("allowed by", rule.Rule))
+		}
+	}
+
+	switch {
+	case len(denies) > 0:
+		return Decision{Allowed: false, Reasons: denies}
+	case len(allows) > 0:
+		return Decision{Allowed: true, Reasons: allows}
+	}
+	return Decision{Allowed: false, Reasons: []string{fmt.Sprintf("no rule allows %q", req.Action)}}
+}
+
+func describe(prefix string, rule Rule) string {
+	if rule.Description == "" {
+		return prefix + " " + rule.ID
+	}
+	return prefix + " " + rule.ID + ": " + rule.Description
+}
+
+// matchAction reports whether any of the patterns covers the action.
+func matchAction(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == action {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasSuffix(prefix, ":") && strings.HasPrefix(action, prefix) {
+			return true
+		}
+	}
+	return false
+}
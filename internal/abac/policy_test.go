@@ -0,0 +1,87 @@
+package abac_test
+
+import (
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/abac"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPolicySet(t *testing.T) *abac.PolicySet {
+	set, err := abac.NewPolicySet([]abac.Rule{
+		{ID: "owner", Description: "Owners may do anything", Effect: abac.Allow, Actions: []string{"project:*"}, Condition: "resource.owner == subject.id"},
+		{ID: "archived", Effect: abac.Deny, Actions: []string{"project:edit"}, Condition: "resource.archived == true"},
+		{ID: "broken-deny", Effect: abac.Deny, Actions: []string{"project:delete"}, Condition: "resource.size > 1"},
+	})
+	assert.NoError(t, err)
+	return set
+}
+
+func TestEvaluate_Allow(t *testing.T) {
+	decision := newPolicySet(t).Evaluate(abac.Request{
+		Subject:  map[string]interface{}{"id": "user"},
+		Action:   "project:edit",
+		Resource: map[string]interface{}{"owner": "user"},
+	})
+
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, []string{"allowed by owner: Owners may do anything"}, decision.Reasons)
+}
+
+func TestEvaluate_DenyOverrides(t *testing.T) {
+	decision := newPolicySet(t).Evaluate(abac.Request{
+		Subject:  map[string]interface{}{"id": "user"},
+		Action:   "project:edit",
+		Resource: map[string]interface{}{"owner": "user", "archived": true},
+	})
+
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, []string{"denied by archived"}, decision.Reasons)
+}
+
+func TestEvaluate_FailingDenyDenies(t *testing.T) {
+	decision := newPolicySet(t).Evaluate(abac.Request{
+		Subject:  map[string]interface{}{"id": "user"},
+		Action:   "project:delete",
+		Resource: map[string]interface{}{"owner": "user", "size": "big"},
+	})
+
+	assert.False(t, decision.Allowed)
+	assert.Contains(t, decision.Reasons[0], "denied by broken-deny: condition failed")
+}
+
+func TestEvaluate_DefaultDeny(t *testing.T) {
+	decision := newPolicySet(t).Evaluate(abac.Request{
+		Subject:  map[string]interface{}{"id": "other"},
+		Action:   "project:view",
+		Resource: map[string]interface{}{"owner": "user"},
+	})
+
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, []string{`no rule allows "project:view"`}, decision.Reasons)
+}
+
+func TestNewPolicySet_Invalid(t *testing.T) {
+	for _, rules := range [][]abac.Rule{
+		{{Effect: abac.Allow, Actions: []string{"*"}}},
+		{{ID: "a", Effect: "maybe", Actions: []string{"*"}}},
+		{{ID: "a", Effect: abac.Allow}},
+		{{ID: "a", Effect: abac.Allow, Actions: []string{"*"}, Condition: "a =="}},
+		{{ID: "a", Effect: abac.Allow, Actions: []string{"*"}}, {ID: "a", Effect: abac.Deny, Actions: []string{"*"}}},
+	} {
+		_, err := abac.NewPolicySet(rules)
+		assert.Error(t, err)
+	}
+}
+
+func TestLoadDir_Default(t *testing.T) {
+	set, err := abac.LoadDir("../../config/abac")
+	assert.NoError(t, err)
+
+	decision := set.Evaluate(abac.Request{
+		Subject:  map[string]interface{}{"id": "user-2", "roles": []interface{}{"user"}},
+		Action:   "project:view",
+		Resource: map[string]interface{}{"owner": "user-1", "members": []interface{}{"user-2"}},
+	})
+	assert.True(t, decision.Allowed)
+}
@@ -0,0 +1,57 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/scope"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+)
+
+// APIKey is a long-lived credential a user issues for programmatic access. Only a hash of
+// the key is kept; the key itself is shown once, when it is created.
+type APIKey struct {
+	ID         string
+	UserID     string
+	Tenant     string
+	Name       string
+	Hash       string // Hex-encoded SHA-256 of the full key
+	Scopes     []string
+	Roles      []string // Roles of the owner when the key was issued
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	LastUsedAt time.Time // Zero until the key is first exchanged
+}
+
+// Prefix returns the leading part of the key, which identifies it without revealing the secret.
+func (k *APIKey) Prefix() string {
+	return KeyPrefix + k.ID
+}
+
+// Grant returns what the key may do, for the access tokens it is exchanged for.
+func (k *APIKey) Grant() *token.APIKeyGrant {
+	return &token.APIKeyGrant{
+		KeyID:  k.ID,
+		UserID: k.UserID,
+		Tenant: k.Tenant,
+		Scopes: k.Scopes,
+		Roles:  k.Roles,
+	}
+}
+
+// ToAPIKeyModel converts the key into its public representation.
+func (k *APIKey) ToAPIKeyModel() *public_model.APIKeyModel {
+	apiKeyModel := &public_model.APIKeyModel{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix(),
+		Scope:     scope.Format(k.Scopes),
+		CreatedAt: k.CreatedAt,
+		ExpiresAt: k.ExpiresAt,
+	}
+	if !k.LastUsedAt.IsZero() {
+		lastUsedAt := k.LastUsedAt
+		apiKeyModel.LastUsedAt = &lastUsedAt
+	}
+	return apiKeyModel
+}
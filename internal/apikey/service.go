@@ -0,0 +1,216 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/scope"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	internal_time "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/time"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+)
+
+// KeyPrefix starts every API key, so that leaked keys are easy to recognize.
+const KeyPrefix = "bbk_"
+
+// day is the unit of requested key lifetimes.
+const day = 24 * time.Hour
+
+// ErrInvalidKey and ErrKeyExpired are the causes of the service errors a failed exchange returns, telling
+// a key that is unknown, revoked or of another tenant from one that has expired.
+var (
+	ErrInvalidKey = errors.New("invalid API key")
+	ErrKeyExpired = errors.New("API key has expired")
+)
+
+// IAPIKeyService defines methods for managing API keys and exchanging them for access tokens.
+type IAPIKeyService interface {
+	Create(ctx context.Context, owner *public_model.CustomClaims, createModel *public_model.CreateAPIKeyModel) (*public_model.CreatedAPIKeyModel, error)
+	List(ctx context.Context, owner *public_model.CustomClaims) ([]*APIKey, error)
+	Revoke(ctx context.Context, owner *public_model.CustomClaims, keyID string) error
+	Exchange(ctx context.Context, key string) (*public_model.AccessTokenModel, error)
+}
+
+// Lifetimes bound how long an API key stays usable.
+type Lifetimes struct {
+	Default time.Duration // Lifetime of keys created without an expiry
+	Max     time.Duration // Longest lifetime a key may be created with
+}
+
+// APIKeyService contains fields necessary for API key operations.
+type APIKeyService struct {
+	Store        IStore                   // Persists API keys
+	Time         internal_time.TimeSource // Source to get the current time
+	TokenService token.ITokenService      // Issues the access tokens keys are exchanged for
+	Lifetimes    Lifetimes                // Default and maximum key lifetimes
+}
+
+// NewAPIKeyService initializes a new APIKeyService with necessary dependencies.
+func NewAPIKeyService(store IStore, time internal_time.TimeSource, tokenService token.ITokenService, lifetimes Lifetimes) *APIKeyService {
+	return &APIKeyService{
+		Store:        store,
+		Time:         time,
+		TokenService: tokenService,
+		Lifetimes:    lifetimes,
+	}
+}
+
+// Create issues a new API key for the owner of the access token. The key is limited to the requested
+// scopes, which must all be held by the owner, and keeps the owner's roles and tenant. Tokens obtained
+// with an API key cannot issue further keys, nor can impersonation tokens, whose short lifetime a key
+// would outlive.
+func (s *APIKeyService) Create(ctx context.Context, owner *public_model.CustomClaims, createModel *public_model.CreateAPIKeyModel) (*public_model.CreatedAPIKeyModel, error) {
+	if owner.APIKeyID != "" {
+		return nil, common_error.NewServiceError(common_error.Forbidden, "API keys cannot issue API keys", nil)
+	}
+	if owner.Actor != nil {
+		return nil, common_error.NewServiceError(common_error.Forbidden, "Impersonation tokens cannot issue API keys", nil)
+	}
+
+	scopes, missing := scope.Narrow(scope.Parse(owner.Scope), scope.Parse(createModel.Scope))
+	if missing != "" {
+		return nil, common_error.NewServiceError(common_error.BadRequest, fmt.Sprintf("Scope %q was not granted", missing), nil)
+	}
+
+	lifetime := s.Lifetimes.Default
+	if createModel.ExpiresInDays > 0 {
+		// Compared in days first, as large day counts overflow a Duration
+		if int64(createModel.ExpiresInDays) > int64(math.MaxInt64/day) {
+			return nil, lifetimeExceeded()
+		}
+		lifetime = time.Duration(createModel.ExpiresInDays) * day
+	}
+	if s.Lifetimes.Max > 0 && lifetime > s.Lifetimes.Max {
+		return nil, lifetimeExceeded()
+	}
+
+	id, secret, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	key := KeyPrefix + id + "_" + secret
+
+	now := s.Time.Now()
+	apiKey := &APIKey{
+		ID:        id,
+		UserID:    owner.UserID,
+		Tenant:    owner.Tenant,
+		Name:      createModel.Name,
+		Hash:      hashKey(key),
+		Scopes:    scopes,
+		Roles:     owner.Roles,
+		CreatedAt: now,
+		ExpiresAt: now.Add(lifetime),
+	}
+	if err := s.Store.Save(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &public_model.CreatedAPIKeyModel{APIKeyModel: *apiKey.ToAPIKeyModel(), Key: key}, nil
+}
+
+// List returns the API keys of the owner of the access token, expired ones included, so that they can
+// be cleaned up.
+func (s *APIKeyService) List(ctx context.Context, owner *public_model.CustomClaims) ([]*APIKey, error) {
+	return s.Store.ListByUser(ctx, owner.Tenant, owner.UserID)
+}
+
+// Revoke deletes one of the API keys of the owner of the access token. Keys of other users, including
+// users with the same ID in another tenant, are reported as not found.
+func (s *APIKeyService) Revoke(ctx context.Context, owner *public_model.CustomClaims, keyID string) error {
+	apiKey, err := s.Store.Get(ctx, keyID)
+	if errors.Is(err, ErrNotFound) || (err == nil && (apiKey.UserID != owner.UserID || apiKey.Tenant != owner.Tenant)) {
+		return common_error.NewServiceError(common_error.NotFound, "API key not found", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.Store.Delete(ctx, keyID)
+}
+
+// Exchange trades an API key for a short-lived access token. Unknown and revoked keys, and keys of
+// another tenant than the request's, are rejected alike; expired keys are reported as such.
+func (s *APIKeyService) Exchange(ctx context.Context, key string) (*public_model.AccessTokenModel, error) {
+	id, ok := parseKey(key)
+	if !ok {
+		return nil, invalidKey()
+	}
+
+	apiKey, err := s.Store.Get(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, invalidKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey.Hash), []byte(hashKey(key))) != 1 || !tenant.Matches(ctx, apiKey.Tenant) {
+		return nil, invalidKey()
+	}
+
+	now := s.Time.Now()
+	if !now.Before(apiKey.ExpiresAt) {
+		return nil, common_error.NewServiceError(common_error.TokenExpired, "API key has expired", ErrKeyExpired)
+	}
+
+	// Revoked since it was read
+	err = s.Store.MarkUsed(ctx, apiKey.ID, now)
+	if errors.Is(err, ErrNotFound) {
+		return nil, invalidKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.TokenService.CreateAPIKeyToken(ctx, apiKey.Grant())
+}
+
+// invalidKey rejects keys that cannot be exchanged, without telling why.
+func invalidKey() error {
+	return common_error.NewServiceError(common_error.Unauthorized, "Invalid API key", ErrInvalidKey)
+}
+
+// lifetimeExceeded rejects keys requested with a longer lifetime than allowed.
+func lifetimeExceeded() error {
+	return common_error.NewServiceError(common_error.BadRequest, "Expiry exceeds the maximum API key lifetime", nil)
+}
+
+// newKey generates a random 64-bit key ID and a random 256-bit secret.
+func newKey() (id string, secret string, err error) {
+	b := make([]byte, 8+32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(b[:8]), base64.RawURLEncoding.EncodeToString(b[8:]), nil
+}
+
+// parseKey returns the ID of a key of the form "bbk_<id>_<secret>".
+func parseKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, KeyPrefix)
+	if !ok {
+		return "", false
+	}
+	id, secret, ok := strings.Cut(rest, "_")
+	return id, ok && id != "" && secret != ""
+}
+
+// hashKey returns the hex-encoded SHA-256 of the key. Keys carry 256 bits of randomness, so a
+// fast hash is enough to make stored hashes useless to an attacker.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Ensure APIKeyService implements IAPIKeyService.
+var _ IAPIKeyService = (*APIKeyService)(nil)
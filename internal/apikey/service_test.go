@@ -0,0 +1,233 @@
+package apikey_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/apikey"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTimeSource struct {
+	now time.Time
+}
+
+func (m *MockTimeSource) Now() time.Time {
+	return m.now
+}
+
+type MockTokenService struct {
+	mock.Mock
+	token.ITokenService
+}
+
+func (m *MockTokenService) CreateAPIKeyToken(ctx context.Context, grant *token.APIKeyGrant) (*public_model.AccessTokenModel, error) {
+	args := m.Called(ctx, grant)
+	return args.Get(0).(*public_model.AccessTokenModel), args.Error(1)
+}
+
+var owner = &public_model.CustomClaims{UserID: "user", Scope: "profile sessions", Roles: []string{"user"}}
+
+func newService() (*apikey.APIKeyService, *MockTimeSource, *MockTokenService) {
+	timeSource := &MockTimeSource{now: time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)}
+	tokenService := new(MockTokenService)
+	lifetimes := apikey.Lifetimes{Default: 90 * 24 * time.Hour, Max: 365 * 24 * time.Hour}
+	return apikey.NewAPIKeyService(apikey.NewMemoryStore(), timeSource, tokenService, lifetimes), timeSource, tokenService
+}
+
+func serviceErrorCode(t *testing.T, err error) int {
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	if !ok {
+		return 0
+	}
+	return serviceErr.Code
+}
+
+func serviceErrorCause(err error) error {
+	if serviceErr, ok := err.(*common_error.ServiceError); ok {
+		return serviceErr.Cause
+	}
+	return nil
+}
+
+func TestCreate_StoresOnlyHash(t *testing.T) {
+	svc, timeSource, _ := newService()
+
+	created, err := svc.Create(context.TODO(), owner, &public_model.CreateAPIKeyModel{Name: "ci", Scope: "profile", ExpiresInDays: 30})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix+"_"))
+	assert.True(t, strings.HasPrefix(created.Prefix, apikey.KeyPrefix))
+	assert.Equal(t, "profile", created.Scope)
+	assert.Equal(t, timeSource.now.Add(30*24*time.Hour), created.ExpiresAt)
+	assert.Nil(t, created.LastUsedAt)
+
+	stored, err := svc.Store.Get(context.TODO(), created.ID)
+	assert.NoError(t, err)
+	assert.Len(t, stored.Hash, 64)
+	assert.NotContains(t, stored.Hash, created.Key)
+	assert.Equal(t, []string{"user"}, stored.Roles)
+}
+
+func TestCreate_DefaultsToCallerScopes(t *testing.T) {
+	svc, timeSource, _ := newService()
+
+	created, err := svc.Create(context.TODO(), owner, &public_model.CreateAPIKeyModel{Name: "ci"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "profile sessions", created.Scope)
+	assert.Equal(t, timeSource.now.Add(90*24*time.Hour), created.ExpiresAt)
+}
+
+func TestCreate_Rejected(t *testing.T) {
+	svc, _, _ := newService()
+	keyOwner := &public_model.CustomClaims{UserID: "user", Scope: "profile", APIKeyID: "key"}
+	impersonated := &public_model.CustomClaims{UserID: "user", Scope: "profile", Roles: []string{"user"}, Actor: &public_model.Actor{UserID: "admin"}}
+
+	for _, tc := range []struct {
+		owner       *public_model.CustomClaims
+		createModel *public_model.CreateAPIKeyModel
+		code        int
+	}{
+		{owner, &public_model.CreateAPIKeyModel{Name: "ci", Scope: "admin"}, common_error.BadRequest},
+		{owner, &public_model.CreateAPIKeyModel{Name: "ci", ExpiresInDays: 366}, common_error.BadRequest},
+		// Wraps around to 25 minutes if multiplied out as a Duration
+		{owner, &public_model.CreateAPIKeyModel{Name: "ci", ExpiresInDays: 213504}, common_error.BadRequest},
+		{keyOwner, &public_model.CreateAPIKeyModel{Name: "ci"}, common_error.Forbidden},
+		{impersonated, &public_model.CreateAPIKeyModel{Name: "ci"}, common_error.Forbidden},
+	} {
+		created, err := svc.Create(context.TODO(), tc.owner, tc.createModel)
+
+		assert.Equal(t, tc.code, serviceErrorCode(t, err))
+		assert.Nil(t, created)
+	}
+}
+
+func TestExchange_Success(t *testing.T) {
+	svc, timeSource, tokenService := newService()
+	created, _ := svc.Create(context.TODO(), owner, &public_model.CreateAPIKeyModel{Name: "ci", Scope: "profile"})
+	tokenService.On("CreateAPIKeyToken", mock.Anything, &token.APIKeyGrant{
+		KeyID:  created.ID,
+		UserID: "user",
+		Scopes: []string{"profile"},
+		Roles:  []string{"user"},
+	}).Return(&public_model.AccessTokenModel{AccessToken: "access_token"}, nil)
+
+	timeSource.now = timeSource.now.Add(time.Hour)
+	accessToken, err := svc.Exchange(context.TODO(), created.Key)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "access_token", accessToken.AccessToken)
+
+	keys, _ := svc.List(context.TODO(), owner)
+	assert.Equal(t, timeSource.now, *keys[0].ToAPIKeyModel().LastUsedAt)
+}
+
+func TestExchange_Rejected(t *testing.T) {
+	svc, timeSource, tokenService := newService()
+	created, _ := svc.Create(context.TODO(), owner, &public_model.CreateAPIKeyModel{Name: "ci", ExpiresInDays: 1})
+	revoked, _ := svc.Create(context.TODO(), owner, &public_model.CreateAPIKeyModel{Name: "old"})
+	assert.NoError(t, svc.Revoke(context.TODO(), owner, revoked.ID))
+
+	for _, key := range []string{
+		"",
+		"not-a-key",
+		apikey.KeyPrefix + created.ID,
+		created.Key + "x",
+		revoked.Key,
+	} {
+		_, err := svc.Exchange(context.TODO(), key)
+		assert.Equal(t, common_error.Unauthorized, serviceErrorCode(t, err), key)
+		assert.ErrorIs(t, serviceErrorCause(err), apikey.ErrInvalidKey, key)
+	}
+
+	_, err := svc.Exchange(tenant.WithTenant(context.TODO(), "acme"), created.Key)
+	assert.Equal(t, common_error.Unauthorized, serviceErrorCode(t, err))
+	assert.ErrorIs(t, serviceErrorCause(err), apikey.ErrInvalidKey)
+
+	timeSource.now = timeSource.now.Add(25 * time.Hour)
+	_, err = svc.Exchange(context.TODO(), created.Key)
+	assert.Equal(t, common_error.TokenExpired, serviceErrorCode(t, err))
+	assert.ErrorIs(t, serviceErrorCause(err), apikey.ErrKeyExpired)
+
+	tokenService.AssertNotCalled(t, "CreateAPIKeyToken", mock.Anything, mock.Anything)
+}
+
+func TestList_OnlyOwnKeys(t *testing.T) {
+	svc, timeSource, _ := newService()
+	first, _ := svc.Create(context.TODO(), owner, &public_model.CreateAPIKeyModel{Name: "first"})
+	timeSource.now = timeSource.now.Add(time.Minute)
+	second, _ := svc.Create(context.TODO(), owner, &public_model.CreateAPIKeyModel{Name: "second"})
+	_, _ = svc.Create(context.TODO(), &public_model.CustomClaims{UserID: "other-user"}, &public_model.CreateAPIKeyModel{Name: "other"})
+
+	keys, err := svc.List(context.TODO(), owner)
+
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+	assert.Equal(t, second.ID, keys[0].ID)
+	assert.Equal(t, first.ID, keys[1].ID)
+}
+
+func TestRevoke_OtherUser(t *testing.T) {
+	svc, _, _ := newService()
+	created, _ := svc.Create(context.TODO(), owner, &public_model.CreateAPIKeyModel{Name: "ci"})
+
+	err := svc.Revoke(context.TODO(), &public_model.CustomClaims{UserID: "other-user"}, created.ID)
+
+	assert.Equal(t, common_error.NotFound, serviceErrorCode(t, err))
+	_, err = svc.Store.Get(context.TODO(), created.ID)
+	assert.NoError(t, err)
+}
+
+func TestKeys_ScopedToTenant(t *testing.T) {
+	svc, _, _ := newService()
+	acmeOwner := &public_model.CustomClaims{UserID: "user", Tenant: "acme"}
+	globexOwner := &public_model.CustomClaims{UserID: "user", Tenant: "globex"}
+	acme, _ := svc.Create(context.TODO(), acmeOwner, &public_model.CreateAPIKeyModel{Name: "acme"})
+	globex, _ := svc.Create(context.TODO(), globexOwner, &public_model.CreateAPIKeyModel{Name: "globex"})
+
+	// The same user ID in another tenant is another user
+	keys, err := svc.List(context.TODO(), acmeOwner)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, acme.ID, keys[0].ID)
+
+	err = svc.Revoke(context.TODO(), acmeOwner, globex.ID)
+	assert.Equal(t, common_error.NotFound, serviceErrorCode(t, err))
+	_, err = svc.Store.Get(context.TODO(), globex.ID)
+	assert.NoError(t, err)
+}
+
+// revokingStore deletes every key right after it is read, as a Revoke racing with an Exchange would.
+type revokingStore struct {
+	*apikey.MemoryStore
+}
+
+func (s revokingStore) Get(ctx context.Context, keyID string) (*apikey.APIKey, error) {
+	key, err := s.MemoryStore.Get(ctx, keyID)
+	if err == nil {
+		_ = s.MemoryStore.Delete(ctx, keyID)
+	}
+	return key, err
+}
+
+func TestExchange_RevokedMeanwhile(t *testing.T) {
+	svc, _, tokenService := newService()
+	created, _ := svc.Create(context.TODO(), owner, &public_model.CreateAPIKeyModel{Name: "ci"})
+	svc.Store = revokingStore{svc.Store.(*apikey.MemoryStore)}
+
+	_, err := svc.Exchange(context.TODO(), created.Key)
+
+	assert.Equal(t, common_error.Unauthorized, serviceErrorCode(t, err))
+	keys, _ := svc.List(context.TODO(), owner)
+	assert.Empty(t, keys)
+	tokenService.AssertNotCalled(t, "CreateAPIKeyToken", mock.Anything, mock.Anything)
+}
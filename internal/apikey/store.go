@@ -0,0 +1,97 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by a store when an API key does not exist.
+var ErrNotFound = errors.New("api key not found")
+
+// IStore defines methods for persisting API keys.
+type IStore interface {
+	Save(ctx context.Context, key *APIKey) error
+	Get(ctx context.Context, keyID string) (*APIKey, error)
+	ListByUser(ctx context.Context, tenantID string, userID string) ([]*APIKey, error)
+	Delete(ctx context.Context, keyID string) error
+	// MarkUsed records when a key was last used, unless it has been deleted.
+	MarkUsed(ctx context.Context, keyID string, usedAt time.Time) error
+}
+
+// MemoryStore keeps API keys in memory. Keys do not survive a restart.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	keys map[string]APIKey
+}
+
+// NewMemoryStore initializes an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: make(map[string]APIKey)}
+}
+
+// Save implements IStore.
+func (m *MemoryStore) Save(ctx context.Context, key *APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[key.ID] = *key
+	return nil
+}
+
+// Get implements IStore.
+func (m *MemoryStore) Get(ctx context.Context, keyID string) (*APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	key, ok := m.keys[keyID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &key, nil
+}
+
+// ListByUser implements IStore. Keys are returned newest first.
+func (m *MemoryStore) ListByUser(ctx context.Context, tenantID string, userID string) ([]*APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := []*APIKey{}
+	for _, key := range m.keys {
+		if key.Tenant == tenantID && key.UserID == userID {
+			key := key
+			keys = append(keys, &key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// Delete implements IStore.
+func (m *MemoryStore) Delete(ctx context.Context, keyID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.keys[keyID]; !ok {
+		return ErrNotFound
+	}
+	delete(m.keys, keyID)
+	return nil
+}
+
+// MarkUsed implements IStore. Only the usage time is written, so that a key revoked meanwhile stays
+// deleted.
+func (m *MemoryStore) MarkUsed(ctx context.Context, keyID string, usedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key, ok := m.keys[keyID]
+	if !ok {
+		return ErrNotFound
+	}
+	key.LastUsedAt = usedAt
+	m.keys[keyID] = key
+	return nil
+}
+
+// Ensure MemoryStore implements IStore.
+var _ IStore = (*MemoryStore)(nil)
@@ -1,52 +1,107 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/config"
 	fiber_server "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/server"
 	grpc_server "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/grpc/server"
 	common_listener "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/grpc"
 )
 
+// ErrServerStopped is returned when a server stops serving without being asked to.
+var ErrServerStopped = errors.New("server stopped unexpectedly")
+
+// App runs the HTTP and gRPC servers and shuts them down together.
 type App struct {
+	Config      *config.Config
 	FiberServer *fiber_server.FiberServer
 	GRPCServer  *grpc_server.AuthGRPCServer
+	Closers     []io.Closer // Resources released once the servers have stopped, such as client connections
 }
 
-func NewApp(fiberServer *fiber_server.FiberServer, gRPCServer *grpc_server.AuthGRPCServer) *App {
+// NewApp initializes a new App. The closers are closed in order after shutdown.
+func NewApp(config *config.Config, fiberServer *fiber_server.FiberServer, gRPCServer *grpc_server.AuthGRPCServer, closers ...io.Closer) *App {
 	return &App{
+		Config:      config,
 		FiberServer: fiberServer,
 		GRPCServer:  gRPCServer,
+		Closers:     closers,
 	}
 }
 
-func (app *App) Run(httpPort string, gRPCPort string) {
-	// Channels to collect errors from the servers
-	httpErrChan := make(chan error)
-	grpcErrChan := make(chan error)
+// Run serves HTTP and gRPC on the configured addresses until ctx is cancelled, typically on SIGINT or
+// SIGTERM, or either server fails. The servers then stop accepting requests and drain in-flight ones
+// within the configured shutdown timeout, after which the closers are closed. Run returns the error that
+// stopped the servers, if any, joined with any error met while shutting down.
+func (app *App) Run(ctx context.Context) error {
+	if err := app.GRPCServer.InitServer(app.Config.GRPC.Address, &common_listener.DefaultListener{}); err != nil {
+		return errors.Join(fmt.Errorf("grpc server: %w", err), app.close())
+	}
 
-	// Run Fiber server
+	// Both servers report when they stop serving; buffered so that neither blocks after shutdown
+	serveErrs := make(chan error, 2)
+	go func() {
+		serveErrs <- serveError("http server", app.FiberServer.Run(app.Config.HTTP.Address))
+	}()
 	go func() {
-		httpErrChan <- app.FiberServer.Run(httpPort)
+		serveErrs <- serveError("grpc server", app.GRPCServer.Run())
 	}()
 
-	// Run gRPC server
+	var err error
+	select {
+	case <-ctx.Done():
+		slog.Info("Shutting down", "cause", context.Cause(ctx))
+	case err = <-serveErrs:
+		slog.Error("Shutting down", "error", err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), app.Config.Shutdown.Timeout)
+	defer cancel()
+	return errors.Join(err, app.shutdown(shutdownCtx), app.close())
+}
+
+// shutdown drains both servers at once, so that the deadline bounds the whole shutdown.
+func (app *App) shutdown(ctx context.Context) error {
+	var wg sync.WaitGroup
+	var httpErr, grpcErr error
+	wg.Add(2)
 	go func() {
-		err := app.GRPCServer.InitServer(gRPCPort, &common_listener.DefaultListener{})
-		if err != nil {
-			grpcErrChan <- err
-			return
+		defer wg.Done()
+		if err := app.FiberServer.Shutdown(ctx); err != nil {
+			httpErr = fmt.Errorf("http server shutdown: %w", err)
 		}
-
-		err = app.GRPCServer.Run()
-		if err != nil {
-			grpcErrChan <- err
+	}()
+	go func() {
+		defer wg.Done()
+		if err := app.GRPCServer.Shutdown(ctx); err != nil {
+			grpcErr = fmt.Errorf("grpc server shutdown: %w", err)
 		}
 	}()
+	wg.Wait()
+	return errors.Join(httpErr, grpcErr)
+}
 
-	// Wait for errors from the servers
-	select {
-	case err := <-httpErrChan:
-		panic(err)
-	case err := <-grpcErrChan:
-		panic(err)
+// close closes every closer, even when some fail.
+func (app *App) close() error {
+	var errs []error
+	for _, closer := range app.Closers {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// serveError names the server that stopped. Servers only stop on their own when something went wrong.
+func serveError(name string, err error) error {
+	if err == nil {
+		err = ErrServerStopped
 	}
+	return fmt.Errorf("%s: %w", name, err)
 }
@@ -0,0 +1,147 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/app"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/config"
+	fiber_server "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/server"
+	grpc_server "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/grpc/server"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockCloser struct {
+	closed bool
+	err    error
+}
+
+func (m *MockCloser) Close() error {
+	m.closed = true
+	return m.err
+}
+
+// freeAddress returns a local address nothing listens on.
+func freeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// newApp returns an app whose HTTP server answers GET /slow once release is closed, signalling entered
+// when a request arrives.
+func newApp(t *testing.T, timeout time.Duration, closers ...io.Closer) (application *app.App, entered chan struct{}, release chan struct{}) {
+	cfg := config.Default()
+	cfg.HTTP.Address = freeAddress(t)
+	cfg.GRPC.Address = freeAddress(t)
+	cfg.Shutdown.Timeout = timeout
+
+	entered = make(chan struct{})
+	release = make(chan struct{})
+	fiberApp := fiber.New(fiber.Config{DisableStartupMessage: true})
+	fiberApp.Get("/slow", func(c *fiber.Ctx) error {
+		close(entered)
+		<-release
+		return c.SendString("done")
+	})
+	return app.NewApp(cfg, &fiber_server.FiberServer{App: fiberApp}, &grpc_server.AuthGRPCServer{}, closers...), entered, release
+}
+
+// get requests GET /slow until the server is up and reports the outcome on the returned channel.
+func get(t *testing.T, address string) chan error {
+	result := make(chan error, 1)
+	go func() {
+		for i := 0; i < 100; i++ {
+			resp, err := http.Get("http://" + address + "/slow")
+			if err == nil {
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if string(body) != "done" {
+					err = errors.New("unexpected body " + string(body))
+				}
+				result <- err
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		result <- errors.New("server did not start")
+	}()
+	return result
+}
+
+func TestRun_DrainsOnCancel(t *testing.T) {
+	closer := &MockCloser{}
+	application, entered, release := newApp(t, 5*time.Second, closer)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- application.Run(ctx) }()
+	response := get(t, application.Config.HTTP.Address)
+
+	// A request is in flight when the signal arrives; it completes before Run returns
+	<-entered
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	assert.NoError(t, <-done)
+	assert.NoError(t, <-response)
+	assert.True(t, closer.closed)
+}
+
+func TestRun_ShutdownDeadline(t *testing.T) {
+	closer := &MockCloser{}
+	application, entered, release := newApp(t, 50*time.Millisecond, closer)
+	defer close(release)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- application.Run(ctx) }()
+	get(t, application.Config.HTTP.Address)
+
+	<-entered
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the shutdown timeout")
+	}
+	assert.True(t, closer.closed)
+}
+
+func TestRun_ServerError(t *testing.T) {
+	closer := &MockCloser{err: errors.New("close failed")}
+	application, _, _ := newApp(t, time.Second, closer)
+	// Something else already listens on the HTTP address
+	listener, err := net.Listen("tcp", application.Config.HTTP.Address)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	err = application.Run(context.Background())
+
+	assert.ErrorContains(t, err, "http server")
+	assert.ErrorContains(t, err, "close failed")
+	assert.True(t, closer.closed)
+}
+
+func TestRun_GRPCListenError(t *testing.T) {
+	closer := &MockCloser{}
+	application, _, _ := newApp(t, time.Second, closer)
+	listener, err := net.Listen("tcp", application.Config.GRPC.Address)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	err = application.Run(context.Background())
+
+	assert.ErrorContains(t, err, "grpc server")
+	assert.True(t, closer.closed)
+}
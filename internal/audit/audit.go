@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	internal_time "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/time"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+)
+
+// EventType names what happened.
+type EventType string
+
+// Event types recorded by the service.
+const (
+	LoginSuccess  EventType = "login_success"
+	LoginFailure  EventType = "login_failure"
+	Register      EventType = "register"
+	Refresh       EventType = "refresh"
+	Logout        EventType = "logout"
+	Lockout       EventType = "lockout"
+	MFAEnrolled   EventType = "mfa_enrolled"
+	Impersonation EventType = "impersonation"
+)
+
+// EventTypes lists every event type, in the order they are documented.
+var EventTypes = []EventType{LoginSuccess, LoginFailure, Register, Refresh, Logout, Lockout, MFAEnrolled, Impersonation}
+
+// Valid reports whether t is a known event type.
+func (t EventType) Valid() bool {
+	for _, eventType := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Outcome tells whether the recorded action succeeded.
+type Outcome string
+
+// Outcomes of recorded actions.
+const (
+	Success Outcome = "success"
+	Failure Outcome = "failure"
+)
+
+// Valid reports whether o is a known outcome.
+func (o Outcome) Valid() bool {
+	return o == Success || o == Failure
+}
+
+// Event is a single entry of the audit log.
+type Event struct {
+	ID        string            `json:"id"`
+	Type      EventType         `json:"type"`
+	Time      time.Time         `json:"time"`
+	Outcome   Outcome           `json:"outcome"`
+	ActorID   string            `json:"actor_id,omitempty"`   // User who performed the action
+	SubjectID string            `json:"subject_id,omitempty"` // User the action was performed on, if not the actor
+	Tenant    string            `json:"tenant,omitempty"`
+	IP        string            `json:"ip,omitempty"`
+	UserAgent string            `json:"user_agent,omitempty"`
+	Reason    string            `json:"reason,omitempty"` // Why the action was taken, or why it failed
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// ToAuditEventModel converts the event into its public representation.
+func (e *Event) ToAuditEventModel() *public_model.AuditEventModel {
+	return &public_model.AuditEventModel{
+		ID:        e.ID,
+		Type:      string(e.Type),
+		Time:      e.Time,
+		Outcome:   string(e.Outcome),
+		ActorID:   e.ActorID,
+		SubjectID: e.SubjectID,
+		Tenant:    e.Tenant,
+		IP:        e.IP,
+		UserAgent: e.UserAgent,
+		Reason:    e.Reason,
+		Details:   e.Details,
+	}
+}
+
+// ISink defines where audit events are written.
+type ISink interface {
+	Write(ctx context.Context, event *Event) error
+}
+
+// IRecorder defines methods for recording audit events.
+type IRecorder interface {
+	Record(ctx context.Context, event *Event) error
+}
+
+// Recorder completes events with an ID, the current time and the request's client and tenant,
+// and writes them to a sink.
+type Recorder struct {
+	Sink ISink                    // Destination of the events
+	Time internal_time.TimeSource // Source to get the current time
+}
+
+// NewRecorder initializes a new Recorder with necessary dependencies.
+func NewRecorder(sink ISink, time internal_time.TimeSource) *Recorder {
+	return &Recorder{Sink: sink, Time: time}
+}
+
+// Record implements IRecorder. Fields already set on the event are kept.
+func (r *Recorder) Record(ctx context.Context, event *Event) error {
+	if event.ID == "" {
+		id, err := newEventID()
+		if err != nil {
+			return err
+		}
+		event.ID = id
+	}
+	if event.Time.IsZero() {
+		event.Time = r.Time.Now()
+	}
+	clientInfo := session.ClientInfoFromContext(ctx)
+	if event.IP == "" {
+		event.IP = clientInfo.IP
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = clientInfo.UserAgent
+	}
+	if event.Tenant == "" {
+		event.Tenant = tenant.FromContext(ctx)
+	}
+	return r.Sink.Write(ctx, event)
+}
+
+// newEventID generates a random 128-bit event identifier.
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Ensure Recorder implements IRecorder.
+var _ IRecorder = (*Recorder)(nil)
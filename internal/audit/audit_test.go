@@ -0,0 +1,68 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/audit"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTimeSource struct {
+	now time.Time
+}
+
+func (m *MockTimeSource) Now() time.Time {
+	return m.now
+}
+
+type MockSink struct {
+	mock.Mock
+}
+
+func (m *MockSink) Write(ctx context.Context, event *audit.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func TestRecord_CompletesEvent(t *testing.T) {
+	sink := new(MockSink)
+	timeSource := &MockTimeSource{now: time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)}
+	recorder := audit.NewRecorder(sink, timeSource)
+	sink.On("Write", mock.Anything, mock.Anything).Return(nil)
+
+	ctx := session.WithClientInfo(context.TODO(), session.ClientInfo{IP: "127.0.0.1", UserAgent: "test-agent"})
+	ctx = tenant.WithTenant(ctx, "acme")
+	event := &audit.Event{Type: audit.Impersonation, Outcome: audit.Success, ActorID: "admin"}
+
+	err := recorder.Record(ctx, event)
+
+	assert.NoError(t, err)
+	assert.Len(t, event.ID, 32)
+	assert.Equal(t, timeSource.now, event.Time)
+	assert.Equal(t, "127.0.0.1", event.IP)
+	assert.Equal(t, "test-agent", event.UserAgent)
+	assert.Equal(t, "acme", event.Tenant)
+	sink.AssertCalled(t, "Write", mock.Anything, event)
+}
+
+func TestRecord_KeepsSetFields(t *testing.T) {
+	sink := new(MockSink)
+	recorder := audit.NewRecorder(sink, &MockTimeSource{now: time.Now()})
+	sink.On("Write", mock.Anything, mock.Anything).Return(nil)
+
+	at := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := &audit.Event{ID: "event", Time: at, Tenant: "globex", IP: "10.0.0.1"}
+
+	err := recorder.Record(tenant.WithTenant(context.TODO(), "acme"), event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "event", event.ID)
+	assert.Equal(t, at, event.Time)
+	assert.Equal(t, "globex", event.Tenant)
+	assert.Equal(t, "10.0.0.1", event.IP)
+}
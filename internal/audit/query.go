@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// Filter selects audit events. Zero fields match every event, except Tenant, which always has to match
+// so that events of one tenant never show up in another's queries.
+type Filter struct {
+	Type      EventType
+	ActorID   string
+	SubjectID string
+	Tenant    string
+	Outcome   Outcome
+	Since     time.Time // Events before this time are skipped
+	Until     time.Time // Events at or after this time are skipped
+	Limit     int       // Most events returned, zero for no limit
+}
+
+// Matches reports whether the event is selected by the filter.
+func (f *Filter) Matches(event *Event) bool {
+	switch {
+	case event.Tenant != f.Tenant:
+		return false
+	case f.Type != "" && event.Type != f.Type:
+		return false
+	case f.ActorID != "" && event.ActorID != f.ActorID:
+		return false
+	case f.SubjectID != "" && event.SubjectID != f.SubjectID:
+		return false
+	case f.Outcome != "" && event.Outcome != f.Outcome:
+		return false
+	case !f.Since.IsZero() && event.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !event.Time.Before(f.Until):
+		return false
+	}
+	return true
+}
+
+// IQuerier defines methods for reading back recorded events.
+type IQuerier interface {
+	// Query returns the events selected by the filter, newest first.
+	Query(ctx context.Context, filter *Filter) ([]*Event, error)
+}
+
+// newestFirst returns the events, which are stored oldest first, in reverse order and cut to the limit.
+func newestFirst(events []*Event, limit int) []*Event {
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	result := make([]*Event, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		result = append(result, events[i])
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+)
+
+const (
+	// DefaultQueryLimit is the number of events returned when a query names no limit.
+	DefaultQueryLimit = 100
+	// MaxQueryLimit is the most events a query returns, whatever limit it names.
+	MaxQueryLimit = 500
+)
+
+// IAuditService defines methods for admins to browse the audit log.
+type IAuditService interface {
+	List(ctx context.Context, caller *public_model.CustomClaims, query *public_model.AuditQueryModel) ([]*public_model.AuditEventModel, error)
+}
+
+// AuditService contains fields necessary for querying the audit log.
+type AuditService struct {
+	Querier IQuerier // Reads back recorded events
+}
+
+// NewAuditService initializes a new AuditService with necessary dependencies.
+func NewAuditService(querier IQuerier) *AuditService {
+	return &AuditService{Querier: querier}
+}
+
+// List returns the events selected by the query, newest first. Callers only see events of their own tenant.
+func (s *AuditService) List(ctx context.Context, caller *public_model.CustomClaims, query *public_model.AuditQueryModel) ([]*public_model.AuditEventModel, error) {
+	filter := &Filter{
+		Type:      EventType(query.Type),
+		ActorID:   query.ActorID,
+		SubjectID: query.SubjectID,
+		Tenant:    caller.Tenant,
+		Outcome:   Outcome(query.Outcome),
+		Limit:     query.Limit,
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultQueryLimit
+	}
+	if filter.Limit > MaxQueryLimit {
+		filter.Limit = MaxQueryLimit
+	}
+	var err error
+	if filter.Since, err = parseTime(query.Since); err != nil {
+		return nil, common_error.NewServiceError(common_error.BadRequest, "Invalid since time", err)
+	}
+	if filter.Until, err = parseTime(query.Until); err != nil {
+		return nil, common_error.NewServiceError(common_error.BadRequest, "Invalid until time", err)
+	}
+
+	events, err := s.Querier.Query(ctx, filter)
+	if err != nil {
+		return nil, common_error.NewServiceError(common_error.ServiceUnavailable, "Audit log unavailable", err)
+	}
+
+	eventModels := make([]*public_model.AuditEventModel, 0, len(events))
+	for _, event := range events {
+		eventModels = append(eventModels, event.ToAuditEventModel())
+	}
+	return eventModels, nil
+}
+
+// parseTime parses an optional RFC 3339 time, returning the zero time for an empty string.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// Ensure AuditService implements IAuditService.
+var _ IAuditService = (*AuditService)(nil)
@@ -0,0 +1,81 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/audit"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockQuerier struct {
+	mock.Mock
+}
+
+func (m *MockQuerier) Query(ctx context.Context, filter *audit.Filter) ([]*audit.Event, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*audit.Event), args.Error(1)
+}
+
+func TestAuditService_List(t *testing.T) {
+	sink := audit.NewMemorySink(0)
+	writeEvents(t, sink)
+	service := audit.NewAuditService(sink)
+
+	events, err := service.List(context.TODO(), &public_model.CustomClaims{UserID: "admin"}, &public_model.AuditQueryModel{
+		Type:  "login_success",
+		Since: "2023-11-01T12:00:00Z",
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "2", events[0].ID)
+	assert.Equal(t, "login_success", events[0].Type)
+	assert.Equal(t, "success", events[0].Outcome)
+
+	// Admins of a tenant only see the events of their tenant
+	events, err = service.List(context.TODO(), &public_model.CustomClaims{UserID: "admin", Tenant: "acme"}, &public_model.AuditQueryModel{})
+
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "acme", events[0].Tenant)
+}
+
+func TestAuditService_List_DefaultLimit(t *testing.T) {
+	querier := new(MockQuerier)
+	service := audit.NewAuditService(querier)
+	querier.On("Query", mock.Anything, &audit.Filter{ActorID: "user", Limit: audit.DefaultQueryLimit}).Return([]*audit.Event{}, nil)
+
+	events, err := service.List(context.TODO(), &public_model.CustomClaims{UserID: "admin"}, &public_model.AuditQueryModel{ActorID: "user"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+	querier.AssertExpectations(t)
+}
+
+func TestAuditService_List_MaxLimit(t *testing.T) {
+	querier := new(MockQuerier)
+	service := audit.NewAuditService(querier)
+	querier.On("Query", mock.Anything, &audit.Filter{Limit: audit.MaxQueryLimit}).Return([]*audit.Event{}, nil)
+
+	_, err := service.List(context.TODO(), &public_model.CustomClaims{UserID: "admin"}, &public_model.AuditQueryModel{Limit: 1_000_000})
+
+	assert.NoError(t, err)
+	querier.AssertExpectations(t)
+}
+
+func TestAuditService_List_Unavailable(t *testing.T) {
+	querier := new(MockQuerier)
+	service := audit.NewAuditService(querier)
+	querier.On("Query", mock.Anything, mock.Anything).Return([]*audit.Event(nil), errors.New("disk error"))
+
+	_, err := service.List(context.TODO(), &public_model.CustomClaims{UserID: "admin"}, &public_model.AuditQueryModel{})
+
+	serviceError, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.ServiceUnavailable, serviceError.Code)
+}
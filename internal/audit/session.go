@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+)
+
+// SessionService wraps a session service and records a logout event whenever sessions are revoked.
+type SessionService struct {
+	session.ISessionService           // Service the calls are passed on to
+	Audit                   IRecorder // Records the logouts
+}
+
+// NewSessionService initializes a new SessionService recording the logouts of sessionService.
+func NewSessionService(sessionService session.ISessionService, auditRecorder IRecorder) *SessionService {
+	return &SessionService{ISessionService: sessionService, Audit: auditRecorder}
+}
+
+// Revoke implements session.ISessionService.
+func (s *SessionService) Revoke(ctx context.Context, tenantID string, userID string, sessionID string) error {
+	err := s.ISessionService.Revoke(ctx, tenantID, userID, sessionID)
+	s.recordLogout(ctx, tenantID, userID, map[string]string{"session_id": sessionID}, err)
+	return err
+}
+
+// RevokeAll implements session.ISessionService.
+func (s *SessionService) RevokeAll(ctx context.Context, tenantID string, userID string) error {
+	err := s.ISessionService.RevokeAll(ctx, tenantID, userID)
+	s.recordLogout(ctx, tenantID, userID, map[string]string{"session_id": "*"}, err)
+	return err
+}
+
+// recordLogout records the outcome of a revocation. Failing to record it does not fail the logout.
+func (s *SessionService) recordLogout(ctx context.Context, tenantID string, userID string, details map[string]string, err error) {
+	event := &Event{Type: Logout, Outcome: Success, ActorID: userID, Tenant: tenantID, Details: details}
+	if err != nil {
+		event.Outcome = Failure
+		event.Reason = err.Error()
+	}
+	if recordErr := s.Audit.Record(ctx, event); recordErr != nil {
+		slog.ErrorContext(ctx, "Failed to record logout", "user_id", userID, "error", recordErr)
+	}
+}
+
+// Ensure SessionService implements session.ISessionService.
+var _ session.ISessionService = (*SessionService)(nil)
@@ -0,0 +1,59 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/audit"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSessionService struct {
+	mock.Mock
+	session.ISessionService
+}
+
+func (m *MockSessionService) Revoke(ctx context.Context, tenantID string, userID string, sessionID string) error {
+	args := m.Called(ctx, tenantID, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) RevokeAll(ctx context.Context, tenantID string, userID string) error {
+	args := m.Called(ctx, tenantID, userID)
+	return args.Error(0)
+}
+
+func TestSessionService_RecordsLogout(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	sink := audit.NewMemorySink(0)
+	sessionService := audit.NewSessionService(mockSessionService, audit.NewRecorder(sink, &MockTimeSource{now: start}))
+
+	mockSessionService.On("Revoke", mock.Anything, "acme", "user", "session").Return(nil)
+	mockSessionService.On("RevokeAll", mock.Anything, "acme", "user").Return(errors.New("store down"))
+
+	assert.NoError(t, sessionService.Revoke(context.TODO(), "acme", "user", "session"))
+	assert.EqualError(t, sessionService.RevokeAll(context.TODO(), "acme", "user"), "store down")
+
+	events, err := sink.Query(context.TODO(), &audit.Filter{Type: audit.Logout, ActorID: "user", Tenant: "acme"})
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, audit.Failure, events[0].Outcome)
+	assert.Equal(t, "store down", events[0].Reason)
+	assert.Equal(t, audit.Success, events[1].Outcome)
+	assert.Equal(t, map[string]string{"session_id": "session"}, events[1].Details)
+}
+
+func TestSessionService_RecordFailure(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	failing := new(MockSink)
+	sessionService := audit.NewSessionService(mockSessionService, audit.NewRecorder(failing, &MockTimeSource{now: start}))
+
+	mockSessionService.On("Revoke", mock.Anything, "acme", "user", "session").Return(nil)
+	failing.On("Write", mock.Anything, mock.Anything).Return(errors.New("sink down"))
+
+	// The logout itself still succeeds
+	assert.NoError(t, sessionService.Revoke(context.TODO(), "acme", "user", "session"))
+}
@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// LogSink writes each event to the default structured logger.
+type LogSink struct{}
+
+// NewLogSink initializes a new LogSink.
+func NewLogSink() *LogSink {
+	return &LogSink{}
+}
+
+// Write implements ISink.
+func (l *LogSink) Write(ctx context.Context, event *Event) error {
+	slog.InfoContext(ctx, "Audit event", "event", event)
+	return nil
+}
+
+// MemorySink keeps the most recent events in memory. It suits tests and single-instance deployments
+// that do not need the log to survive a restart.
+type MemorySink struct {
+	mu       sync.RWMutex
+	events   []*Event
+	capacity int
+}
+
+// NewMemorySink initializes a new MemorySink holding at most capacity events, dropping the oldest
+// when full. A capacity of zero or less keeps every event.
+func NewMemorySink(capacity int) *MemorySink {
+	return &MemorySink{capacity: capacity}
+}
+
+// Write implements ISink.
+func (m *MemorySink) Write(ctx context.Context, event *Event) error {
+	stored := *event
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, &stored)
+	if m.capacity > 0 && len(m.events) > m.capacity {
+		m.events = append(m.events[:0], m.events[len(m.events)-m.capacity:]...)
+	}
+	return nil
+}
+
+// Query implements IQuerier.
+func (m *MemorySink) Query(ctx context.Context, filter *Filter) ([]*Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var events []*Event
+	for _, event := range m.events {
+		if filter.Matches(event) {
+			events = append(events, event)
+		}
+	}
+	return newestFirst(events, filter.Limit), nil
+}
+
+// FileSink appends each event as a JSON line to a file, the format log shippers expect.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileSink opens, or creates, the file at path for appending events.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{path: path, file: file}, nil
+}
+
+// Write implements ISink. Each event is written with a single call, so lines are never interleaved.
+func (f *FileSink) Write(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err = f.file.Write(append(data, '\n'))
+	return err
+}
+
+// Query implements IQuerier by reading the file back. Lines that are not valid events are skipped.
+// Only the last Limit matches are kept while scanning, so memory does not grow with the file.
+func (f *FileSink) Query(ctx context.Context, filter *Filter) ([]*Event, error) {
+	f.mu.Lock()
+	file, err := os.Open(f.path)
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []*Event
+	oldest := 0 // Once Limit events are kept, the index of the oldest, which the next match replaces
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		event := &Event{}
+		if err := json.Unmarshal(scanner.Bytes(), event); err != nil {
+			continue
+		}
+		if !filter.Matches(event) {
+			continue
+		}
+		if filter.Limit <= 0 || len(events) < filter.Limit {
+			events = append(events, event)
+		} else {
+			events[oldest] = event
+			oldest = (oldest + 1) % filter.Limit
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	ordered := append(make([]*Event, 0, len(events)), events[oldest:]...)
+	return newestFirst(append(ordered, events[:oldest]...), filter.Limit), nil
+}
+
+// Close closes the underlying file.
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// MultiSink writes every event to each of its sinks.
+type MultiSink []ISink
+
+// NewMultiSink initializes a new MultiSink.
+func NewMultiSink(sinks ...ISink) MultiSink {
+	return MultiSink(sinks)
+}
+
+// Write implements ISink. An event is offered to every sink even if an earlier one fails.
+func (m MultiSink) Write(ctx context.Context, event *Event) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Write(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Ensure sinks implement ISink and IQuerier.
+var (
+	_ ISink    = (*LogSink)(nil)
+	_ ISink    = (*MemorySink)(nil)
+	_ IQuerier = (*MemorySink)(nil)
+	_ ISink    = (*FileSink)(nil)
+	_ IQuerier = (*FileSink)(nil)
+	_ ISink    = MultiSink(nil)
+)
@@ -0,0 +1,128 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var start = time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+
+// writeEvents writes a login failure, a login success and a refresh, a minute apart.
+func writeEvents(t *testing.T, sink audit.ISink) {
+	for i, event := range []*audit.Event{
+		{ID: "1", Type: audit.LoginFailure, Outcome: audit.Failure, ActorID: "user"},
+		{ID: "2", Type: audit.LoginSuccess, Outcome: audit.Success, ActorID: "user"},
+		{ID: "3", Type: audit.Refresh, Outcome: audit.Success, ActorID: "other"},
+		{ID: "4", Type: audit.LoginSuccess, Outcome: audit.Success, ActorID: "user", Tenant: "acme"},
+	} {
+		event.Time = start.Add(time.Duration(i) * time.Minute)
+		assert.NoError(t, sink.Write(context.TODO(), event))
+	}
+}
+
+func ids(events []*audit.Event) []string {
+	result := make([]string, 0, len(events))
+	for _, event := range events {
+		result = append(result, event.ID)
+	}
+	return result
+}
+
+func testQuery(t *testing.T, querier audit.IQuerier) {
+	for _, tc := range []struct {
+		filter audit.Filter
+		want   []string
+	}{
+		{audit.Filter{}, []string{"3", "2", "1"}},
+		{audit.Filter{Tenant: "acme"}, []string{"4"}},
+		{audit.Filter{ActorID: "user"}, []string{"2", "1"}},
+		{audit.Filter{Type: audit.LoginFailure}, []string{"1"}},
+		{audit.Filter{Outcome: audit.Success}, []string{"3", "2"}},
+		{audit.Filter{Since: start.Add(time.Minute)}, []string{"3", "2"}},
+		{audit.Filter{Until: start.Add(time.Minute)}, []string{"1"}},
+		{audit.Filter{Limit: 2}, []string{"3", "2"}},
+		{audit.Filter{Limit: 1}, []string{"3"}},
+		{audit.Filter{ActorID: "user", Limit: 2}, []string{"2", "1"}},
+	} {
+		events, err := querier.Query(context.TODO(), &tc.filter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, ids(events), "%+v", tc.filter)
+	}
+}
+
+func TestMemorySink_Query(t *testing.T) {
+	sink := audit.NewMemorySink(0)
+	writeEvents(t, sink)
+
+	testQuery(t, sink)
+}
+
+func TestMemorySink_Capacity(t *testing.T) {
+	sink := audit.NewMemorySink(2)
+	writeEvents(t, sink)
+
+	events, err := sink.Query(context.TODO(), &audit.Filter{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3"}, ids(events))
+}
+
+func TestFileSink_Query(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := audit.NewFileSink(path)
+	assert.NoError(t, err)
+	defer sink.Close()
+	writeEvents(t, sink)
+
+	testQuery(t, sink)
+
+	// One JSON document per line
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `{"id":"1","type":"login_failure","time":"2023-11-01T12:00:00Z","outcome":"failure","actor_id":"user"}`+"\n")
+}
+
+func TestFileSink_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := audit.NewFileSink(path)
+	assert.NoError(t, err)
+	writeEvents(t, sink)
+	assert.NoError(t, sink.Close())
+
+	// Events written before a restart are kept, and lines that are not events are skipped
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	assert.NoError(t, err)
+	_, err = file.WriteString("not json\n")
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	sink, err = audit.NewFileSink(path)
+	assert.NoError(t, err)
+	defer sink.Close()
+	assert.NoError(t, sink.Write(context.TODO(), &audit.Event{ID: "5", Time: start.Add(time.Hour)}))
+
+	events, err := sink.Query(context.TODO(), &audit.Filter{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"5", "3", "2", "1"}, ids(events))
+}
+
+func TestMultiSink_Write(t *testing.T) {
+	failing := new(MockSink)
+	failing.On("Write", mock.Anything, mock.Anything).Return(errors.New("sink down"))
+	memory := audit.NewMemorySink(0)
+
+	err := audit.NewMultiSink(failing, memory).Write(context.TODO(), &audit.Event{ID: "1"})
+
+	assert.EqualError(t, err, "sink down")
+	events, _ := memory.Query(context.TODO(), &audit.Filter{})
+	assert.Equal(t, []string{"1"}, ids(events))
+}
@@ -2,28 +2,49 @@ package auth
 
 import (
 	"context"
-	"time"
+	"errors"
+	"log/slog"
 
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/audit"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/event"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/scope"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tracing"
 	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
 	common_crypto "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/crypto"
 	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
 	"github.com/Bit-Bridge-Source/BitBridge-UserService-Go/proto/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
+// ErrAccountLocked and ErrMFARequired are the causes of the service errors a login returns when the user
+// service refuses it because of the state of the account rather than the credentials.
+var (
+	ErrAccountLocked = errors.New("account locked")
+	ErrMFARequired   = errors.New("multi-factor authentication required")
+)
+
 // IAuthService defines methods for user authentication services.
 type IAuthService interface {
 	Register(ctx context.Context, registerModel *public_model.RegisterModel) (*public_model.TokenModel, error)
 	Login(ctx context.Context, loginModel *public_model.LoginModel) (*public_model.TokenModel, error)
+	Refresh(ctx context.Context, refreshModel *public_model.TokenRefreshModel) (*public_model.TokenModel, error)
+	Impersonate(ctx context.Context, actor *public_model.CustomClaims, impersonateModel *public_model.ImpersonateModel) (*public_model.AccessTokenModel, error)
 }
 
 // AuthService is the struct containing services and configurations for authentication.
 type AuthService struct {
-	TokenService      token.ITokenService   // Handles token creation and validation
-	Crypto            common_crypto.ICrypto // Handles cryptographic operations
-	UserServiceClient pb.UserServiceClient  // Factory function to create a new UserService client
+	TokenService      token.ITokenService     // Handles token creation and validation
+	Crypto            common_crypto.ICrypto   // Handles cryptographic operations
+	UserServiceClient pb.UserServiceClient    // Factory function to create a new UserService client
+	SessionService    session.ISessionService // Tracks the session behind each refresh-token family
+	Audit             audit.IRecorder         // Records security-relevant actions
+	Events            event.IPublisher        // Publishes domain events for other services
 }
 
 // NewAuthService is a constructor for creating an instance of AuthService with necessary dependencies.
@@ -31,18 +52,23 @@ func NewAuthService(
 	tokenService token.ITokenService,
 	crypto common_crypto.ICrypto,
 	userServiceClient pb.UserServiceClient,
+	sessionService session.ISessionService,
+	auditRecorder audit.IRecorder,
+	publisher event.IPublisher,
 ) *AuthService {
 	return &AuthService{
 		TokenService:      tokenService,
 		Crypto:            crypto,
 		UserServiceClient: userServiceClient,
+		SessionService:    sessionService,
+		Audit:             auditRecorder,
+		Events:            publisher,
 	}
 }
 
 // createUser creates a new user by communicating with the user service.
-func (authService *AuthService) createUser(ctx context.Context, registerModel *public_model.RegisterModel, token string) (string, error) {
-	md := metadata.Pairs("Authorization", "Bearer "+token)
-	ctx = metadata.NewOutgoingContext(ctx, md)
+func (authService *AuthService) createUser(ctx context.Context, registerModel *public_model.RegisterModel, token string, tenantID string) (*pb.UserResponse, error) {
+	ctx = userServiceContext(ctx, token, tenantID)
 	userCreate := &pb.CreateUserRequest{
 		Email:    registerModel.Email,
 		Username: registerModel.Username,
@@ -50,61 +76,309 @@ func (authService *AuthService) createUser(ctx context.Context, registerModel *p
 	}
 	resp, err := authService.UserServiceClient.CreateUser(ctx, userCreate)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return resp.GetId(), nil
+	return &pb.UserResponse{
+		Id:        resp.GetId(),
+		Username:  resp.GetUsername(),
+		Email:     registerModel.Email,
+		CreatedAt: resp.GetCreatedAt(),
+		UpdatedAt: resp.GetUpdatedAt(),
+	}, nil
 }
 
 // Register registers a new user, creates and returns a new token pair for the registered user.
+// The attempt is recorded in the audit log, and a successful registration is published as a domain event.
 func (authService *AuthService) Register(ctx context.Context, registerModel *public_model.RegisterModel) (*public_model.TokenModel, error) {
-	token, err := authService.TokenService.CreateToken(ctx, "-1", time.Minute*15)
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer span.End()
+
+	auditEvent := &audit.Event{Type: audit.Register, Details: map[string]string{"username": registerModel.Username}}
+	tokenModel, err := authService.register(ctx, registerModel, auditEvent)
+	tracing.Fail(span, err)
+	authService.record(ctx, auditEvent, err)
+	if err != nil {
+		return nil, err
+	}
+
+	authService.publish(ctx, &event.Event{
+		Type:   event.UserRegistered,
+		Tenant: auditEvent.Tenant,
+		UserID: auditEvent.ActorID,
+		Data:   map[string]string{"username": registerModel.Username, "email": registerModel.Email},
+	})
+	return tokenModel, nil
+}
+
+// register creates the user and starts its first session, naming the user and tenant on the audit event.
+func (authService *AuthService) register(ctx context.Context, registerModel *public_model.RegisterModel, auditEvent *audit.Event) (*public_model.TokenModel, error) {
+	tenantID, err := tenant.Select(ctx, registerModel.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	auditEvent.Tenant = tenantID
+
+	policy, err := authService.TokenService.Policy(registerModel.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := authService.TokenService.CreateServiceToken(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	userID, err := authService.createUser(ctx, registerModel, token)
+	user, err := authService.createUser(ctx, registerModel, token, tenantID)
 	if err != nil {
 		// Repackage the error with the correct error code and message
 		st, ok := status.FromError(err)
 		if !ok {
 			return nil, err
 		}
-		return nil, common_error.NewServiceError(int(st.Code()), st.Message(), err)
+		return nil, common_error.NewServiceError(serviceErrorCode(st.Code()), st.Message(), err)
 	}
+	auditEvent.ActorID = user.GetId()
+
+	return authService.startSession(ctx, user, tenantID, policy, scope.Parse(registerModel.Scope))
+}
 
-	tokenModel, err := authService.TokenService.CreateTokenPair(ctx, userID)
+// Login authenticates a user, and if successful, creates and returns a new token pair for the user.
+// Successful and failed attempts are recorded in the audit log, and successful ones are published as
+// domain events.
+func (authService *AuthService) Login(ctx context.Context, loginModel *public_model.LoginModel) (*public_model.TokenModel, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+
+	auditEvent := &audit.Event{Type: audit.LoginSuccess, Details: map[string]string{"identifier": loginModel.LoginIdentifier()}}
+	tokenModel, err := authService.login(ctx, loginModel, auditEvent)
+	tracing.Fail(span, err)
+	if err != nil {
+		auditEvent.Type = audit.LoginFailure
+	}
+	authService.record(ctx, auditEvent, err)
 	if err != nil {
 		return nil, err
 	}
 
+	clientInfo := session.ClientInfoFromContext(ctx)
+	authService.publish(ctx, &event.Event{
+		Type:   event.UserLoggedIn,
+		Tenant: auditEvent.Tenant,
+		UserID: auditEvent.ActorID,
+		Data:   map[string]string{"client_id": loginModel.ClientID, "ip": clientInfo.IP, "user_agent": clientInfo.UserAgent},
+	})
 	return tokenModel, nil
 }
 
-// Login authenticates a user, and if successful, creates and returns a new token pair for the user.
-func (authService *AuthService) Login(ctx context.Context, loginModel *public_model.LoginModel) (*public_model.TokenModel, error) {
-	token, err := authService.TokenService.CreateToken(ctx, "-1", time.Minute*15)
+// login checks the credentials and starts a session, naming the user and tenant on the audit event.
+func (authService *AuthService) login(ctx context.Context, loginModel *public_model.LoginModel, auditEvent *audit.Event) (*public_model.TokenModel, error) {
+	tenantID, err := tenant.Select(ctx, loginModel.TenantID)
 	if err != nil {
 		return nil, err
 	}
+	auditEvent.Tenant = tenantID
 
-	md := metadata.Pairs("Authorization", "Bearer "+token)
-	ctx = metadata.NewOutgoingContext(ctx, md)
+	policy, err := authService.TokenService.Policy(loginModel.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := authService.TokenService.CreateServiceToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = userServiceContext(ctx, token, tenantID)
 
 	identifierRequest := &pb.IdentifierRequest{
-		UserIdentifier: loginModel.Email,
+		UserIdentifier: loginModel.LoginIdentifier(),
 	}
 
 	user, err := authService.UserServiceClient.GetPrivateUserByIdentifier(ctx, identifierRequest)
 	if err != nil {
-		return nil, common_error.NewServiceError(common_error.Unauthorized, "Invalid credentials", err)
+		if serviceErrorCode(status.Code(err)) == common_error.ServiceUnavailable {
+			return nil, common_error.NewServiceError(common_error.ServiceUnavailable, "User service unavailable", err)
+		}
+		if refused := accountRefusal(err); refused != nil {
+			return nil, refused
+		}
+		return nil, common_error.NewServiceError(common_error.InvalidCredentials, "Invalid credentials", err)
 	}
+	auditEvent.ActorID = user.GetId()
 
-	err = authService.Crypto.CompareHashAndPassword(user.GetHash(), loginModel.Password)
+	err = authService.comparePassword(ctx, user.GetHash(), loginModel.Password)
+	if err != nil {
+		return nil, common_error.NewServiceError(common_error.InvalidCredentials, "Invalid credentials", err)
+	}
+
+	return authService.startSession(ctx, user, tenantID, policy, scope.Parse(loginModel.Scope))
+}
+
+// Refresh exchanges a refresh token for a new token pair in the same session.
+// Revoked and timed out sessions cannot be refreshed, and neither can sessions of another tenant.
+// The attempt is recorded in the audit log.
+func (authService *AuthService) Refresh(ctx context.Context, refreshModel *public_model.TokenRefreshModel) (*public_model.TokenModel, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Refresh")
+	defer span.End()
+
+	auditEvent := &audit.Event{Type: audit.Refresh}
+	tokenModel, err := authService.refresh(ctx, refreshModel, auditEvent)
+	tracing.Fail(span, err)
+	authService.record(ctx, auditEvent, err)
+	return tokenModel, err
+}
+
+// refresh validates the refresh token and rotates it, naming the user, session and tenant on the audit event.
+func (authService *AuthService) refresh(ctx context.Context, refreshModel *public_model.TokenRefreshModel, auditEvent *audit.Event) (*public_model.TokenModel, error) {
+	tenantID, err := tenant.Select(ctx, refreshModel.TenantID)
 	if err != nil {
 		return nil, err
 	}
+	auditEvent.Tenant = tenantID
+
+	claims, err := authService.TokenService.ParseToken(ctx, refreshModel.Token)
+	if err != nil || claims.TokenType != token.RefreshTokenType || claims.Actor != nil {
+		return nil, common_error.NewServiceError(common_error.TokenInvalid, "Invalid refresh token", err)
+	}
+	auditEvent.ActorID = claims.UserID
+	auditEvent.Details = map[string]string{"session_id": claims.SessionID}
+	if claims.Tenant != tenantID {
+		return nil, common_error.NewServiceError(common_error.TokenInvalid, "Invalid refresh token", nil)
+	}
+
+	// Refreshed tokens stay under the policy of the client the session was started for
+	policy, err := authService.TokenService.Policy(claims.Audience)
+	if err != nil {
+		return nil, common_error.NewServiceError(common_error.TokenInvalid, "Invalid refresh token", err)
+	}
 
-	tokenModel, err := authService.TokenService.CreateTokenPair(ctx, user.GetId())
+	userSession, err := authService.SessionService.Refresh(ctx, claims.Tenant, claims.UserID, claims.SessionID, claims.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return authService.TokenService.CreateTokenPair(ctx, &token.Subject{
+		Session:  userSession,
+		Scopes:   scope.Parse(refreshModel.Scope),
+		Previous: claims,
+	}, policy)
+}
+
+// Impersonate issues a short-lived access token that lets the actor, an admin, act as another user of
+// the same tenant. Every attempt is recorded in the audit log, and no token is handed out unless its
+// issuance was recorded.
+func (authService *AuthService) Impersonate(ctx context.Context, actor *public_model.CustomClaims, impersonateModel *public_model.ImpersonateModel) (*public_model.AccessTokenModel, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Impersonate")
+	defer span.End()
+
+	auditEvent := &audit.Event{
+		Type:    audit.Impersonation,
+		ActorID: actor.UserID,
+		Tenant:  actor.Tenant,
+		Reason:  impersonateModel.Reason,
+		Details: map[string]string{"identifier": impersonateModel.Identifier},
+	}
+
+	tokenModel, err := authService.impersonate(ctx, actor, impersonateModel, auditEvent)
+	tracing.Fail(span, err)
+	if err != nil {
+		auditEvent.Outcome = audit.Failure
+		auditEvent.Details["error"] = err.Error()
+		if recordErr := authService.Audit.Record(ctx, auditEvent); recordErr != nil {
+			slog.ErrorContext(ctx, "Failed to record impersonation attempt", "actor_id", actor.UserID, "error", recordErr)
+		}
+		return nil, err
+	}
+
+	auditEvent.Outcome = audit.Success
+	if err := authService.Audit.Record(ctx, auditEvent); err != nil {
+		return nil, common_error.NewServiceError(common_error.ServiceUnavailable, "Impersonation could not be recorded", err)
+	}
+
+	return tokenModel, nil
+}
+
+// impersonate looks up the target user and issues the impersonation token, naming the user on the audit event.
+func (authService *AuthService) impersonate(ctx context.Context, actor *public_model.CustomClaims, impersonateModel *public_model.ImpersonateModel, auditEvent *audit.Event) (*public_model.AccessTokenModel, error) {
+	if actor.Actor != nil || actor.APIKeyID != "" {
+		return nil, common_error.NewServiceError(common_error.Forbidden, "Impersonation requires a token from a login", nil)
+	}
+
+	serviceToken, err := authService.TokenService.CreateServiceToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := authService.UserServiceClient.GetPrivateUserByIdentifier(userServiceContext(ctx, serviceToken, actor.Tenant), &pb.IdentifierRequest{
+		UserIdentifier: impersonateModel.Identifier,
+	})
+	if err != nil {
+		if code := serviceErrorCode(status.Code(err)); code == common_error.ServiceUnavailable {
+			return nil, common_error.NewServiceError(code, "User service unavailable", err)
+		}
+		return nil, common_error.NewServiceError(common_error.NotFound, "User not found", err)
+	}
+	auditEvent.SubjectID = user.GetId()
+
+	if user.GetId() == actor.UserID {
+		return nil, common_error.NewServiceError(common_error.BadRequest, "Cannot impersonate yourself", nil)
+	}
+
+	// The password hash never leaves this service
+	target := &pb.UserResponse{
+		Id:        user.GetId(),
+		Username:  user.GetUsername(),
+		Email:     user.GetEmail(),
+		CreatedAt: user.GetCreatedAt(),
+		UpdatedAt: user.GetUpdatedAt(),
+	}
+
+	return authService.TokenService.CreateImpersonationToken(ctx, &token.Subject{User: target, Tenant: actor.Tenant}, actor)
+}
+
+// comparePassword checks the password against the user's hash in a span of its own, as hashing takes a
+// large share of a login.
+func (authService *AuthService) comparePassword(ctx context.Context, hash string, password string) error {
+	_, span := tracing.Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+	return authService.Crypto.CompareHashAndPassword(hash, password)
+}
+
+// record completes the audit event with the outcome of the action and records it. Failing to record the
+// event is logged but does not fail the action.
+func (authService *AuthService) record(ctx context.Context, auditEvent *audit.Event, err error) {
+	auditEvent.Outcome = audit.Success
+	if err != nil {
+		auditEvent.Outcome = audit.Failure
+		auditEvent.Reason = err.Error()
+	}
+	if recordErr := authService.Audit.Record(ctx, auditEvent); recordErr != nil {
+		slog.ErrorContext(ctx, "Failed to record audit event", "type", auditEvent.Type, "error", recordErr)
+	}
+}
+
+// publish hands a domain event to the publisher. The action it describes already happened, so a failure
+// to publish is logged rather than returned.
+func (authService *AuthService) publish(ctx context.Context, domainEvent *event.Event) {
+	if err := authService.Events.Publish(ctx, domainEvent); err != nil {
+		slog.ErrorContext(ctx, "Failed to publish domain event", "type", domainEvent.Type, "user_id", domainEvent.UserID, "error", err)
+	}
+}
+
+// startSession creates a new session for the user and issues its first token pair for the tenant under
+// the given policy, limited to the requested scopes.
+func (authService *AuthService) startSession(ctx context.Context, user *pb.UserResponse, tenantID string, policy token.Policy, scopes []string) (*public_model.TokenModel, error) {
+	userSession, err := authService.SessionService.Create(ctx, tenantID, user.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	tokenModel, err := authService.TokenService.CreateTokenPair(ctx, &token.Subject{
+		Session: userSession,
+		User:    user,
+		Scopes:  scopes,
+		Tenant:  tenantID,
+	}, policy)
 	if err != nil {
 		return nil, err
 	}
@@ -112,5 +386,57 @@ func (authService *AuthService) Login(ctx context.Context, loginModel *public_mo
 	return tokenModel, nil
 }
 
+// userServiceContext authenticates calls to the user service with the service token and names the
+// tenant whose users they act on.
+func userServiceContext(ctx context.Context, token string, tenantID string) context.Context {
+	md := metadata.Pairs("Authorization", "Bearer "+token)
+	if tenantID != "" {
+		md.Set(tenant.DefaultHeader, tenantID)
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// accountRefusal returns the service error for a login the user service refused because of the state of
+// the account, which it names in the ErrorInfo reason of the status, or nil for any other failure.
+func accountRefusal(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	for _, detail := range st.Details() {
+		errorInfo, ok := detail.(*errdetails.ErrorInfo)
+		if !ok {
+			continue
+		}
+		switch errorInfo.GetReason() {
+		case "account_locked":
+			return common_error.NewServiceError(common_error.Forbidden, "Account is locked", ErrAccountLocked)
+		case "mfa_required":
+			return common_error.NewServiceError(common_error.Unauthorized, "Multi-factor authentication is required", ErrMFARequired)
+		}
+	}
+	return nil
+}
+
+// serviceErrorCode maps a gRPC status code returned by the user service to a common service error code.
+func serviceErrorCode(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument:
+		return common_error.BadRequest
+	case codes.NotFound:
+		return common_error.NotFound
+	case codes.AlreadyExists:
+		return common_error.Conflict
+	case codes.Unauthenticated:
+		return common_error.Unauthorized
+	case codes.PermissionDenied:
+		return common_error.Forbidden
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return common_error.ServiceUnavailable
+	default:
+		return common_error.InternalServerError
+	}
+}
+
 // Ensure AuthService implements IAuthService.
 var _ IAuthService = (*AuthService)(nil)
@@ -6,8 +6,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/audit"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/auth"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/event"
 	internal_jwt "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/jwt"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
 	internal_time "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/time"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
 	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
@@ -18,7 +22,15 @@ import (
 	"github.com/golang-jwt/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	otel_codes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -33,21 +45,84 @@ func (m *MockTokenService) CreateToken(ctx context.Context, userID string, durat
 	return args.String(0), args.Error(1)
 }
 
+// CreateServiceToken mock
+func (m *MockTokenService) CreateServiceToken(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+// CreateImpersonationToken mock
+func (m *MockTokenService) CreateImpersonationToken(ctx context.Context, subject *token.Subject, actor *public_model.CustomClaims) (*public_model.AccessTokenModel, error) {
+	args := m.Called(ctx, subject, actor)
+	return args.Get(0).(*public_model.AccessTokenModel), args.Error(1)
+}
+
+// CreateAPIKeyToken mock
+func (m *MockTokenService) CreateAPIKeyToken(ctx context.Context, grant *token.APIKeyGrant) (*public_model.AccessTokenModel, error) {
+	args := m.Called(ctx, grant)
+	return args.Get(0).(*public_model.AccessTokenModel), args.Error(1)
+}
+
+// Policy mock
+func (m *MockTokenService) Policy(clientID string) (token.Policy, error) {
+	args := m.Called(clientID)
+	return args.Get(0).(token.Policy), args.Error(1)
+}
+
 // CreateTokenPair mock
-func (m *MockTokenService) CreateTokenPair(ctx context.Context, userID string) (*public_model.TokenModel, error) {
-	args := m.Called(ctx, userID)
+func (m *MockTokenService) CreateTokenPair(ctx context.Context, subject *token.Subject, policy token.Policy) (*public_model.TokenModel, error) {
+	args := m.Called(ctx, subject, policy)
 	return args.Get(0).(*public_model.TokenModel), args.Error(1)
 }
 
-// RefreshToken mock
-func (m *MockTokenService) RefreshToken(ctx context.Context, refreshToken string) (*public_model.TokenModel, error) {
-	args := m.Called(ctx, refreshToken)
-	return args.Get(0).(*public_model.TokenModel), args.Error(1)
+// ParseToken mock
+func (m *MockTokenService) ParseToken(ctx context.Context, tokenString string) (*public_model.CustomClaims, error) {
+	args := m.Called(ctx, tokenString)
+	return args.Get(0).(*public_model.CustomClaims), args.Error(1)
 }
 
 // Ensure that the mock implements the interface
 var _ token.ITokenService = (*MockTokenService)(nil)
 
+// forSession matches a token subject bound to the given session
+func forSession(userSession *session.Session) interface{} {
+	return mock.MatchedBy(func(subject *token.Subject) bool {
+		return subject.Session == userSession
+	})
+}
+
+type MockAuditRecorder struct {
+	mock.Mock
+}
+
+func (m *MockAuditRecorder) Record(ctx context.Context, event *audit.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+// newAuditRecorder returns an audit recorder that accepts every event.
+func newAuditRecorder() *MockAuditRecorder {
+	auditRecorder := new(MockAuditRecorder)
+	auditRecorder.On("Record", mock.Anything, mock.Anything).Return(nil)
+	return auditRecorder
+}
+
+type MockPublisher struct {
+	mock.Mock
+}
+
+func (m *MockPublisher) Publish(ctx context.Context, domainEvent *event.Event) error {
+	args := m.Called(ctx, domainEvent)
+	return args.Error(0)
+}
+
+// newPublisher returns a publisher that accepts every event.
+func newPublisher() *MockPublisher {
+	publisher := new(MockPublisher)
+	publisher.On("Publish", mock.Anything, mock.Anything).Return(nil)
+	return publisher
+}
+
 type MockCrypto struct {
 	mock.Mock
 }
@@ -110,11 +185,43 @@ func (m *MockAuthService) CreateToken(ctx context.Context, userID string, expira
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockAuthService) CreateTokenPair(ctx context.Context, userID string) (*public_model.TokenModel, error) {
-	args := m.Called(ctx, userID)
+func (m *MockAuthService) CreateTokenPair(ctx context.Context, subject *token.Subject, policy token.Policy) (*public_model.TokenModel, error) {
+	args := m.Called(ctx, subject, policy)
 	return args.Get(0).(*public_model.TokenModel), args.Error(1)
 }
 
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) Create(ctx context.Context, tenantID string, userID string) (*session.Session, error) {
+	args := m.Called(ctx, tenantID, userID)
+	return args.Get(0).(*session.Session), args.Error(1)
+}
+
+func (m *MockSessionService) Refresh(ctx context.Context, tenantID string, userID string, sessionID string, refreshTokenID string) (*session.Session, error) {
+	args := m.Called(ctx, tenantID, userID, sessionID, refreshTokenID)
+	return args.Get(0).(*session.Session), args.Error(1)
+}
+
+func (m *MockSessionService) List(ctx context.Context, tenantID string, userID string) ([]*session.Session, error) {
+	args := m.Called(ctx, tenantID, userID)
+	return args.Get(0).([]*session.Session), args.Error(1)
+}
+
+func (m *MockSessionService) Revoke(ctx context.Context, tenantID string, userID string, sessionID string) error {
+	args := m.Called(ctx, tenantID, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) RevokeAll(ctx context.Context, tenantID string, userID string) error {
+	args := m.Called(ctx, tenantID, userID)
+	return args.Error(0)
+}
+
+// Ensure that the mock implements the interface
+var _ session.ISessionService = (*MockSessionService)(nil)
+
 type MockJWTHandler struct {
 	mock.Mock
 }
@@ -151,19 +258,25 @@ func TestRegister_Success(t *testing.T) {
 	mockTokenService := new(MockTokenService)
 	mockCrypto := new(MockCrypto)
 	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
 
 	authService := auth.NewAuthService(
 		mockTokenService,
 		mockCrypto,
 		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
 	)
 
 	// Setup expectations
 	mockUserServiceClient.On("CreateUser", mock.Anything, mock.Anything).Return(&pb.PublicUserResponse{
 		Id: "test",
 	}, nil)
-	mockTokenService.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return("mocked_token", nil)
-	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything).Return(&public_model.TokenModel{AccessToken: "mocked_access_token", RefreshToken: "mocked_refresh_token"}, nil)
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockSessionService.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(&session.Session{ID: "session"}, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything, mock.Anything).Return(&public_model.TokenModel{AccessToken: "mocked_access_token", RefreshToken: "mocked_refresh_token"}, nil)
 
 	// Call method
 	registerModel := &public_model.RegisterModel{Email: "test@test.com", Username: "test", Password: "password"}
@@ -182,16 +295,22 @@ func TestRegister_CreateUser_Failure_Unknown_Error(t *testing.T) {
 	mockTokenService := new(MockTokenService)
 	mockCrypto := new(MockCrypto)
 	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
 
 	authService := auth.NewAuthService(
 		mockTokenService,
 		mockCrypto,
 		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
 	)
 
 	mockUserServiceClient.On("CreateUser", mock.Anything, mock.Anything).Return((*pb.PublicUserResponse)(nil), errors.New("create user error"))
-	mockTokenService.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return("mocked_token", nil)
-	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything).Return(&public_model.TokenModel{AccessToken: "mocked_access_token", RefreshToken: "mocked_refresh_token"}, nil)
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockSessionService.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(&session.Session{ID: "session"}, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything, mock.Anything).Return(&public_model.TokenModel{AccessToken: "mocked_access_token", RefreshToken: "mocked_refresh_token"}, nil)
 
 	registerModel := &public_model.RegisterModel{Email: "test@test.com", Username: "test", Password: "password"}
 	result, err := authService.Register(context.Background(), registerModel)
@@ -208,16 +327,22 @@ func TestRegister_CreateUser_Failure_Known_Error(t *testing.T) {
 	mockTokenService := new(MockTokenService)
 	mockCrypto := new(MockCrypto)
 	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
 
 	authService := auth.NewAuthService(
 		mockTokenService,
 		mockCrypto,
 		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
 	)
 
 	mockUserServiceClient.On("CreateUser", mock.Anything, mock.Anything).Return((*pb.PublicUserResponse)(nil), status.Errorf(400, "create user error"))
-	mockTokenService.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return("mocked_token", nil)
-	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything).Return(&public_model.TokenModel{AccessToken: "mocked_access_token", RefreshToken: "mocked_refresh_token"}, nil)
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockSessionService.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(&session.Session{ID: "session"}, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything, mock.Anything).Return(&public_model.TokenModel{AccessToken: "mocked_access_token", RefreshToken: "mocked_refresh_token"}, nil)
 
 	registerModel := &public_model.RegisterModel{Email: "test@test.com", Username: "test", Password: "password"}
 	result, err := authService.Register(context.Background(), registerModel)
@@ -229,19 +354,57 @@ func TestRegister_CreateUser_Failure_Known_Error(t *testing.T) {
 	mockUserServiceClient.AssertExpectations(t)
 }
 
+func TestRegister_CreateUser_Failure_Conflict(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockCrypto := new(MockCrypto)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
+
+	authService := auth.NewAuthService(
+		mockTokenService,
+		mockCrypto,
+		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
+	)
+
+	mockUserServiceClient.On("CreateUser", mock.Anything, mock.Anything).Return((*pb.PublicUserResponse)(nil), status.Error(codes.AlreadyExists, "user already exists"))
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+
+	registerModel := &public_model.RegisterModel{Email: "test@test.com", Username: "test", Password: "password"}
+	result, err := authService.Register(context.Background(), registerModel)
+
+	serverError, ok := err.(*common_error.ServiceError)
+
+	assert.True(t, ok)
+	assert.Equal(t, common_error.Conflict, serverError.Code)
+	assert.Equal(t, "user already exists", serverError.Message)
+	assert.Nil(t, result)
+
+	mockUserServiceClient.AssertExpectations(t)
+}
+
 func TestRegister_CreateToken_Failure(t *testing.T) {
 	// Setup mocks
 	mockTokenService := new(MockTokenService)
 	mockCrypto := new(MockCrypto)
 	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
 
 	authService := auth.NewAuthService(
 		mockTokenService,
 		mockCrypto,
 		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
 	)
 
-	mockTokenService.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return("", errors.New("create token error"))
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("", errors.New("create token error"))
 
 	registerModel := &public_model.RegisterModel{Email: "test@mail", Username: "test", Password: "password"}
 	result, err := authService.Register(context.Background(), registerModel)
@@ -258,19 +421,25 @@ func TestRegister_CreateTokenPair_Failure(t *testing.T) {
 	mockTokenService := new(MockTokenService)
 	mockCrypto := new(MockCrypto)
 	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
 
 	authService := auth.NewAuthService(
 		mockTokenService,
 		mockCrypto,
 		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
 	)
 
 	// Setup expectations
 	mockUserServiceClient.On("CreateUser", mock.Anything, mock.Anything).Return(&pb.PublicUserResponse{
 		Id: "test",
 	}, nil)
-	mockTokenService.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return("mocked_token", nil)
-	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything).Return((*public_model.TokenModel)(nil), errors.New("create token pair error"))
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockSessionService.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(&session.Session{ID: "session"}, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything, mock.Anything).Return((*public_model.TokenModel)(nil), errors.New("create token pair error"))
 
 	// Call method
 	registerModel := &public_model.RegisterModel{Email: "test@test.com", Username: "test", Password: "password"}
@@ -285,26 +454,59 @@ func TestRegister_CreateTokenPair_Failure(t *testing.T) {
 	mockUserServiceClient.AssertExpectations(t)
 }
 
+func TestLogin_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(trace.NewNoopTracerProvider()) })
+
+	mockTokenService := new(MockTokenService)
+	mockCrypto := new(MockCrypto)
+	mockUserServiceClient := new(MockUserServiceClient)
+	authService := auth.NewAuthService(mockTokenService, mockCrypto, mockUserServiceClient, new(MockSessionService), newAuditRecorder(), newPublisher())
+
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything).Return(&pb.UserResponse{Id: "test", Hash: "hashed_password"}, nil)
+	mockCrypto.On("CompareHashAndPassword", "hashed_password", "wrong").Return(errors.New("mismatch"))
+
+	_, err := authService.Login(context.Background(), &public_model.LoginModel{Email: "test@test.com", Password: "wrong"})
+
+	assert.Error(t, err)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	// Hashing shows as a child of the login, which failed
+	assert.Equal(t, "bcrypt.CompareHashAndPassword", spans[0].Name())
+	assert.Equal(t, "AuthService.Login", spans[1].Name())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, otel_codes.Error, spans[1].Status().Code)
+}
+
 func TestLogin_Success(t *testing.T) {
 	// Setup mocks
 	mockTokenService := new(MockTokenService)
 	mockCrypto := new(MockCrypto)
 	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
 
 	authService := auth.NewAuthService(
 		mockTokenService,
 		mockCrypto,
 		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
 	)
 
 	// Setup expectations
-	mockTokenService.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return("mocked_token", nil)
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
 	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything).Return(&pb.UserResponse{
 		Id:   "test",
 		Hash: "hashed_password",
 	}, nil)
 	mockCrypto.On("CompareHashAndPassword", "hashed_password", "password").Return(nil)
-	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything).Return(&public_model.TokenModel{
+	mockSessionService.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(&session.Session{ID: "session"}, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything, mock.Anything).Return(&public_model.TokenModel{
 		AccessToken:  "mocked_access_token",
 		RefreshToken: "mocked_refresh_token",
 	}, nil)
@@ -323,19 +525,62 @@ func TestLogin_Success(t *testing.T) {
 	mockUserServiceClient.AssertExpectations(t)
 }
 
+func TestLogin_Identifier(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockCrypto := new(MockCrypto)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
+
+	authService := auth.NewAuthService(
+		mockTokenService,
+		mockCrypto,
+		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
+	)
+
+	// Setup expectations
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, &pb.IdentifierRequest{UserIdentifier: "test_user"}).Return(&pb.UserResponse{
+		Id:   "test",
+		Hash: "hashed_password",
+	}, nil)
+	mockCrypto.On("CompareHashAndPassword", "hashed_password", "password").Return(nil)
+	mockSessionService.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(&session.Session{ID: "session"}, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything, mock.Anything).Return(&public_model.TokenModel{}, nil)
+
+	// Call method
+	loginModel := &public_model.LoginModel{Identifier: "test_user", Email: "test@test.com", Password: "password"}
+	_, err := authService.Login(context.Background(), loginModel)
+
+	// Assertions
+	assert.NoError(t, err)
+
+	// Verify that expected methods were called
+	mockUserServiceClient.AssertExpectations(t)
+}
+
 func TestLogin_CreateToken_Failure(t *testing.T) {
 	// Setup mocks
 	mockTokenService := new(MockTokenService)
 	mockCrypto := new(MockCrypto)
 	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
 
 	authService := auth.NewAuthService(
 		mockTokenService,
 		mockCrypto,
 		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
 	)
 
-	mockTokenService.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return("", errors.New("create token error"))
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("", errors.New("create token error"))
 
 	loginModel := &public_model.LoginModel{Email: "test@mail", Password: "password"}
 	result, err := authService.Login(context.Background(), loginModel)
@@ -352,15 +597,20 @@ func TestLogin_GetPrivateUserByIdentifier_Failure(t *testing.T) {
 	mockTokenService := new(MockTokenService)
 	mockCrypto := new(MockCrypto)
 	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
 
 	authService := auth.NewAuthService(
 		mockTokenService,
 		mockCrypto,
 		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
 	)
 
 	// Setup expectations
-	mockTokenService.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return("mocked_token", nil)
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
 	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything).Return((*pb.UserResponse)(nil), errors.New("get private user error"))
 
 	// Call method
@@ -372,7 +622,7 @@ func TestLogin_GetPrivateUserByIdentifier_Failure(t *testing.T) {
 	serverError, ok := err.(*common_error.ServiceError)
 
 	assert.True(t, ok)
-	assert.Equal(t, common_error.Unauthorized, serverError.Code)
+	assert.Equal(t, common_error.InvalidCredentials, serverError.Code)
 	assert.Equal(t, "Invalid credentials", serverError.Message)
 	assert.Nil(t, result)
 
@@ -381,21 +631,92 @@ func TestLogin_GetPrivateUserByIdentifier_Failure(t *testing.T) {
 	mockUserServiceClient.AssertExpectations(t)
 }
 
+func TestLogin_GetPrivateUserByIdentifier_Unavailable(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockCrypto := new(MockCrypto)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
+
+	authService := auth.NewAuthService(
+		mockTokenService,
+		mockCrypto,
+		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
+	)
+
+	// Setup expectations
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything).Return((*pb.UserResponse)(nil), status.Error(codes.Unavailable, "connection refused"))
+
+	// Call method
+	loginModel := &public_model.LoginModel{Email: "test@mail.com", Password: "password"}
+	result, err := authService.Login(context.Background(), loginModel)
+
+	// Assertions
+	serverError, ok := err.(*common_error.ServiceError)
+
+	assert.True(t, ok)
+	assert.Equal(t, common_error.ServiceUnavailable, serverError.Code)
+	assert.Nil(t, result)
+
+	// Verify that expected methods were called
+	mockTokenService.AssertExpectations(t)
+	mockUserServiceClient.AssertExpectations(t)
+}
+
+func TestLogin_AccountRefused(t *testing.T) {
+	for reason, cause := range map[string]error{
+		"account_locked": auth.ErrAccountLocked,
+		"mfa_required":   auth.ErrMFARequired,
+	} {
+		// Setup mocks
+		mockTokenService := new(MockTokenService)
+		mockUserServiceClient := new(MockUserServiceClient)
+
+		authService := auth.NewAuthService(mockTokenService, new(MockCrypto), mockUserServiceClient, new(MockSessionService), newAuditRecorder(), newPublisher())
+
+		// Setup expectations
+		st, err := status.New(codes.PermissionDenied, "refused").WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: "user.bitbridge"})
+		assert.NoError(t, err)
+		mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+		mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+		mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything).Return((*pb.UserResponse)(nil), st.Err())
+
+		// Call method
+		result, err := authService.Login(context.Background(), &public_model.LoginModel{Email: "test@mail.com", Password: "password"})
+
+		// Assertions
+		serverError, ok := err.(*common_error.ServiceError)
+		assert.True(t, ok, reason)
+		assert.ErrorIs(t, serverError.Cause, cause, reason)
+		assert.Nil(t, result)
+	}
+}
+
 func TestLogin_CompareHashAndPassword_Failure(t *testing.T) {
 	// Setup mocks
 	mockTokenService := new(MockTokenService)
 	mockCrypto := new(MockCrypto)
 	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
 	mockAuthService := new(MockAuthService)
 
 	authService := auth.NewAuthService(
 		mockTokenService,
 		mockCrypto,
 		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
 	)
 
 	// Setup expectations
-	mockTokenService.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return("mocked_token", nil)
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
 	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything).Return(&pb.UserResponse{
 		Id:   "test",
 		Hash: "hashed_password",
@@ -407,9 +728,12 @@ func TestLogin_CompareHashAndPassword_Failure(t *testing.T) {
 	result, err := authService.Login(context.Background(), loginModel)
 
 	// Assertions
-	assert.Error(t, err)
+	serverError, ok := err.(*common_error.ServiceError)
+
+	assert.True(t, ok)
+	assert.Equal(t, common_error.InvalidCredentials, serverError.Code)
+	assert.Equal(t, "Invalid credentials", serverError.Message)
 	assert.Nil(t, result)
-	assert.Equal(t, "compare hash and password error", err.Error())
 
 	// Verify that expected methods were called
 	mockTokenService.AssertExpectations(t)
@@ -422,22 +746,28 @@ func TestLogin_CreateTokenPair_Failure(t *testing.T) {
 	mockTokenService := new(MockTokenService)
 	mockCrypto := new(MockCrypto)
 	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
 	mockAuthService := new(MockAuthService)
 
 	authService := auth.NewAuthService(
 		mockTokenService,
 		mockCrypto,
 		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
 	)
 
 	// Setup expectations
-	mockTokenService.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return("mocked_token", nil)
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
 	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything).Return(&pb.UserResponse{
 		Id:   "test",
 		Hash: "hashed_password",
 	}, nil)
 	mockCrypto.On("CompareHashAndPassword", "hashed_password", "password").Return(nil)
-	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything).Return((*public_model.TokenModel)(nil), errors.New("create token pair error"))
+	mockSessionService.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(&session.Session{ID: "session"}, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything, mock.Anything).Return((*public_model.TokenModel)(nil), errors.New("create token pair error"))
 
 	// Call method
 	loginModel := &public_model.LoginModel{Email: "test@mail.com", Password: "password"}
@@ -454,27 +784,579 @@ func TestLogin_CreateTokenPair_Failure(t *testing.T) {
 	mockAuthService.AssertExpectations(t)
 }
 
-func TestRefreshToken_Success_(t *testing.T) {
-	mockJWTHandler := new(MockJWTHandler)
-	mockTimeSource := &MockTimeSource{}
-	svc := token.NewTokenService(mockTimeSource, mockJWTHandler)
+func TestLogin_CreatesSession(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockCrypto := new(MockCrypto)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
+
+	authService := auth.NewAuthService(
+		mockTokenService,
+		mockCrypto,
+		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
+	)
+
+	// Setup expectations
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything).Return(&pb.UserResponse{
+		Id:   "user",
+		Hash: "hashed_password",
+	}, nil)
+	mockCrypto.On("CompareHashAndPassword", "hashed_password", "password").Return(nil)
+	userSession := &session.Session{ID: "session", UserID: "user"}
+	mockSessionService.On("Create", mock.Anything, "", "user").Return(userSession, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, forSession(userSession), token.DefaultPolicies().Default).Return(&public_model.TokenModel{}, nil)
+
+	// Call method
+	loginModel := &public_model.LoginModel{Identifier: "test@test.com", Password: "password"}
+	_, err := authService.Login(context.Background(), loginModel)
+
+	// Assertions
+	assert.NoError(t, err)
+
+	// Verify that expected methods were called
+	mockSessionService.AssertExpectations(t)
+	mockTokenService.AssertExpectations(t)
+}
+
+func TestLogin_CreateSession_Failure(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockCrypto := new(MockCrypto)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
+
+	authService := auth.NewAuthService(
+		mockTokenService,
+		mockCrypto,
+		mockUserServiceClient,
+		mockSessionService,
+		newAuditRecorder(),
+		newPublisher(),
+	)
+
+	// Setup expectations
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything).Return(&pb.UserResponse{
+		Id:   "user",
+		Hash: "hashed_password",
+	}, nil)
+	mockCrypto.On("CompareHashAndPassword", "hashed_password", "password").Return(nil)
+	mockSessionService.On("Create", mock.Anything, "", "user").Return((*session.Session)(nil), errors.New("create session error"))
+
+	// Call method
+	loginModel := &public_model.LoginModel{Identifier: "test@test.com", Password: "password"}
+	result, err := authService.Login(context.Background(), loginModel)
+
+	// Assertions
+	assert.Nil(t, result)
+	assert.Equal(t, "create session error", err.Error())
+	mockTokenService.AssertNotCalled(t, "CreateTokenPair", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefresh_Success(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockSessionService := new(MockSessionService)
+
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), new(MockUserServiceClient), mockSessionService, newAuditRecorder(), newPublisher())
+
+	// Setup expectations
+	mockTokenService.On("ParseToken", mock.Anything, "refresh_token").Return(&public_model.CustomClaims{
+		UserID:         "user",
+		SessionID:      "session",
+		TokenType:      token.RefreshTokenType,
+		StandardClaims: jwt.StandardClaims{Id: "refresh"},
+	}, nil)
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	userSession := &session.Session{ID: "session", UserID: "user"}
+	mockSessionService.On("Refresh", mock.Anything, "", "user", "session", "refresh").Return(userSession, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, forSession(userSession), token.DefaultPolicies().Default).Return(&public_model.TokenModel{
+		AccessToken:  "new_access_token",
+		RefreshToken: "new_refresh_token",
+	}, nil)
+
+	// Call method
+	result, err := authService.Refresh(context.Background(), &public_model.TokenRefreshModel{Token: "refresh_token"})
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, "new_access_token", result.AccessToken)
+
+	// Verify that expected methods were called
+	mockTokenService.AssertExpectations(t)
+	mockSessionService.AssertExpectations(t)
+}
+
+func TestRefresh_AccessToken(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockSessionService := new(MockSessionService)
+
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), new(MockUserServiceClient), mockSessionService, newAuditRecorder(), newPublisher())
+
+	// Setup expectations
+	mockTokenService.On("ParseToken", mock.Anything, "access_token").Return(&public_model.CustomClaims{
+		UserID:    "user",
+		SessionID: "session",
+		TokenType: token.AccessTokenType,
+	}, nil)
+	// Even a refresh-typed token naming an actor is refused
+	mockTokenService.On("ParseToken", mock.Anything, "impersonation_token").Return(&public_model.CustomClaims{
+		UserID:    "user",
+		SessionID: "session",
+		TokenType: token.RefreshTokenType,
+		Actor:     &public_model.Actor{UserID: "admin"},
+	}, nil)
+
+	for _, tokenString := range []string{"access_token", "impersonation_token"} {
+		// Call method
+		result, err := authService.Refresh(context.Background(), &public_model.TokenRefreshModel{Token: tokenString})
+
+		// Assertions
+		serverError, ok := err.(*common_error.ServiceError)
+
+		assert.True(t, ok)
+		assert.Equal(t, common_error.TokenInvalid, serverError.Code)
+		assert.Nil(t, result)
+	}
+	mockSessionService.AssertNotCalled(t, "Refresh", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefresh_RevokedSession(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockSessionService := new(MockSessionService)
+
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), new(MockUserServiceClient), mockSessionService, newAuditRecorder(), newPublisher())
+
+	// Setup expectations
+	mockTokenService.On("ParseToken", mock.Anything, "refresh_token").Return(&public_model.CustomClaims{
+		UserID:    "user",
+		SessionID: "session",
+		TokenType: token.RefreshTokenType,
+	}, nil)
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockSessionService.On("Refresh", mock.Anything, "", "user", "session", "").Return((*session.Session)(nil), common_error.NewServiceError(common_error.SessionExpired, "Session has been revoked", nil))
+
+	// Call method
+	result, err := authService.Refresh(context.Background(), &public_model.TokenRefreshModel{Token: "refresh_token"})
+
+	// Assertions
+	serverError, ok := err.(*common_error.ServiceError)
+
+	assert.True(t, ok)
+	assert.Equal(t, common_error.SessionExpired, serverError.Code)
+	assert.Nil(t, result)
+	mockTokenService.AssertNotCalled(t, "CreateTokenPair", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLogin_UnknownClient(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockUserServiceClient := new(MockUserServiceClient)
+
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), mockUserServiceClient, new(MockSessionService), newAuditRecorder(), newPublisher())
+
+	// Setup expectations
+	mockTokenService.On("Policy", "unknown").Return(token.Policy{}, common_error.NewServiceError(common_error.BadRequest, "Unknown client", nil))
+
+	// Call method
+	result, err := authService.Login(context.Background(), &public_model.LoginModel{Identifier: "test", Password: "password", ClientID: "unknown"})
+
+	// Assertions
+	serverError, ok := err.(*common_error.ServiceError)
+
+	assert.True(t, ok)
+	assert.Equal(t, common_error.BadRequest, serverError.Code)
+	assert.Nil(t, result)
+	mockUserServiceClient.AssertNotCalled(t, "GetPrivateUserByIdentifier", mock.Anything, mock.Anything)
+}
+
+func TestRefresh_KeepsClientPolicy(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockSessionService := new(MockSessionService)
+
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), new(MockUserServiceClient), mockSessionService, newAuditRecorder(), newPublisher())
+
+	// Setup expectations
+	claims := &public_model.CustomClaims{UserID: "user", SessionID: "session", TokenType: token.RefreshTokenType}
+	claims.Audience = "mobile"
+	mobilePolicy := token.Policy{Audience: "mobile", AccessTTL: time.Minute, RefreshTTL: time.Hour, MaxSession: 24 * time.Hour}
+	mockTokenService.On("ParseToken", mock.Anything, "refresh_token").Return(claims, nil)
+	mockTokenService.On("Policy", "mobile").Return(mobilePolicy, nil)
+	userSession := &session.Session{ID: "session", UserID: "user"}
+	mockSessionService.On("Refresh", mock.Anything, "", "user", "session", "").Return(userSession, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, forSession(userSession), mobilePolicy).Return(&public_model.TokenModel{}, nil)
+
+	// Call method
+	_, err := authService.Refresh(context.Background(), &public_model.TokenRefreshModel{Token: "refresh_token"})
+
+	// Assertions
+	assert.NoError(t, err)
+	mockTokenService.AssertExpectations(t)
+}
+
+func TestLogin_RequestedScopes(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockCrypto := new(MockCrypto)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
+
+	authService := auth.NewAuthService(mockTokenService, mockCrypto, mockUserServiceClient, mockSessionService, newAuditRecorder(), newPublisher())
+
+	// Setup expectations
+	user := &pb.UserResponse{Id: "user", Hash: "hash"}
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything).Return(user, nil)
+	mockCrypto.On("CompareHashAndPassword", "hash", "password").Return(nil)
+	mockSessionService.On("Create", mock.Anything, "", "user").Return(&session.Session{ID: "session", UserID: "user"}, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.MatchedBy(func(subject *token.Subject) bool {
+		return subject.User == user && assert.ObjectsAreEqual([]string{"profile"}, subject.Scopes) && subject.Previous == nil
+	}), mock.Anything).Return(&public_model.TokenModel{}, nil)
+
+	// Call method
+	_, err := authService.Login(context.Background(), &public_model.LoginModel{Identifier: "test", Password: "password", Scope: "profile"})
+
+	// Assertions
+	assert.NoError(t, err)
+	mockTokenService.AssertExpectations(t)
+}
+
+func TestLogin_Tenant(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockCrypto := new(MockCrypto)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
+
+	authService := auth.NewAuthService(mockTokenService, mockCrypto, mockUserServiceClient, mockSessionService, newAuditRecorder(), newPublisher())
+
+	// Setup expectations
+	user := &pb.UserResponse{Id: "user", Hash: "hash"}
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.MatchedBy(func(ctx context.Context) bool {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		return assert.ObjectsAreEqual([]string{"acme"}, md.Get(tenant.DefaultHeader))
+	}), mock.Anything).Return(user, nil)
+	mockCrypto.On("CompareHashAndPassword", "hash", "password").Return(nil)
+	mockSessionService.On("Create", mock.Anything, "acme", "user").Return(&session.Session{ID: "session", Tenant: "acme", UserID: "user"}, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.MatchedBy(func(subject *token.Subject) bool {
+		return subject.Tenant == "acme"
+	}), mock.Anything).Return(&public_model.TokenModel{}, nil)
+
+	// Call method
+	ctx := tenant.WithTenant(context.Background(), "acme")
+	_, err := authService.Login(ctx, &public_model.LoginModel{Identifier: "test", Password: "password"})
+
+	// Assertions
+	assert.NoError(t, err)
+	mockTokenService.AssertExpectations(t)
+	mockUserServiceClient.AssertExpectations(t)
+}
+
+func TestLogin_ConflictingTenant(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockUserServiceClient := new(MockUserServiceClient)
+
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), mockUserServiceClient, new(MockSessionService), newAuditRecorder(), newPublisher())
+
+	// Call method
+	ctx := tenant.WithTenant(context.Background(), "acme")
+	result, err := authService.Login(ctx, &public_model.LoginModel{Identifier: "test", Password: "password", TenantID: "globex"})
+
+	// Assertions
+	serverError, ok := err.(*common_error.ServiceError)
+
+	assert.True(t, ok)
+	assert.Equal(t, common_error.BadRequest, serverError.Code)
+	assert.Nil(t, result)
+	mockUserServiceClient.AssertNotCalled(t, "GetPrivateUserByIdentifier", mock.Anything, mock.Anything)
+}
+
+func TestRefresh_OtherTenant(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockSessionService := new(MockSessionService)
+
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), new(MockUserServiceClient), mockSessionService, newAuditRecorder(), newPublisher())
+
+	// Setup expectations
+	mockTokenService.On("ParseToken", mock.Anything, "refresh_token").Return(&public_model.CustomClaims{
+		UserID:    "user",
+		SessionID: "session",
+		TokenType: token.RefreshTokenType,
+		Tenant:    "acme",
+	}, nil)
+
+	// Call method
+	result, err := authService.Refresh(context.Background(), &public_model.TokenRefreshModel{Token: "refresh_token", TenantID: "globex"})
+
+	// Assertions
+	serverError, ok := err.(*common_error.ServiceError)
+
+	assert.True(t, ok)
+	assert.Equal(t, common_error.TokenInvalid, serverError.Code)
+	assert.Nil(t, result)
+	mockSessionService.AssertNotCalled(t, "Refresh", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImpersonate_Success(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockAuditRecorder := new(MockAuditRecorder)
 
-	// Mock Parse method since RefreshToken will call it
-	mockJWTHandler.On("Parse", "someValidRefreshToken", mock.Anything).Return(&jwt.Token{Valid: true}, nil)
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), mockUserServiceClient, new(MockSessionService), mockAuditRecorder, newPublisher())
+	actor := &public_model.CustomClaims{UserID: "admin", Tenant: "acme"}
+	accessToken := &public_model.AccessTokenModel{AccessToken: "access_token", TokenType: "Bearer", ExpiresIn: 600}
 
-	// Mock Generate method twice, because RefreshToken will call CreateTokenPair -> CreateToken twice
-	mockJWTHandler.On("Generate", mock.Anything).Return("newAccessToken", nil).Once()
-	mockJWTHandler.On("Generate", mock.Anything).Return("newRefreshToken", nil).Once()
+	// Setup expectations
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("service_token", nil)
+	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, &pb.IdentifierRequest{UserIdentifier: "target"}, mock.Anything).Return(&pb.UserResponse{Id: "user", Username: "target", Hash: "hashed"}, nil)
+	mockTokenService.On("CreateImpersonationToken", mock.Anything, &token.Subject{User: &pb.UserResponse{Id: "user", Username: "target"}, Tenant: "acme"}, actor).Return(accessToken, nil)
+	mockAuditRecorder.On("Record", mock.Anything, mock.MatchedBy(func(event *audit.Event) bool {
+		return event.Type == audit.Impersonation && event.Outcome == audit.Success &&
+			event.ActorID == "admin" && event.SubjectID == "user" && event.Tenant == "acme" && event.Reason == "support ticket"
+	})).Return(nil)
 
-	tokenModel, err := svc.RefreshToken(context.TODO(), "someValidRefreshToken")
+	// Call method
+	result, err := authService.Impersonate(context.Background(), actor, &public_model.ImpersonateModel{Identifier: "target", Reason: "support ticket"})
 
+	// Assertions
 	assert.NoError(t, err)
+	assert.Equal(t, accessToken, result)
+	mockAuditRecorder.AssertNumberOfCalls(t, "Record", 1)
+}
+
+func TestImpersonate_Self(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockAuditRecorder := new(MockAuditRecorder)
+
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), mockUserServiceClient, new(MockSessionService), mockAuditRecorder, newPublisher())
+
+	// Setup expectations
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("service_token", nil)
+	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything, mock.Anything).Return(&pb.UserResponse{Id: "admin"}, nil)
+	mockAuditRecorder.On("Record", mock.Anything, mock.MatchedBy(func(event *audit.Event) bool {
+		return event.Outcome == audit.Failure && event.SubjectID == "admin"
+	})).Return(nil)
+
+	// Call method
+	result, err := authService.Impersonate(context.Background(), &public_model.CustomClaims{UserID: "admin"}, &public_model.ImpersonateModel{Identifier: "admin", Reason: "testing"})
+
+	// Assertions
+	serverError, ok := err.(*common_error.ServiceError)
+
+	assert.True(t, ok)
+	assert.Equal(t, common_error.BadRequest, serverError.Code)
+	assert.Nil(t, result)
+	mockAuditRecorder.AssertNumberOfCalls(t, "Record", 1)
+	mockTokenService.AssertNotCalled(t, "CreateImpersonationToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImpersonate_ChainedActor(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockAuditRecorder := new(MockAuditRecorder)
+
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), new(MockUserServiceClient), new(MockSessionService), mockAuditRecorder, newPublisher())
+
+	// Setup expectations
+	mockAuditRecorder.On("Record", mock.Anything, mock.Anything).Return(nil)
 
-	expectedTokenModel := &public_model.TokenModel{
-		AccessToken:  "newAccessToken",
-		RefreshToken: "newRefreshToken",
+	for _, actor := range []*public_model.CustomClaims{
+		{UserID: "user", Actor: &public_model.Actor{UserID: "admin"}},
+		{UserID: "admin", APIKeyID: "key"},
+	} {
+		// Call method
+		result, err := authService.Impersonate(context.Background(), actor, &public_model.ImpersonateModel{Identifier: "target", Reason: "testing"})
+
+		// Assertions
+		serverError, ok := err.(*common_error.ServiceError)
+
+		assert.True(t, ok)
+		assert.Equal(t, common_error.Forbidden, serverError.Code)
+		assert.Nil(t, result)
 	}
+	mockTokenService.AssertNotCalled(t, "CreateServiceToken", mock.Anything)
+}
+
+func TestImpersonate_AuditFailure(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockAuditRecorder := new(MockAuditRecorder)
 
-	assert.Equal(t, expectedTokenModel, tokenModel)
-	mockJWTHandler.AssertExpectations(t)
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), mockUserServiceClient, new(MockSessionService), mockAuditRecorder, newPublisher())
+
+	// Setup expectations
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("service_token", nil)
+	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything, mock.Anything).Return(&pb.UserResponse{Id: "user"}, nil)
+	mockTokenService.On("CreateImpersonationToken", mock.Anything, mock.Anything, mock.Anything).Return(&public_model.AccessTokenModel{AccessToken: "access_token"}, nil)
+	mockAuditRecorder.On("Record", mock.Anything, mock.Anything).Return(errors.New("sink down"))
+
+	// Call method
+	result, err := authService.Impersonate(context.Background(), &public_model.CustomClaims{UserID: "admin"}, &public_model.ImpersonateModel{Identifier: "target", Reason: "testing"})
+
+	// Assertions
+	serverError, ok := err.(*common_error.ServiceError)
+
+	assert.True(t, ok)
+	assert.Equal(t, common_error.ServiceUnavailable, serverError.Code)
+	assert.Nil(t, result)
+}
+
+func TestLogin_RecordsEvents(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockCrypto := new(MockCrypto)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
+	mockAuditRecorder := new(MockAuditRecorder)
+
+	authService := auth.NewAuthService(mockTokenService, mockCrypto, mockUserServiceClient, mockSessionService, mockAuditRecorder, newPublisher())
+
+	// Setup expectations
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything).Return(&pb.UserResponse{Id: "test", Hash: "hashed_password"}, nil)
+	mockCrypto.On("CompareHashAndPassword", "hashed_password", "password").Return(nil)
+	mockCrypto.On("CompareHashAndPassword", "hashed_password", "wrong").Return(errors.New("mismatch"))
+	mockSessionService.On("Create", mock.Anything, "", "test").Return(&session.Session{ID: "session"}, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything, mock.Anything).Return(&public_model.TokenModel{AccessToken: "access_token"}, nil)
+	mockAuditRecorder.On("Record", mock.Anything, mock.MatchedBy(func(event *audit.Event) bool {
+		return event.Type == audit.LoginSuccess && event.Outcome == audit.Success &&
+			event.ActorID == "test" && event.Details["identifier"] == "test@test.com"
+	})).Return(nil).Once()
+	mockAuditRecorder.On("Record", mock.Anything, mock.MatchedBy(func(event *audit.Event) bool {
+		return event.Type == audit.LoginFailure && event.Outcome == audit.Failure &&
+			event.ActorID == "test" && event.Reason == "Invalid credentials"
+	})).Return(errors.New("sink down")).Once()
+
+	// Call method
+	_, err := authService.Login(context.Background(), &public_model.LoginModel{Email: "test@test.com", Password: "password"})
+	assert.NoError(t, err)
+
+	// A failure to record the event does not change the outcome of the login
+	_, err = authService.Login(context.Background(), &public_model.LoginModel{Email: "test@test.com", Password: "wrong"})
+	serverError, ok := err.(*common_error.ServiceError)
+
+	// Assertions
+	assert.True(t, ok)
+	assert.Equal(t, common_error.InvalidCredentials, serverError.Code)
+	mockAuditRecorder.AssertExpectations(t)
+}
+
+func TestRefresh_RecordsEvent(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockSessionService := new(MockSessionService)
+	mockAuditRecorder := new(MockAuditRecorder)
+
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), new(MockUserServiceClient), mockSessionService, mockAuditRecorder, newPublisher())
+
+	// Setup expectations
+	mockTokenService.On("ParseToken", mock.Anything, "refresh_token").Return(&public_model.CustomClaims{
+		UserID:    "user",
+		SessionID: "session",
+		TokenType: token.RefreshTokenType,
+	}, nil)
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockSessionService.On("Refresh", mock.Anything, "", "user", "session", "").Return((*session.Session)(nil), common_error.NewServiceError(common_error.SessionExpired, "Session expired", nil))
+	mockAuditRecorder.On("Record", mock.Anything, mock.MatchedBy(func(event *audit.Event) bool {
+		return event.Type == audit.Refresh && event.Outcome == audit.Failure &&
+			event.ActorID == "user" && event.Details["session_id"] == "session" && event.Reason == "Session expired"
+	})).Return(nil)
+
+	// Call method
+	result, err := authService.Refresh(context.Background(), &public_model.TokenRefreshModel{Token: "refresh_token"})
+
+	// Assertions
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockAuditRecorder.AssertExpectations(t)
+}
+
+func TestRegister_PublishesEvent(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
+	mockPublisher := new(MockPublisher)
+
+	authService := auth.NewAuthService(mockTokenService, new(MockCrypto), mockUserServiceClient, mockSessionService, newAuditRecorder(), mockPublisher)
+
+	// Setup expectations
+	mockUserServiceClient.On("CreateUser", mock.Anything, mock.Anything).Return(&pb.PublicUserResponse{Id: "user", Username: "test"}, nil)
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockSessionService.On("Create", mock.Anything, "acme", "user").Return(&session.Session{ID: "session"}, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything, mock.Anything).Return(&public_model.TokenModel{AccessToken: "access_token"}, nil)
+	mockPublisher.On("Publish", mock.Anything, &event.Event{
+		Type:   event.UserRegistered,
+		Tenant: "acme",
+		UserID: "user",
+		Data:   map[string]string{"username": "test", "email": "test@test.com"},
+	}).Return(nil)
+
+	// Call method
+	result, err := authService.Register(context.Background(), &public_model.RegisterModel{Email: "test@test.com", Username: "test", Password: "password", TenantID: "acme"})
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestLogin_PublishesEvent(t *testing.T) {
+	// Setup mocks
+	mockTokenService := new(MockTokenService)
+	mockCrypto := new(MockCrypto)
+	mockUserServiceClient := new(MockUserServiceClient)
+	mockSessionService := new(MockSessionService)
+	mockPublisher := new(MockPublisher)
+
+	authService := auth.NewAuthService(mockTokenService, mockCrypto, mockUserServiceClient, mockSessionService, newAuditRecorder(), mockPublisher)
+
+	// Setup expectations
+	mockTokenService.On("Policy", "").Return(token.DefaultPolicies().Default, nil)
+	mockTokenService.On("CreateServiceToken", mock.Anything).Return("mocked_token", nil)
+	mockUserServiceClient.On("GetPrivateUserByIdentifier", mock.Anything, mock.Anything).Return(&pb.UserResponse{Id: "user", Hash: "hashed_password"}, nil)
+	mockCrypto.On("CompareHashAndPassword", "hashed_password", "password").Return(nil)
+	mockCrypto.On("CompareHashAndPassword", "hashed_password", "wrong").Return(errors.New("mismatch"))
+	mockSessionService.On("Create", mock.Anything, "", "user").Return(&session.Session{ID: "session"}, nil)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything, mock.Anything).Return(&public_model.TokenModel{AccessToken: "access_token"}, nil)
+	mockPublisher.On("Publish", mock.Anything, &event.Event{
+		Type:   event.UserLoggedIn,
+		UserID: "user",
+		Data:   map[string]string{"client_id": "", "ip": "127.0.0.1", "user_agent": "test-agent"},
+	}).Return(errors.New("outbox full"))
+
+	ctx := session.WithClientInfo(context.Background(), session.ClientInfo{IP: "127.0.0.1", UserAgent: "test-agent"})
+
+	// Call method
+	result, err := authService.Login(ctx, &public_model.LoginModel{Email: "test@test.com", Password: "password"})
+
+	// Assertions: a failure to publish does not fail the login
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+
+	// Failed logins are not published
+	_, err = authService.Login(ctx, &public_model.LoginModel{Email: "test@test.com", Password: "wrong"})
+	assert.Error(t, err)
+	mockPublisher.AssertNumberOfCalls(t, "Publish", 1)
 }
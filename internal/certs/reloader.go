@@ -0,0 +1,194 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Reloader serves a certificate and a CA bundle read from PEM files. Reload picks up changed files, so
+// that certificates can be rotated without a restart; a broken change is logged and the previous files
+// stay in force. The TLS configurations it builds read the current files on every handshake.
+type Reloader struct {
+	CertFile    string // Certificate chain, none is presented when empty
+	KeyFile     string // Private key of the certificate
+	CAFile      string // CAs peer certificates are verified against, the system's when empty
+	certificate atomic.Pointer[tls.Certificate]
+	pool        atomic.Pointer[x509.CertPool]
+	fingerprint atomic.Value // Sizes and modification times of the loaded files
+}
+
+// NewReloader loads the certificate and CA files. It fails if they cannot be loaded.
+func NewReloader(certFile, keyFile, caFile string) (*Reloader, error) {
+	r := &Reloader{CertFile: certFile, KeyFile: keyFile, CAFile: caFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the files again if any of them changed since the last load. It reports whether new
+// files were loaded.
+func (r *Reloader) Reload() (bool, error) {
+	fingerprint, err := r.fingerprintFiles()
+	if err != nil {
+		return false, err
+	}
+	if previous, ok := r.fingerprint.Load().(string); ok && previous == fingerprint {
+		return false, nil
+	}
+
+	var certificate *tls.Certificate
+	if r.CertFile != "" {
+		loaded, err := tls.LoadX509KeyPair(r.CertFile, r.KeyFile)
+		if err != nil {
+			return false, fmt.Errorf("certificate %s: %w", r.CertFile, err)
+		}
+		certificate = &loaded
+	}
+	var pool *x509.CertPool
+	if r.CAFile != "" {
+		data, err := os.ReadFile(r.CAFile)
+		if err != nil {
+			return false, err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return false, fmt.Errorf("CA bundle %s: no PEM certificates", r.CAFile)
+		}
+	}
+
+	r.certificate.Store(certificate)
+	r.pool.Store(pool)
+	r.fingerprint.Store(fingerprint)
+	return true, nil
+}
+
+// Watch reloads the files every interval until the context is done.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				slog.ErrorContext(ctx, "Keeping previous certificates, reload failed", "cert_file", r.CertFile, "ca_file", r.CAFile, "error", err)
+			} else if reloaded {
+				slog.InfoContext(ctx, "Reloaded certificates", "cert_file", r.CertFile, "ca_file", r.CAFile)
+			}
+		}
+	}
+}
+
+// Certificate returns the loaded certificate, or nil when there is none.
+func (r *Reloader) Certificate() *tls.Certificate {
+	return r.certificate.Load()
+}
+
+// CAPool returns the loaded CAs, or nil when the system's are used.
+func (r *Reloader) CAPool() *x509.CertPool {
+	return r.pool.Load()
+}
+
+// ServerConfig returns a server configuration presenting the certificate. Client certificates are
+// requested and verified against the CAs as clientAuth says. nextProtos are the application protocols
+// offered, such as "h2" for gRPC.
+func (r *Reloader) ServerConfig(clientAuth tls.ClientAuthType, nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: nextProtos,
+		// Built per handshake so that a reloaded CA bundle applies to new connections
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			certificate := r.certificate.Load()
+			if certificate == nil {
+				return nil, errors.New("no server certificate loaded")
+			}
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   nextProtos,
+				Certificates: []tls.Certificate{*certificate},
+				ClientAuth:   clientAuth,
+				ClientCAs:    r.pool.Load(),
+			}, nil
+		},
+	}
+}
+
+// ClientConfig returns a client configuration for connections to the address, verifying the server
+// certificate against the CAs and presenting the certificate, if any, to servers that ask for one. The
+// server certificate must be valid for serverName, or for the host of the address when it is empty,
+// whether that is a DNS name or an IP address.
+func (r *Reloader) ClientConfig(address string, serverName string) *tls.Config {
+	if serverName == "" {
+		serverName = address
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			serverName = host
+		}
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			if certificate := r.certificate.Load(); certificate != nil {
+				return certificate, nil
+			}
+			// No certificate; the server decides whether to go on without one
+			return &tls.Certificate{}, nil
+		},
+		// crypto/tls verifies against the CAs the configuration was built with, which a reload does not
+		// change, so the server certificate is verified against the current ones in VerifyConnection
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			return r.verifyServer(state, serverName)
+		},
+	}
+}
+
+// verifyServer verifies the server certificate chain against the current CAs and checks that it is
+// valid for the server name. The name is the one the configuration was built for: crypto/tls reports
+// none for connections to IP addresses, and x509 skips the check without one.
+func (r *Reloader) verifyServer(state tls.ConnectionState, serverName string) error {
+	if serverName == "" {
+		return errors.New("no server name to verify the server certificate against")
+	}
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+	opts := x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         r.pool.Load(),
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, intermediate := range state.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(intermediate)
+	}
+	_, err := state.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// fingerprintFiles summarizes the files so that changes can be detected cheaply.
+func (r *Reloader) fingerprintFiles() (string, error) {
+	var b strings.Builder
+	for _, path := range []string{r.CertFile, r.KeyFile, r.CAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
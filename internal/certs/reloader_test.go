@@ -0,0 +1,324 @@
+package certs_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/certs"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/identity"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+)
+
+// authority issues certificates for tests.
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newAuthority(t *testing.T, name string) *authority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return &authority{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM certificate and key of a leaf valid for the DNS name or IP address and URI.
+func (a *authority) issue(t *testing.T, dnsName string, uri string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{dnsName},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if ip := net.ParseIP(dnsName); ip != nil {
+		template.DNSNames, template.IPAddresses = nil, []net.IP{ip}
+	}
+	if uri != "" {
+		parsed, err := url.Parse(uri)
+		assert.NoError(t, err)
+		template.URIs = []*url.URL{parsed}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFile writes a file with a modification time that differs from any earlier write
+func writeFile(t *testing.T, path string, content []byte, age time.Duration) {
+	assert.NoError(t, os.WriteFile(path, content, 0o600))
+	modTime := time.Now().Add(-age)
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+// files writes a certificate, its key and a CA bundle to a directory and returns their paths.
+func files(t *testing.T, cert []byte, key []byte, ca []byte) (string, string, string) {
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	writeFile(t, certFile, cert, time.Hour)
+	writeFile(t, keyFile, key, time.Hour)
+	writeFile(t, caFile, ca, time.Hour)
+	return certFile, keyFile, caFile
+}
+
+// handshake connects the client to a server on localhost and returns the server's view of the
+// connection, or the error of the client's handshake.
+func handshake(t *testing.T, server *tls.Config, client *tls.Config) (tls.ConnectionState, error) {
+	return handshakeTo(t, "localhost", server, client)
+}
+
+// handshakeTo is handshake dialling the host, a name or an IP address of the loopback interface.
+func handshakeTo(t *testing.T, host string, server *tls.Config, client *tls.Config) (tls.ConnectionState, error) {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	states := make(chan tls.ConnectionState, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tlsConn := conn.(*tls.Conn)
+		if tlsConn.Handshake() == nil {
+			states <- tlsConn.ConnectionState()
+		}
+		close(states)
+	}()
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	conn, err := tls.Dial("tcp", net.JoinHostPort(host, port), client)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer conn.Close()
+	return <-states, nil
+}
+
+func TestReloader_MutualTLS(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	serverCert, serverKey := ca.issue(t, "localhost", "")
+	clientCert, clientKey := ca.issue(t, "user-service", "spiffe://bitbridge/user-service")
+	server, err := certs.NewReloader(files(t, serverCert, serverKey, ca.pem))
+	assert.NoError(t, err)
+	client, err := certs.NewReloader(files(t, clientCert, clientKey, ca.pem))
+	assert.NoError(t, err)
+
+	state, err := handshake(t, server.ServerConfig(tls.RequireAndVerifyClientCert), client.ClientConfig("localhost:3003", ""))
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, state.VerifiedChains)
+	assert.Equal(t, "spiffe://bitbridge/user-service", state.VerifiedChains[0][0].URIs[0].String())
+}
+
+func TestReloader_ClientWithoutCertificate(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	serverCert, serverKey := ca.issue(t, "localhost", "")
+	server, err := certs.NewReloader(files(t, serverCert, serverKey, ca.pem))
+	assert.NoError(t, err)
+	_, _, caFile := files(t, nil, nil, ca.pem)
+	client, err := certs.NewReloader("", "", caFile)
+	assert.NoError(t, err)
+	assert.Nil(t, client.Certificate())
+
+	// Verified only if given, so the client connects without an identity
+	state, err := handshake(t, server.ServerConfig(tls.VerifyClientCertIfGiven), client.ClientConfig("localhost:3003", ""))
+	assert.NoError(t, err)
+	assert.Empty(t, state.VerifiedChains)
+}
+
+func TestReloader_VerifiesServer(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	serverCert, serverKey := ca.issue(t, "localhost", "")
+	server, err := certs.NewReloader(files(t, serverCert, serverKey, ca.pem))
+	assert.NoError(t, err)
+
+	// Signed by another CA
+	_, _, otherCAFile := files(t, nil, nil, newAuthority(t, "other").pem)
+	client, err := certs.NewReloader("", "", otherCAFile)
+	assert.NoError(t, err)
+	_, err = handshake(t, server.ServerConfig(tls.NoClientCert), client.ClientConfig("localhost:3003", ""))
+	assert.Error(t, err)
+
+	// Valid for another name
+	_, _, caFile := files(t, nil, nil, ca.pem)
+	client, err = certs.NewReloader("", "", caFile)
+	assert.NoError(t, err)
+	_, err = handshake(t, server.ServerConfig(tls.NoClientCert), client.ClientConfig("localhost:3003", "auth.internal"))
+	assert.Error(t, err)
+}
+
+func TestReloader_VerifiesServerIP(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	_, _, caFile := files(t, nil, nil, ca.pem)
+	client, err := certs.NewReloader("", "", caFile)
+	assert.NoError(t, err)
+
+	// Dialling an IP address, the certificate must be valid for it, not merely chain to the CAs
+	serverCert, serverKey := ca.issue(t, "auth.internal", "")
+	server, err := certs.NewReloader(files(t, serverCert, serverKey, ca.pem))
+	assert.NoError(t, err)
+	_, err = handshakeTo(t, "127.0.0.1", server.ServerConfig(tls.NoClientCert), client.ClientConfig("127.0.0.1:3003", ""))
+	assert.Error(t, err)
+
+	serverCert, serverKey = ca.issue(t, "127.0.0.1", "")
+	server, err = certs.NewReloader(files(t, serverCert, serverKey, ca.pem))
+	assert.NoError(t, err)
+	_, err = handshakeTo(t, "127.0.0.1", server.ServerConfig(tls.NoClientCert), client.ClientConfig("127.0.0.1:3003", ""))
+	assert.NoError(t, err)
+
+	// A configured server name takes precedence over the address
+	_, err = handshakeTo(t, "127.0.0.1", server.ServerConfig(tls.NoClientCert), client.ClientConfig("127.0.0.1:3003", "auth.internal"))
+	assert.Error(t, err)
+}
+
+func TestReloader_Reload(t *testing.T) {
+	oldCA, newCA := newAuthority(t, "old"), newAuthority(t, "new")
+	serverCert, serverKey := oldCA.issue(t, "localhost", "")
+	certFile, keyFile, caFile := files(t, serverCert, serverKey, oldCA.pem)
+	server, err := certs.NewReloader(certFile, keyFile, "")
+	assert.NoError(t, err)
+	client, err := certs.NewReloader("", "", caFile)
+	assert.NoError(t, err)
+	serverConfig, clientConfig := server.ServerConfig(tls.NoClientCert), client.ClientConfig("localhost:3003", "")
+
+	reloaded, err := server.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// The server rotates to a certificate of a CA the client does not trust yet
+	serverCert, serverKey = newCA.issue(t, "localhost", "")
+	writeFile(t, certFile, serverCert, 0)
+	writeFile(t, keyFile, serverKey, 0)
+	reloaded, err = server.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	_, err = handshake(t, serverConfig, clientConfig)
+	assert.Error(t, err)
+
+	// The configurations already handed out pick up the client's new CA bundle
+	writeFile(t, caFile, append(oldCA.pem, newCA.pem...), 0)
+	reloaded, err = client.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	_, err = handshake(t, serverConfig, clientConfig)
+	assert.NoError(t, err)
+
+	// A broken change keeps the previous certificate in force
+	certificate := server.Certificate()
+	writeFile(t, keyFile, []byte("not a key"), time.Minute)
+	_, err = server.Reload()
+	assert.Error(t, err)
+	assert.Same(t, certificate, server.Certificate())
+	_, err = handshake(t, serverConfig, clientConfig)
+	assert.NoError(t, err)
+}
+
+func TestReloader_Watch(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	cert, key := ca.issue(t, "localhost", "")
+	certFile, keyFile, _ := files(t, cert, key, ca.pem)
+	reloader, err := certs.NewReloader(certFile, keyFile, "")
+	assert.NoError(t, err)
+	certificate := reloader.Certificate()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 5*time.Millisecond)
+
+	cert, key = ca.issue(t, "localhost", "")
+	writeFile(t, keyFile, key, 0)
+	writeFile(t, certFile, cert, 0)
+
+	assert.Eventually(t, func() bool {
+		return reloader.Certificate() != certificate
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestNewReloader_Errors(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	cert, key := ca.issue(t, "localhost", "")
+	certFile, keyFile, caFile := files(t, cert, key, ca.pem)
+
+	_, err := certs.NewReloader(filepath.Join(t.TempDir(), "missing.crt"), keyFile, "")
+	assert.Error(t, err)
+
+	_, err = certs.NewReloader(certFile, caFile, "")
+	assert.Error(t, err)
+
+	_, err = certs.NewReloader("", "", keyFile)
+	assert.ErrorContains(t, err, "no PEM certificates")
+}
+
+func TestReloader_GRPC(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	serverCert, serverKey := ca.issue(t, "localhost", "")
+	clientCert, clientKey := ca.issue(t, "user-service", "spiffe://bitbridge/user-service")
+	server, err := certs.NewReloader(files(t, serverCert, serverKey, ca.pem))
+	assert.NoError(t, err)
+	client, err := certs.NewReloader(files(t, clientCert, clientKey, ca.pem))
+	assert.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	var caller string
+	grpcServer := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(server.ServerConfig(tls.RequireAndVerifyClientCert, "h2"))),
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			p, _ := peer.FromContext(ctx)
+			caller, _ = identity.ServiceFromCertificate(p.AuthInfo.(credentials.TLSInfo).State.VerifiedChains[0][0])
+			return handler(ctx, req)
+		}),
+	)
+	grpc_health_v1.RegisterHealthServer(grpcServer, health.NewServer())
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	address := net.JoinHostPort("localhost", port)
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(credentials.NewTLS(client.ClientConfig(address, ""))))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "spiffe://bitbridge/user-service", caller)
+}
@@ -0,0 +1,484 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/validation"
+)
+
+// Config holds every setting of the service. Settings are read from a YAML file, environment variables
+// and flags, each overriding the one before. See Load for how they are named.
+type Config struct {
+	HTTP        Server            `yaml:"http"`
+	GRPC        Server            `yaml:"grpc"`
+	TLS         TLS               `yaml:"tls"`
+	Shutdown    Shutdown          `yaml:"shutdown"`
+	Logging     Logging           `yaml:"logging"`
+	Health      Health            `yaml:"health"`
+	Secrets     Secrets           `yaml:"secrets"`
+	Vault       Vault             `yaml:"vault"`
+	UserService UserService       `yaml:"user_service"`
+	Tenants     map[string]Tenant `yaml:"tenants"` // Tenants sharing the deployment, keyed by tenant ID. File only.
+	Token       Token             `yaml:"token"`
+	Policy      Policy            `yaml:"policy"`
+	Session     Session           `yaml:"session"`
+	APIKeys     APIKeys           `yaml:"api_keys"`
+	Audit       Audit             `yaml:"audit"`
+	Events      Events            `yaml:"events"`
+	Tracing     Tracing           `yaml:"tracing"`
+}
+
+// Server configures a listener.
+type Server struct {
+	Address string    `yaml:"address"` // host:port to listen on, the host may be empty
+	TLS     ServerTLS `yaml:"tls"`     // Transport security, plaintext unless a certificate is configured
+}
+
+// ServerTLS configures the certificate a server presents and the client certificates it accepts.
+type ServerTLS struct {
+	CertFile          string `yaml:"cert_file"`           // PEM certificate chain, TLS is off when empty
+	KeyFile           string `yaml:"key_file"`            // PEM private key of the certificate
+	ClientCAFile      string `yaml:"client_ca_file"`      // CAs client certificates are verified against, none are requested when empty
+	RequireClientCert bool   `yaml:"require_client_cert"` // Reject clients without a verified certificate instead of treating them as external callers
+}
+
+// Enabled reports whether the server serves over TLS.
+func (t ServerTLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// ClientAuth returns how the server treats client certificates.
+func (t ServerTLS) ClientAuth() tls.ClientAuthType {
+	switch {
+	case t.ClientCAFile == "":
+		return tls.NoClientCert
+	case t.RequireClientCert:
+		return tls.RequireAndVerifyClientCert
+	default:
+		return tls.VerifyClientCertIfGiven
+	}
+}
+
+// ClientTLS configures how a client verifies the server and the certificate it presents.
+type ClientTLS struct {
+	Enabled    bool   `yaml:"enabled"`     // Connect over TLS
+	CAFile     string `yaml:"ca_file"`     // CAs the server certificate is verified against, the system's when empty
+	CertFile   string `yaml:"cert_file"`   // PEM client certificate chain presented to servers that ask for one, none when empty
+	KeyFile    string `yaml:"key_file"`    // PEM private key of the client certificate
+	ServerName string `yaml:"server_name"` // Name the server certificate must be valid for, the host of the address when empty
+}
+
+// TLS configures the certificates shared by the servers and clients.
+type TLS struct {
+	ReloadInterval time.Duration `yaml:"reload_interval"` // How often certificate files are checked for changes
+}
+
+// Shutdown configures how the servers stop.
+type Shutdown struct {
+	Timeout time.Duration `yaml:"timeout"` // How long in-flight requests may take to complete before they are cut off
+}
+
+// Log formats.
+const (
+	LogFormatJSON = "json" // One JSON object per line, for log collectors
+	LogFormatText = "text" // key=value pairs, for reading in a terminal
+)
+
+// Logging configures the structured log written to standard error.
+type Logging struct {
+	Level  string `yaml:"level"`  // One of debug, info, warn and error. Requests are logged with their payloads at debug.
+	Format string `yaml:"format"` // One of json and text
+}
+
+// SlogLevel returns the configured level as a slog level.
+func (l Logging) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(l.Level))
+	return level, err
+}
+
+// Health configures the readiness checks.
+type Health struct {
+	CheckTimeout time.Duration `yaml:"check_timeout"` // Time limit of each dependency check
+}
+
+// Secrets providers.
+const (
+	SecretsVault = "vault" // Secrets are read from Vault, configured by the vault section
+	SecretsEnv   = "env"   // Secrets are read from environment variables
+	SecretsFile  = "file"  // Secrets are read from files below a directory
+	SecretsDev   = "dev"   // Secrets are generated on start and lost on restart, for local development only
+)
+
+// Secrets selects where signing keys and other secrets are read from. Secrets are named by their
+// Vault paths, such as vault.jwt_secret_path, whatever the provider.
+type Secrets struct {
+	Provider  string        `yaml:"provider"`   // One of vault, env, file and dev
+	EnvPrefix string        `yaml:"env_prefix"` // Prefix of the variables of the env provider
+	Dir       string        `yaml:"dir"`        // Directory of the file provider
+	CacheTTL  time.Duration `yaml:"cache_ttl"`  // How long secrets are served before they are read again
+}
+
+// Vault configures access to the secrets store.
+type Vault struct {
+	Address       string    `yaml:"address"`         // URL of the Vault server
+	Auth          VaultAuth `yaml:"auth"`            // How the service authenticates to Vault
+	Token         string    `yaml:"token"`           // Token to authenticate with the token method
+	JWTSecretPath string    `yaml:"jwt_secret_path"` // Secret signing tokens of the default tenant
+}
+
+// Vault auth methods.
+const (
+	VaultAuthToken      = "token"      // A token configured as vault.token
+	VaultAuthTokenFile  = "token_file" // A token read from a file, as written by a Vault agent
+	VaultAuthAppRole    = "approle"    // An AppRole role ID and secret ID
+	VaultAuthKubernetes = "kubernetes" // The service account token of the pod
+)
+
+// VaultAuth configures how the service authenticates to Vault.
+type VaultAuth struct {
+	Method       string `yaml:"method"`         // One of token, token_file, approle and kubernetes
+	Mount        string `yaml:"mount"`          // Mount path of the auth method, its name when empty
+	TokenFile    string `yaml:"token_file"`     // File holding the token of the token_file method
+	RoleID       string `yaml:"role_id"`        // Role ID of the approle method
+	SecretIDFile string `yaml:"secret_id_file"` // File holding the secret ID of the approle method
+	Role         string `yaml:"role"`           // Vault role of the kubernetes method
+	JWTFile      string `yaml:"jwt_file"`       // Service account token of the kubernetes method, the pod's own when empty
+}
+
+// UserService configures the connection to the user service.
+type UserService struct {
+	Address string    `yaml:"address"` // host:port of the user service's gRPC server
+	TLS     ClientTLS `yaml:"tls"`     // Transport security of the connection
+}
+
+// Tenant configures a tenant sharing the deployment.
+type Tenant struct {
+	Hosts []string `yaml:"hosts"` // Host names that select the tenant
+}
+
+// Token configures the lifetimes of issued tokens. Clients may override the lifetimes of the tokens
+// issued to them.
+type Token struct {
+	AccessTTL        time.Duration          `yaml:"access_ttl"`        // Lifetime of access tokens
+	RefreshTTL       time.Duration          `yaml:"refresh_ttl"`       // Lifetime of refresh tokens
+	MaxSession       time.Duration          `yaml:"max_session"`       // No token outlives its session start by more than this
+	Leeway           time.Duration          `yaml:"leeway"`            // Allowed clock skew when checking token times
+	ServiceTokenTTL  time.Duration          `yaml:"service_token_ttl"` // Lifetime of the tokens the service calls the user service with
+	APIKeyTokenTTL   time.Duration          `yaml:"api_key_token_ttl"` // Lifetime of the access tokens API keys are exchanged for
+	ImpersonationTTL time.Duration          `yaml:"impersonation_ttl"` // Lifetime of the access tokens of admins acting as a user
+	Clients          map[string]TokenClient `yaml:"clients"`           // Clients that may request tokens, keyed by client ID, which doubles as the audience. File only.
+}
+
+// TokenClient overrides the token lifetimes of a client. Lifetimes left unset are taken from the token
+// section.
+type TokenClient struct {
+	AccessTTL  time.Duration `yaml:"access_ttl"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+	MaxSession time.Duration `yaml:"max_session"`
+	Leeway     time.Duration `yaml:"leeway"`
+}
+
+// Policy configures the access control policies.
+type Policy struct {
+	RBACFile       string        `yaml:"rbac_file"`       // Role-based access control policy
+	ABACDir        string        `yaml:"abac_dir"`        // Directory of attribute-based policies
+	ReloadInterval time.Duration `yaml:"reload_interval"` // How often the attribute-based policies are reloaded
+}
+
+// Session configures login sessions.
+type Session struct {
+	IdleTimeout time.Duration `yaml:"idle_timeout"` // A session expires when it is not refreshed within this window
+}
+
+// APIKeys configures API key lifetimes.
+type APIKeys struct {
+	DefaultLifetime time.Duration `yaml:"default_lifetime"` // Lifetime of keys created without an expiry
+	MaxLifetime     time.Duration `yaml:"max_lifetime"`     // Longest lifetime a key may be created with
+}
+
+// Audit configures the audit log.
+type Audit struct {
+	File string `yaml:"file"` // JSON-lines file the events are appended to
+}
+
+// Events configures delivery of domain events.
+type Events struct {
+	WebhookURL        string        `yaml:"webhook_url"`         // Endpoint events are posted to, empty to discard events
+	WebhookSecretPath string        `yaml:"webhook_secret_path"` // Vault secret the payloads are signed with
+	WebhookTimeout    time.Duration `yaml:"webhook_timeout"`     // Time limit of a single delivery
+	OutboxDir         string        `yaml:"outbox_dir"`          // Directory events wait in until delivered
+	DispatchInterval  time.Duration `yaml:"dispatch_interval"`   // How often due events are delivered
+}
+
+// Tracing exporters.
+const (
+	TracingNone   = "none"   // Spans are not recorded, trace context is still passed on to the user service
+	TracingStdout = "stdout" // Spans are written to standard output, for local development
+	TracingFile   = "file"   // Spans are appended to a file as JSON
+	TracingOTLP   = "otlp"   // Spans are sent to an OpenTelemetry collector over gRPC
+)
+
+// Tracing configures OpenTelemetry tracing.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter"`     // One of none, stdout, file and otlp
+	Endpoint    string  `yaml:"endpoint"`     // host:port of the collector of the otlp exporter
+	Insecure    bool    `yaml:"insecure"`     // Connect to the collector without TLS
+	File        string  `yaml:"file"`         // File of the file exporter
+	SampleRatio float64 `yaml:"sample_ratio"` // Share of new traces that are recorded, from 0 to 1
+	ServiceName string  `yaml:"service_name"` // Name the spans are reported under
+}
+
+// Default returns the configuration used for settings that are not configured. The Vault credentials
+// have no default and must be configured when secrets are read from Vault.
+func Default() *Config {
+	return &Config{
+		HTTP:     Server{Address: ":3002"},
+		GRPC:     Server{Address: ":3003"},
+		TLS:      TLS{ReloadInterval: time.Minute},
+		Shutdown: Shutdown{Timeout: 15 * time.Second},
+		Logging:  Logging{Level: "info", Format: LogFormatJSON},
+		Health:   Health{CheckTimeout: 2 * time.Second},
+		Secrets:  Secrets{Provider: SecretsVault, CacheTTL: 5 * time.Minute},
+		Vault: Vault{
+			Address:       "http://127.0.0.1:8200",
+			Auth:          VaultAuth{Method: VaultAuthToken},
+			JWTSecretPath: "secret/data/jwt_secret",
+		},
+		UserService: UserService{Address: "localhost:3001"},
+		Token: Token{
+			AccessTTL:        15 * time.Minute,
+			RefreshTTL:       7 * 24 * time.Hour,
+			MaxSession:       30 * 24 * time.Hour,
+			Leeway:           30 * time.Second,
+			ServiceTokenTTL:  15 * time.Minute,
+			APIKeyTokenTTL:   5 * time.Minute,
+			ImpersonationTTL: 10 * time.Minute,
+		},
+		Policy:  Policy{RBACFile: "config/rbac.yaml", ABACDir: "config/abac", ReloadInterval: 5 * time.Second},
+		Session: Session{IdleTimeout: 24 * time.Hour},
+		APIKeys: APIKeys{DefaultLifetime: 90 * 24 * time.Hour, MaxLifetime: 365 * 24 * time.Hour},
+		Audit:   Audit{File: "audit.log"},
+		Events: Events{
+			WebhookSecretPath: "secret/data/webhook_secret",
+			WebhookTimeout:    10 * time.Second,
+			OutboxDir:         "outbox",
+			DispatchInterval:  time.Second,
+		},
+		Tracing: Tracing{
+			Exporter:    TracingNone,
+			Endpoint:    "localhost:4317",
+			File:        "traces.json",
+			SampleRatio: 1,
+			ServiceName: "auth-service",
+		},
+	}
+}
+
+// Validate checks the configuration, reporting every invalid setting by its file name.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(name string, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s %s", name, fmt.Sprintf(format, args...)))
+	}
+
+	validateAddress(invalid, "http.address", c.HTTP.Address)
+	validateAddress(invalid, "grpc.address", c.GRPC.Address)
+	validateServerTLS(invalid, "http.tls", c.HTTP.TLS)
+	validateServerTLS(invalid, "grpc.tls", c.GRPC.TLS)
+	validatePositive(invalid, "tls.reload_interval", c.TLS.ReloadInterval)
+	validatePositive(invalid, "shutdown.timeout", c.Shutdown.Timeout)
+	if _, err := c.Logging.SlogLevel(); err != nil {
+		invalid("logging.level", "must be debug, info, warn or error, got %q", c.Logging.Level)
+	}
+	if c.Logging.Format != LogFormatJSON && c.Logging.Format != LogFormatText {
+		invalid("logging.format", "must be json or text, got %q", c.Logging.Format)
+	}
+	validatePositive(invalid, "health.check_timeout", c.Health.CheckTimeout)
+	validateAddress(invalid, "user_service.address", c.UserService.Address)
+	validateClientTLS(invalid, "user_service.tls", c.UserService.TLS)
+	validatePositive(invalid, "secrets.cache_ttl", c.Secrets.CacheTTL)
+	switch c.Secrets.Provider {
+	case SecretsVault:
+		validateURL(invalid, "vault.address", c.Vault.Address)
+		validateVaultAuth(invalid, c.Vault)
+	case SecretsFile:
+		if c.Secrets.Dir == "" {
+			invalid("secrets.dir", "is required with the file provider")
+		}
+	case SecretsEnv, SecretsDev:
+	default:
+		invalid("secrets.provider", "must be vault, env, file or dev, got %q", c.Secrets.Provider)
+	}
+	if c.Vault.JWTSecretPath == "" {
+		invalid("vault.jwt_secret_path", "is required")
+	}
+	for tenantID, t := range c.Tenants {
+		if !validation.ValidTenantID(tenantID) {
+			invalid("tenants", "has invalid tenant ID %q, tenant IDs are lower-case DNS labels", tenantID)
+		}
+		if len(t.Hosts) == 0 {
+			invalid("tenants."+tenantID+".hosts", "must name at least one host")
+		}
+	}
+	validateToken(invalid, c.Token)
+	if c.Policy.RBACFile == "" {
+		invalid("policy.rbac_file", "is required")
+	}
+	if c.Policy.ABACDir == "" {
+		invalid("policy.abac_dir", "is required")
+	}
+	validatePositive(invalid, "policy.reload_interval", c.Policy.ReloadInterval)
+	validatePositive(invalid, "session.idle_timeout", c.Session.IdleTimeout)
+	validatePositive(invalid, "api_keys.default_lifetime", c.APIKeys.DefaultLifetime)
+	validatePositive(invalid, "api_keys.max_lifetime", c.APIKeys.MaxLifetime)
+	if c.APIKeys.DefaultLifetime > c.APIKeys.MaxLifetime {
+		invalid("api_keys.default_lifetime", "must not exceed api_keys.max_lifetime")
+	}
+	if c.Audit.File == "" {
+		invalid("audit.file", "is required")
+	}
+	if c.Events.WebhookURL != "" {
+		validateURL(invalid, "events.webhook_url", c.Events.WebhookURL)
+		if c.Events.WebhookSecretPath == "" {
+			invalid("events.webhook_secret_path", "is required with a webhook")
+		}
+		if c.Events.OutboxDir == "" {
+			invalid("events.outbox_dir", "is required with a webhook")
+		}
+		validatePositive(invalid, "events.webhook_timeout", c.Events.WebhookTimeout)
+		validatePositive(invalid, "events.dispatch_interval", c.Events.DispatchInterval)
+	}
+	switch c.Tracing.Exporter {
+	case TracingOTLP:
+		validateAddress(invalid, "tracing.endpoint", c.Tracing.Endpoint)
+	case TracingFile:
+		if c.Tracing.File == "" {
+			invalid("tracing.file", "is required with the file exporter")
+		}
+	case TracingNone, TracingStdout:
+	default:
+		invalid("tracing.exporter", "must be none, stdout, file or otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		invalid("tracing.sample_ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+	if c.Tracing.ServiceName == "" {
+		invalid("tracing.service_name", "is required")
+	}
+	return errors.Join(errs...)
+}
+
+func validateVaultAuth(invalid func(string, string, ...interface{}), v Vault) {
+	required := func(name string, value string) {
+		if value == "" {
+			invalid(name, "is required with the %s auth method", v.Auth.Method)
+		}
+	}
+	switch v.Auth.Method {
+	case VaultAuthToken:
+		required("vault.token", v.Token)
+	case VaultAuthTokenFile:
+		required("vault.auth.token_file", v.Auth.TokenFile)
+	case VaultAuthAppRole:
+		required("vault.auth.role_id", v.Auth.RoleID)
+		required("vault.auth.secret_id_file", v.Auth.SecretIDFile)
+	case VaultAuthKubernetes:
+		required("vault.auth.role", v.Auth.Role)
+	default:
+		invalid("vault.auth.method", "must be token, token_file, approle or kubernetes, got %q", v.Auth.Method)
+	}
+}
+
+func validateToken(invalid func(string, string, ...interface{}), t Token) {
+	validatePositive(invalid, "token.access_ttl", t.AccessTTL)
+	validatePositive(invalid, "token.refresh_ttl", t.RefreshTTL)
+	validatePositive(invalid, "token.max_session", t.MaxSession)
+	validateNotNegative(invalid, "token.leeway", t.Leeway)
+	validatePositive(invalid, "token.service_token_ttl", t.ServiceTokenTTL)
+	validatePositive(invalid, "token.api_key_token_ttl", t.APIKeyTokenTTL)
+	validatePositive(invalid, "token.impersonation_ttl", t.ImpersonationTTL)
+	if t.AccessTTL > t.RefreshTTL {
+		invalid("token.access_ttl", "must not exceed token.refresh_ttl")
+	}
+	for clientID, client := range t.Clients {
+		name := "token.clients." + clientID
+		if clientID == "" {
+			invalid("token.clients", "has an empty client ID")
+		}
+		validateNotNegative(invalid, name+".access_ttl", client.AccessTTL)
+		validateNotNegative(invalid, name+".refresh_ttl", client.RefreshTTL)
+		validateNotNegative(invalid, name+".max_session", client.MaxSession)
+		validateNotNegative(invalid, name+".leeway", client.Leeway)
+		accessTTL, refreshTTL := t.AccessTTL, t.RefreshTTL
+		if client.AccessTTL > 0 {
+			accessTTL = client.AccessTTL
+		}
+		if client.RefreshTTL > 0 {
+			refreshTTL = client.RefreshTTL
+		}
+		if accessTTL > refreshTTL {
+			invalid(name+".access_ttl", "must not exceed the refresh TTL of the client")
+		}
+	}
+}
+
+func validateServerTLS(invalid func(string, string, ...interface{}), name string, t ServerTLS) {
+	validateKeyPair(invalid, name, t.CertFile, t.KeyFile)
+	if t.ClientCAFile != "" && !t.Enabled() {
+		invalid(name+".client_ca_file", "requires %s.cert_file", name)
+	}
+	if t.RequireClientCert && t.ClientCAFile == "" {
+		invalid(name+".require_client_cert", "requires %s.client_ca_file", name)
+	}
+}
+
+func validateClientTLS(invalid func(string, string, ...interface{}), name string, t ClientTLS) {
+	validateKeyPair(invalid, name, t.CertFile, t.KeyFile)
+	if !t.Enabled && (t.CAFile != "" || t.CertFile != "" || t.ServerName != "") {
+		invalid(name, "settings require %s.enabled", name)
+	}
+}
+
+// validateKeyPair checks that a certificate and its key are configured together.
+func validateKeyPair(invalid func(string, string, ...interface{}), name string, certFile string, keyFile string) {
+	if certFile != "" && keyFile == "" {
+		invalid(name+".key_file", "is required with %s.cert_file", name)
+	}
+	if keyFile != "" && certFile == "" {
+		invalid(name+".cert_file", "is required with %s.key_file", name)
+	}
+}
+
+func validateAddress(invalid func(string, string, ...interface{}), name string, address string) {
+	if address == "" {
+		invalid(name, "is required")
+	} else if _, _, err := net.SplitHostPort(address); err != nil {
+		invalid(name, "must be host:port, got %q", address)
+	}
+}
+
+func validateURL(invalid func(string, string, ...interface{}), name string, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid(name, "must be an http or https URL, got %q", value)
+	}
+}
+
+func validatePositive(invalid func(string, string, ...interface{}), name string, d time.Duration) {
+	if d <= 0 {
+		invalid(name, "must be positive, got %s", d)
+	}
+}
+
+func validateNotNegative(invalid func(string, string, ...interface{}), name string, d time.Duration) {
+	if d < 0 {
+		invalid(name, "must not be negative, got %s", d)
+	}
+}
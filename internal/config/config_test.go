@@ -0,0 +1,285 @@
+package config_test
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// env returns a lookup function over the given variables.
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "auth.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := config.Load([]string{"-config", writeFile(t, "")}, env(map[string]string{"AUTH_VAULT_TOKEN": "token"}))
+
+	assert.NoError(t, err)
+	expected := config.Default()
+	expected.Vault.Token = "token"
+	assert.Equal(t, expected, cfg)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, `
+http:
+  address: ":8080"
+grpc:
+  address: ":9090"
+vault:
+  token: file-token
+session:
+  idle_timeout: 2h
+tenants:
+  acme:
+    hosts: [acme.example.com]
+`)
+
+	cfg, err := config.Load(
+		[]string{"-config", path, "-grpc.address", ":9999", "-vault.jwt-secret-path", "secret/data/other"},
+		env(map[string]string{"AUTH_GRPC_ADDRESS": ":9191", "AUTH_VAULT_TOKEN": "env-token", "AUTH_SESSION_IDLE_TIMEOUT": "30m"}),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ":8080", cfg.HTTP.Address)
+	assert.Equal(t, ":9999", cfg.GRPC.Address)
+	assert.Equal(t, "env-token", cfg.Vault.Token)
+	assert.Equal(t, "secret/data/other", cfg.Vault.JWTSecretPath)
+	assert.Equal(t, 30*time.Minute, cfg.Session.IdleTimeout)
+	assert.Equal(t, map[string]config.Tenant{"acme": {Hosts: []string{"acme.example.com"}}}, cfg.Tenants)
+	assert.Equal(t, "localhost:3001", cfg.UserService.Address)
+}
+
+func TestLoad_ConfigFromEnv(t *testing.T) {
+	path := writeFile(t, "vault:\n  token: file-token\n")
+
+	cfg, err := config.Load(nil, env(map[string]string{"AUTH_CONFIG": path}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "file-token", cfg.Vault.Token)
+}
+
+func TestLoad_FileErrors(t *testing.T) {
+	_, err := config.Load([]string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}, env(nil))
+	assert.ErrorContains(t, err, "missing.yaml")
+
+	_, err = config.Load([]string{"-config", writeFile(t, "vault:\n  tokn: typo\n")}, env(nil))
+	assert.ErrorContains(t, err, "field tokn not found")
+}
+
+func TestLoad_InvalidValues(t *testing.T) {
+	_, err := config.Load([]string{"-config", writeFile(t, ""), "-session.idle-timeout", "soon"}, env(nil))
+	assert.ErrorContains(t, err, "-session.idle-timeout")
+
+	_, err = config.Load([]string{"-config", writeFile(t, "")}, env(map[string]string{"AUTH_API_KEYS_MAX_LIFETIME": "forever"}))
+	assert.ErrorContains(t, err, "AUTH_API_KEYS_MAX_LIFETIME")
+
+	_, err = config.Load([]string{"-no-such-flag"}, env(nil))
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	cfg := config.Default()
+	cfg.HTTP.Address = "3002"
+	cfg.Vault.Address = "127.0.0.1:8200"
+	cfg.Tenants = map[string]config.Tenant{"Acme": {}}
+	cfg.APIKeys.DefaultLifetime = 2 * cfg.APIKeys.MaxLifetime
+	cfg.Events.WebhookURL = "http://hooks.example.com/events"
+	cfg.Events.DispatchInterval = 0
+
+	err := cfg.Validate()
+
+	assert.Error(t, err)
+	for _, message := range []string{
+		`http.address must be host:port, got "3002"`,
+		`vault.address must be an http or https URL, got "127.0.0.1:8200"`,
+		"vault.token is required with the token auth method",
+		`tenants has invalid tenant ID "Acme"`,
+		"tenants.Acme.hosts must name at least one host",
+		"api_keys.default_lifetime must not exceed api_keys.max_lifetime",
+		"events.dispatch_interval must be positive, got 0s",
+	} {
+		assert.ErrorContains(t, err, message)
+	}
+
+	cfg = config.Default()
+	cfg.Vault.Token = "token"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_Secrets(t *testing.T) {
+	cfg := config.Default()
+	cfg.Secrets.Provider = "keychain"
+	assert.EqualError(t, cfg.Validate(), `secrets.provider must be vault, env, file or dev, got "keychain"`)
+
+	cfg.Secrets.Provider = "file"
+	assert.EqualError(t, cfg.Validate(), "secrets.dir is required with the file provider")
+
+	// Vault settings are only checked with the vault provider
+	cfg = config.Default()
+	cfg.Secrets.Provider = "dev"
+	cfg.Vault.Address = ""
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_VaultAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    config.VaultAuth
+		message string
+	}{
+		{"TokenFile", config.VaultAuth{Method: "token_file"}, "vault.auth.token_file is required with the token_file auth method"},
+		{"AppRole", config.VaultAuth{Method: "approle", RoleID: "role"}, "vault.auth.secret_id_file is required with the approle auth method"},
+		{"Kubernetes", config.VaultAuth{Method: "kubernetes"}, "vault.auth.role is required with the kubernetes auth method"},
+		{"Unknown", config.VaultAuth{Method: "ldap"}, `vault.auth.method must be token, token_file, approle or kubernetes, got "ldap"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Vault.Auth = tt.auth
+
+			assert.EqualError(t, cfg.Validate(), tt.message)
+		})
+	}
+
+	// Only the token method needs a token
+	cfg := config.Default()
+	cfg.Vault.Auth = config.VaultAuth{Method: "kubernetes", Role: "auth-service"}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoad_DefaultFile(t *testing.T) {
+	cfg, err := config.Load(nil, env(map[string]string{"AUTH_VAULT_TOKEN": "token"}))
+
+	// The default file is optional and absent from this package's directory
+	assert.NoError(t, err)
+	assert.Equal(t, ":3002", cfg.HTTP.Address)
+}
+
+func TestLoad_ShippedFile(t *testing.T) {
+	cfg, err := config.Load([]string{"-config", "../../config/auth.yaml"}, env(map[string]string{"AUTH_VAULT_TOKEN": "token"}))
+
+	assert.NoError(t, err)
+	expected := config.Default()
+	expected.Vault.Token = "token"
+	expected.Tenants = map[string]config.Tenant{}
+	expected.Token.Clients = map[string]config.TokenClient{}
+	assert.Equal(t, expected, cfg)
+}
+
+func TestValidate_Tracing(t *testing.T) {
+	cfg := config.Default()
+	cfg.Vault.Token = "token"
+	cfg.Tracing.Exporter = "jaeger"
+	cfg.Tracing.SampleRatio = 1.5
+	assert.EqualError(t, cfg.Validate(), `tracing.exporter must be none, stdout, file or otlp, got "jaeger"`+"\n"+
+		"tracing.sample_ratio must be between 0 and 1, got 1.5")
+
+	cfg.Tracing.Exporter = "otlp"
+	cfg.Tracing.Endpoint = "collector"
+	cfg.Tracing.SampleRatio = 0.25
+	assert.EqualError(t, cfg.Validate(), `tracing.endpoint must be host:port, got "collector"`)
+}
+
+func TestLoad_Float(t *testing.T) {
+	cfg, err := config.Load([]string{"-config", writeFile(t, ""), "-tracing.sample-ratio", "0.1"}, env(map[string]string{"AUTH_VAULT_TOKEN": "token"}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0.1, cfg.Tracing.SampleRatio)
+}
+
+func TestValidate_Logging(t *testing.T) {
+	cfg := config.Default()
+	cfg.Vault.Token = "token"
+	cfg.Logging = config.Logging{Level: "verbose", Format: "xml"}
+
+	assert.EqualError(t, cfg.Validate(), `logging.level must be debug, info, warn or error, got "verbose"`+"\n"+
+		`logging.format must be json or text, got "xml"`)
+
+	cfg.Logging = config.Logging{Level: "DEBUG", Format: "text"}
+	assert.NoError(t, cfg.Validate())
+	level, err := cfg.Logging.SlogLevel()
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+}
+
+func TestValidate_TLS(t *testing.T) {
+	cfg := config.Default()
+	cfg.Vault.Token = "token"
+	cfg.HTTP.TLS.KeyFile = "tls.key"
+	cfg.GRPC.TLS.ClientCAFile = "ca.crt"
+	cfg.GRPC.TLS.RequireClientCert = true
+	cfg.UserService.TLS.CAFile = "ca.crt"
+	cfg.TLS.ReloadInterval = 0
+	assert.EqualError(t, cfg.Validate(), "http.tls.cert_file is required with http.tls.key_file\n"+
+		"grpc.tls.client_ca_file requires grpc.tls.cert_file\n"+
+		"tls.reload_interval must be positive, got 0s\n"+
+		"user_service.tls settings require user_service.tls.enabled")
+
+	cfg = config.Default()
+	cfg.Vault.Token = "token"
+	cfg.GRPC.TLS = config.ServerTLS{CertFile: "tls.crt", KeyFile: "tls.key", ClientCAFile: "ca.crt"}
+	cfg.UserService.TLS = config.ClientTLS{Enabled: true, CertFile: "client.crt", KeyFile: "client.key"}
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, tls.VerifyClientCertIfGiven, cfg.GRPC.TLS.ClientAuth())
+
+	cfg.GRPC.TLS.RequireClientCert = true
+	assert.Equal(t, tls.RequireAndVerifyClientCert, cfg.GRPC.TLS.ClientAuth())
+	assert.Equal(t, tls.NoClientCert, cfg.HTTP.TLS.ClientAuth())
+	assert.False(t, cfg.HTTP.TLS.Enabled())
+}
+
+func TestLoad_TLS(t *testing.T) {
+	cfg, err := config.Load([]string{"-config", writeFile(t, ""), "-grpc.tls.require-client-cert", "true"}, env(map[string]string{
+		"AUTH_VAULT_TOKEN":             "token",
+		"AUTH_GRPC_TLS_CERT_FILE":      "tls.crt",
+		"AUTH_GRPC_TLS_KEY_FILE":       "tls.key",
+		"AUTH_GRPC_TLS_CLIENT_CA_FILE": "ca.crt",
+	}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, config.ServerTLS{CertFile: "tls.crt", KeyFile: "tls.key", ClientCAFile: "ca.crt", RequireClientCert: true}, cfg.GRPC.TLS)
+}
+
+func TestValidate_Token(t *testing.T) {
+	cfg := config.Default()
+	cfg.Vault.Token = "token"
+	cfg.Token.AccessTTL = 2 * cfg.Token.RefreshTTL
+	cfg.Token.Leeway = -time.Second
+	cfg.Token.ImpersonationTTL = 0
+	cfg.Token.Clients = map[string]config.TokenClient{"mobile": {RefreshTTL: time.Minute, MaxSession: -time.Hour}}
+	assert.EqualError(t, cfg.Validate(), "token.leeway must not be negative, got -1s\n"+
+		"token.impersonation_ttl must be positive, got 0s\n"+
+		"token.access_ttl must not exceed token.refresh_ttl\n"+
+		"token.clients.mobile.max_session must not be negative, got -1h0m0s\n"+
+		"token.clients.mobile.access_ttl must not exceed the refresh TTL of the client")
+
+	cfg = config.Default()
+	cfg.Vault.Token = "token"
+	cfg.Token.Clients = map[string]config.TokenClient{"mobile": {RefreshTTL: 30 * 24 * time.Hour, MaxSession: 90 * 24 * time.Hour}}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoad_TokenClients(t *testing.T) {
+	cfg, err := config.Load([]string{"-config", writeFile(t, "token:\n  clients:\n    mobile:\n      refresh_ttl: 720h\n"), "-token.access-ttl", "5m"},
+		env(map[string]string{"AUTH_VAULT_TOKEN": "token"}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.Token.AccessTTL)
+	assert.Equal(t, map[string]config.TokenClient{"mobile": {RefreshTTL: 720 * time.Hour}}, cfg.Token.Clients)
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts the name of every environment variable read by Load.
+const EnvPrefix = "AUTH_"
+
+// DefaultFile is the configuration file read when none is named. Unlike a named file, it may be absent.
+const DefaultFile = "config/auth.yaml"
+
+// setting is a configurable field, named by its path of YAML keys such as "vault.token".
+type setting struct {
+	path  string
+	value reflect.Value
+}
+
+// env returns the environment variable of the setting, such as AUTH_VAULT_TOKEN.
+func (s setting) env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.path, ".", "_"))
+}
+
+// flag returns the flag of the setting, such as -vault.jwt-secret-path.
+func (s setting) flag() string {
+	return strings.ReplaceAll(s.path, "_", "-")
+}
+
+// Load builds the configuration from the defaults, the configuration file, environment variables and
+// command-line flags, each overriding the one before, and validates it.
+//
+// The file is named by the -config flag or the AUTH_CONFIG variable and defaults to DefaultFile. Every
+// setting except the tenants and the token clients can also be set by an environment variable and a
+// flag derived from its path in the file: vault.jwt_secret_path is read from AUTH_VAULT_JWT_SECRET_PATH and
+// -vault.jwt-secret-path. Lists are comma-separated and durations use Go syntax, such as "90s".
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	config := Default()
+	settings := collect(reflect.ValueOf(config).Elem(), "")
+
+	flags := flag.NewFlagSet("auth-service", flag.ContinueOnError)
+	file := flags.String("config", "", "configuration file, defaults to "+DefaultFile)
+	for _, s := range settings {
+		flags.String(s.flag(), "", "overrides "+s.path+" of the configuration file")
+	}
+	if err := flags.Parse(args); err != nil {
+		// The flag set already printed the error and its usage
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	path, required := *file, true
+	if path == "" {
+		path, required = lookupEnv(EnvPrefix + "CONFIG")
+	}
+	if path == "" {
+		path, required = DefaultFile, false
+	}
+	if err := loadFile(config, path, required); err != nil {
+		return nil, err
+	}
+
+	for _, s := range settings {
+		if value, ok := lookupEnv(s.env()); ok {
+			if err := set(s.value, value); err != nil {
+				return nil, fmt.Errorf("config: %s: %w", s.env(), err)
+			}
+		}
+	}
+
+	var flagErr error
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if f.Name == s.flag() && flagErr == nil {
+				if err := set(s.value, f.Value.String()); err != nil {
+					flagErr = fmt.Errorf("config: -%s: %w", f.Name, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("config: invalid configuration:\n%w", err)
+	}
+	return config, nil
+}
+
+// loadFile decodes the YAML file at path over the configuration. Unknown keys are rejected so that
+// misspelled settings do not go unnoticed.
+func loadFile(config *Config, path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !required && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("config: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+// collect lists the settings of a struct that can be set from a string, skipping maps.
+func collect(v reflect.Value, prefix string) []setting {
+	var settings []setting
+	for i := 0; i < v.NumField(); i++ {
+		key := strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			settings = append(settings, collect(field, prefix+key+".")...)
+		case reflect.String, reflect.Int, reflect.Int64, reflect.Float64, reflect.Bool, reflect.Slice:
+			settings = append(settings, setting{path: prefix + key, value: field})
+		}
+	}
+	return settings
+}
+
+// set parses the string into the field.
+func set(field reflect.Value, value string) error {
+	switch {
+	case field.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Int || field.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case field.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
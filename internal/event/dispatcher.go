@@ -0,0 +1,114 @@
+package event
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	internal_time "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/time"
+)
+
+// DefaultBatchSize is the number of events delivered per dispatch.
+const DefaultBatchSize = 100
+
+// RetryPolicy controls how failed deliveries are retried, doubling the delay after every attempt.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts before an event is given up on
+	BaseDelay   time.Duration // Delay after the first failed attempt
+	MaxDelay    time.Duration // Longest delay between attempts
+}
+
+// DefaultRetryPolicy returns a policy retrying for a little over half an hour.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 5 * time.Minute}
+}
+
+// Backoff returns how long to wait after the given number of failed attempts.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Dispatcher delivers the events in an outbox to a sink, retrying failed deliveries with backoff.
+type Dispatcher struct {
+	Outbox    IOutbox                  // Events waiting for delivery
+	Sink      ISink                    // Destination of the events
+	Time      internal_time.TimeSource // Source to get the current time
+	Retry     RetryPolicy              // How failed deliveries are retried
+	BatchSize int                      // Most events delivered per dispatch
+}
+
+// NewDispatcher initializes a new Dispatcher with necessary dependencies.
+func NewDispatcher(outbox IOutbox, sink ISink, time internal_time.TimeSource, retry RetryPolicy) *Dispatcher {
+	return &Dispatcher{
+		Outbox:    outbox,
+		Sink:      sink,
+		Time:      time,
+		Retry:     retry,
+		BatchSize: DefaultBatchSize,
+	}
+}
+
+// Dispatch delivers the events that are due, in the order they were published, and returns how many
+// were delivered. Failed events are rescheduled, or buried once they run out of attempts.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	now := d.Time.Now()
+	entries, err := d.Outbox.Due(ctx, now, d.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+
+		if err := d.Sink.Deliver(ctx, entry.Event); err != nil {
+			if err := d.fail(ctx, entry, err, now); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		if err := d.Outbox.Remove(ctx, entry.Event.ID); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// fail records a failed delivery, burying the entry when it has no attempts left.
+func (d *Dispatcher) fail(ctx context.Context, entry *Entry, deliveryErr error, now time.Time) error {
+	entry.Attempts++
+	entry.LastError = deliveryErr.Error()
+	if entry.Attempts >= d.Retry.MaxAttempts {
+		slog.ErrorContext(ctx, "Giving up on event", "type", entry.Event.Type, "event_id", entry.Event.ID, "attempts", entry.Attempts, "error", deliveryErr)
+		return d.Outbox.Bury(ctx, entry)
+	}
+	entry.NextAttempt = now.Add(d.Retry.Backoff(entry.Attempts))
+	return d.Outbox.Update(ctx, entry)
+}
+
+// Run dispatches due events every interval until the context is done.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.Dispatch(ctx); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "Dispatching domain events failed", "error", err)
+			}
+		}
+	}
+}
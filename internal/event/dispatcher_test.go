@@ -0,0 +1,88 @@
+package event_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSink struct {
+	mock.Mock
+}
+
+func (m *MockSink) Deliver(ctx context.Context, e *event.Event) error {
+	args := m.Called(ctx, e)
+	return args.Error(0)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := event.RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 8*time.Second, policy.Backoff(4))
+	assert.Equal(t, 10*time.Second, policy.Backoff(5))
+	assert.Equal(t, 10*time.Second, policy.Backoff(50))
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	outbox := newOutbox(t)
+	sink := new(MockSink)
+	clock := &MockTimeSource{now: start}
+	dispatcher := event.NewDispatcher(outbox, sink, clock, event.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour})
+
+	assert.NoError(t, outbox.Add(context.TODO(), &event.Event{ID: "ok", Time: start}))
+	assert.NoError(t, outbox.Add(context.TODO(), &event.Event{ID: "flaky", Time: start.Add(time.Second)}))
+	sink.On("Deliver", mock.Anything, mock.MatchedBy(func(e *event.Event) bool { return e.ID == "ok" })).Return(nil).Once()
+	sink.On("Deliver", mock.Anything, mock.MatchedBy(func(e *event.Event) bool { return e.ID == "flaky" })).Return(errors.New("webhook down")).Once()
+
+	delivered, err := dispatcher.Dispatch(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	// The failed event waits for its backoff before it is retried
+	entries, _ := outbox.Due(context.TODO(), start.Add(time.Hour), 0)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 1, entries[0].Attempts)
+	assert.Equal(t, start.Add(time.Minute), entries[0].NextAttempt)
+	assert.Equal(t, "webhook down", entries[0].LastError)
+
+	delivered, err = dispatcher.Dispatch(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	clock.now = start.Add(time.Minute)
+	sink.On("Deliver", mock.Anything, mock.Anything).Return(nil).Once()
+	delivered, err = dispatcher.Dispatch(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	entries, _ = outbox.Due(context.TODO(), start.Add(time.Hour), 0)
+	assert.Empty(t, entries)
+	sink.AssertExpectations(t)
+}
+
+func TestDispatcher_GivesUp(t *testing.T) {
+	outbox := newOutbox(t)
+	sink := new(MockSink)
+	clock := &MockTimeSource{now: start}
+	dispatcher := event.NewDispatcher(outbox, sink, clock, event.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Hour})
+
+	assert.NoError(t, outbox.Add(context.TODO(), &event.Event{ID: "doomed", Time: start}))
+	sink.On("Deliver", mock.Anything, mock.Anything).Return(errors.New("webhook down"))
+
+	for i := 0; i < 2; i++ {
+		_, err := dispatcher.Dispatch(context.TODO())
+		assert.NoError(t, err)
+		clock.now = clock.now.Add(time.Hour)
+	}
+
+	entries, _ := outbox.Due(context.TODO(), clock.now, 0)
+	assert.Empty(t, entries)
+	sink.AssertNumberOfCalls(t, "Deliver", 2)
+}
@@ -0,0 +1,89 @@
+package event
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	internal_time "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/time"
+)
+
+// Type names what happened to the user.
+type Type string
+
+// Domain events emitted by the service for other services to react to.
+const (
+	UserRegistered Type = "user.registered"
+	UserLoggedIn   Type = "user.logged_in"
+)
+
+// Event is a domain event, delivered at least once. Consumers should deduplicate on ID.
+type Event struct {
+	ID     string            `json:"id"`
+	Type   Type              `json:"type"`
+	Time   time.Time         `json:"time"`
+	Tenant string            `json:"tenant,omitempty"`
+	UserID string            `json:"user_id"`
+	Data   map[string]string `json:"data,omitempty"`
+}
+
+// IPublisher defines how domain events leave the service.
+type IPublisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// ISink defines a destination events are delivered to.
+type ISink interface {
+	Deliver(ctx context.Context, event *Event) error
+}
+
+// OutboxPublisher publishes events by storing them in an outbox, from which a Dispatcher delivers them.
+// Publishing never waits on the network, and events survive a restart until they are delivered.
+type OutboxPublisher struct {
+	Outbox IOutbox                  // Stores events until they are delivered
+	Time   internal_time.TimeSource // Source to get the current time
+}
+
+// NewOutboxPublisher initializes a new OutboxPublisher with necessary dependencies.
+func NewOutboxPublisher(outbox IOutbox, time internal_time.TimeSource) *OutboxPublisher {
+	return &OutboxPublisher{Outbox: outbox, Time: time}
+}
+
+// Publish implements IPublisher. The event is given an ID and time unless it already has them.
+func (p *OutboxPublisher) Publish(ctx context.Context, event *Event) error {
+	if event.ID == "" {
+		id, err := newEventID()
+		if err != nil {
+			return err
+		}
+		event.ID = id
+	}
+	if event.Time.IsZero() {
+		event.Time = p.Time.Now()
+	}
+	return p.Outbox.Add(ctx, event)
+}
+
+// NopPublisher discards every event. It is used when no sink is configured.
+type NopPublisher struct{}
+
+// Publish implements IPublisher.
+func (NopPublisher) Publish(ctx context.Context, event *Event) error {
+	return nil
+}
+
+// newEventID generates a random 128-bit event identifier.
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Ensure publishers implement IPublisher.
+var (
+	_ IPublisher = (*OutboxPublisher)(nil)
+	_ IPublisher = NopPublisher{}
+)
@@ -0,0 +1,166 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry is an event waiting in the outbox, together with its delivery state.
+type Entry struct {
+	Event       *Event    `json:"event"`
+	Attempts    int       `json:"attempts"`               // Failed deliveries so far
+	NextAttempt time.Time `json:"next_attempt,omitempty"` // The event is not due before this time
+	LastError   string    `json:"last_error,omitempty"`   // Why the last delivery failed
+}
+
+// IOutbox defines storage for events that have yet to be delivered.
+type IOutbox interface {
+	// Add stores a new event for delivery.
+	Add(ctx context.Context, event *Event) error
+	// Due returns up to limit entries due at now, oldest event first.
+	Due(ctx context.Context, now time.Time, limit int) ([]*Entry, error)
+	// Update stores the delivery state of an entry after a failed attempt.
+	Update(ctx context.Context, entry *Entry) error
+	// Remove deletes a delivered entry.
+	Remove(ctx context.Context, id string) error
+	// Bury moves an entry that will not be retried out of the outbox, keeping it for inspection.
+	Bury(ctx context.Context, entry *Entry) error
+}
+
+// FileOutbox keeps each pending event in its own file under Dir/pending, and events that exhausted
+// their retries under Dir/dead. Files are replaced atomically, so a crash never leaves a torn entry.
+type FileOutbox struct {
+	Dir string
+}
+
+// NewFileOutbox initializes a new FileOutbox in dir, creating the directories it needs.
+func NewFileOutbox(dir string) (*FileOutbox, error) {
+	outbox := &FileOutbox{Dir: dir}
+	for _, sub := range []string{outbox.pendingDir(), outbox.deadDir()} {
+		if err := os.MkdirAll(sub, 0o700); err != nil {
+			return nil, err
+		}
+	}
+	return outbox, nil
+}
+
+// Add implements IOutbox.
+func (o *FileOutbox) Add(ctx context.Context, event *Event) error {
+	return writeEntry(o.pendingDir(), &Entry{Event: event})
+}
+
+// Due implements IOutbox. Files that cannot be read as entries are skipped.
+func (o *FileOutbox) Due(ctx context.Context, now time.Time, limit int) ([]*Entry, error) {
+	files, err := os.ReadDir(o.pendingDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		entry, err := readEntry(filepath.Join(o.pendingDir(), file.Name()))
+		if err != nil {
+			continue
+		}
+		if !entry.NextAttempt.After(now) {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Event.Time.Before(entries[j].Event.Time)
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// Update implements IOutbox.
+func (o *FileOutbox) Update(ctx context.Context, entry *Entry) error {
+	return writeEntry(o.pendingDir(), entry)
+}
+
+// Remove implements IOutbox. Removing an entry that is already gone is not an error.
+func (o *FileOutbox) Remove(ctx context.Context, id string) error {
+	err := os.Remove(entryPath(o.pendingDir(), id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Bury implements IOutbox.
+func (o *FileOutbox) Bury(ctx context.Context, entry *Entry) error {
+	if err := writeEntry(o.deadDir(), entry); err != nil {
+		return err
+	}
+	return o.Remove(ctx, entry.Event.ID)
+}
+
+func (o *FileOutbox) pendingDir() string {
+	return filepath.Join(o.Dir, "pending")
+}
+
+func (o *FileOutbox) deadDir() string {
+	return filepath.Join(o.Dir, "dead")
+}
+
+// entryPath returns the file of an event. IDs are generated hex strings; the base name guards
+// against anything else escaping the directory.
+func entryPath(dir string, id string) string {
+	return filepath.Join(dir, filepath.Base(id)+".json")
+}
+
+func readEntry(path string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entry := &Entry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, err
+	}
+	if entry.Event == nil || entry.Event.ID == "" {
+		return nil, errors.New("outbox entry has no event")
+	}
+	return entry, nil
+}
+
+// writeEntry writes the entry to a temporary file, syncs it and renames it into place.
+func writeEntry(dir string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), entryPath(dir, entry.Event.ID))
+}
+
+// Ensure FileOutbox implements IOutbox.
+var _ IOutbox = (*FileOutbox)(nil)
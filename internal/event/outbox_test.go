@@ -0,0 +1,101 @@
+package event_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/event"
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+
+type MockTimeSource struct {
+	now time.Time
+}
+
+func (m *MockTimeSource) Now() time.Time {
+	return m.now
+}
+
+func newOutbox(t *testing.T) *event.FileOutbox {
+	outbox, err := event.NewFileOutbox(filepath.Join(t.TempDir(), "outbox"))
+	assert.NoError(t, err)
+	return outbox
+}
+
+func TestFileOutbox_Due(t *testing.T) {
+	outbox := newOutbox(t)
+	assert.NoError(t, outbox.Add(context.TODO(), &event.Event{ID: "b", Time: start.Add(time.Minute)}))
+	assert.NoError(t, outbox.Add(context.TODO(), &event.Event{ID: "a", Time: start}))
+	assert.NoError(t, outbox.Add(context.TODO(), &event.Event{ID: "c", Time: start.Add(2 * time.Minute)}))
+	assert.NoError(t, outbox.Update(context.TODO(), &event.Entry{Event: &event.Event{ID: "c", Time: start.Add(2 * time.Minute)}, Attempts: 1, NextAttempt: start.Add(time.Hour)}))
+
+	entries, err := outbox.Due(context.TODO(), start, 0)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "a", entries[0].Event.ID)
+	assert.Equal(t, "b", entries[1].Event.ID)
+
+	entries, err = outbox.Due(context.TODO(), start.Add(time.Hour), 1)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "a", entries[0].Event.ID)
+
+	assert.NoError(t, outbox.Remove(context.TODO(), "a"))
+	assert.NoError(t, outbox.Remove(context.TODO(), "a"))
+	entries, err = outbox.Due(context.TODO(), start.Add(time.Hour), 0)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, 1, entries[1].Attempts)
+}
+
+func TestFileOutbox_SurvivesRestart(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	outbox, err := event.NewFileOutbox(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, outbox.Add(context.TODO(), &event.Event{ID: "a", Type: event.UserRegistered, UserID: "user", Time: start}))
+	// A torn write left behind by a crash is ignored
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pending", "broken.json"), []byte(`{"event":`), 0o600))
+
+	reopened, err := event.NewFileOutbox(dir)
+	assert.NoError(t, err)
+	entries, err := reopened.Due(context.TODO(), start, 0)
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, &event.Event{ID: "a", Type: event.UserRegistered, UserID: "user", Time: start}, entries[0].Event)
+}
+
+func TestFileOutbox_Bury(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	outbox, err := event.NewFileOutbox(dir)
+	assert.NoError(t, err)
+	entry := &event.Entry{Event: &event.Event{ID: "a", Time: start}, Attempts: 3, LastError: "webhook down"}
+	assert.NoError(t, outbox.Add(context.TODO(), entry.Event))
+
+	assert.NoError(t, outbox.Bury(context.TODO(), entry))
+
+	entries, err := outbox.Due(context.TODO(), start, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.FileExists(t, filepath.Join(dir, "dead", "a.json"))
+}
+
+func TestOutboxPublisher_Publish(t *testing.T) {
+	outbox := newOutbox(t)
+	publisher := event.NewOutboxPublisher(outbox, &MockTimeSource{now: start})
+	published := &event.Event{Type: event.UserLoggedIn, UserID: "user"}
+
+	assert.NoError(t, publisher.Publish(context.TODO(), published))
+
+	assert.Len(t, published.ID, 32)
+	assert.Equal(t, start, published.Time)
+	entries, err := outbox.Due(context.TODO(), start, 0)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, published.ID, entries[0].Event.ID)
+}
@@ -0,0 +1,87 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	internal_time "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/time"
+)
+
+// Headers sent with every webhook request.
+const (
+	SignatureHeader = "X-BitBridge-Signature" // "sha256=" followed by the hex HMAC of the timestamp, a '.' and the body
+	TimestampHeader = "X-BitBridge-Timestamp" // Unix time the request was signed at
+	EventIDHeader   = "X-BitBridge-Event-ID"
+	EventTypeHeader = "X-BitBridge-Event-Type"
+)
+
+// WebhookSink delivers each event as a signed JSON POST request. Receivers verify the signature with
+// the shared secret and should reject stale timestamps to prevent replays.
+type WebhookSink struct {
+	URL    string                   // Endpoint the events are posted to
+	Secret []byte                   // Key the payloads are signed with
+	Client *http.Client             // Client sending the requests
+	Time   internal_time.TimeSource // Source to get the current time
+}
+
+// NewWebhookSink initializes a new WebhookSink with necessary dependencies.
+func NewWebhookSink(url string, secret []byte, client *http.Client, time internal_time.TimeSource) *WebhookSink {
+	return &WebhookSink{URL: url, Secret: secret, Client: client, Time: time}
+}
+
+// Deliver implements ISink. Any response other than 2xx counts as a failed delivery.
+func (w *WebhookSink) Deliver(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(w.Time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(w.Secret, timestamp, body))
+	req.Header.Set(EventIDHeader, event.ID)
+	req.Header.Set(EventTypeHeader, string(event.Type))
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for a payload sent at the given timestamp.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the valid signature of a payload sent at the given timestamp.
+func Verify(secret []byte, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Ensure WebhookSink implements ISink.
+var _ ISink = (*WebhookSink)(nil)
@@ -0,0 +1,103 @@
+package event_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/event"
+	"github.com/stretchr/testify/assert"
+)
+
+var secret = []byte("webhook-secret")
+
+func TestWebhookSink_Deliver(t *testing.T) {
+	var received *event.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !event.Verify(secret, r.Header.Get(event.TimestampHeader), body, r.Header.Get(event.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "1698840000", r.Header.Get(event.TimestampHeader))
+		assert.Equal(t, "event", r.Header.Get(event.EventIDHeader))
+		assert.Equal(t, "user.registered", r.Header.Get(event.EventTypeHeader))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		received = &event.Event{}
+		assert.NoError(t, json.Unmarshal(body, received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := event.NewWebhookSink(server.URL, secret, server.Client(), &MockTimeSource{now: start})
+	sent := &event.Event{ID: "event", Type: event.UserRegistered, Time: start, UserID: "user", Data: map[string]string{"username": "user"}}
+
+	assert.NoError(t, sink.Deliver(context.TODO(), sent))
+	assert.Equal(t, sent, received)
+}
+
+func TestWebhookSink_WrongSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !event.Verify(secret, r.Header.Get(event.TimestampHeader), body, r.Header.Get(event.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink := event.NewWebhookSink(server.URL, []byte("other-secret"), server.Client(), &MockTimeSource{now: start})
+
+	err := sink.Deliver(context.TODO(), &event.Event{ID: "event", Time: start})
+	assert.EqualError(t, err, "webhook responded with status 401")
+}
+
+func TestWebhookSink_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	sink := event.NewWebhookSink(server.URL, secret, http.DefaultClient, &MockTimeSource{now: start})
+
+	assert.Error(t, sink.Deliver(context.TODO(), &event.Event{ID: "event", Time: start}))
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"event"}`)
+	signature := event.Sign(secret, "1698840000", body)
+
+	assert.True(t, event.Verify(secret, "1698840000", body, signature))
+	assert.False(t, event.Verify(secret, "1698840001", body, signature))
+	assert.False(t, event.Verify(secret, "1698840000", []byte(`{"id":"other"}`), signature))
+}
+
+func TestDispatcher_Webhook(t *testing.T) {
+	var deliveries int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveries++
+		if deliveries == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	outbox := newOutbox(t)
+	clock := &MockTimeSource{now: start}
+	dispatcher := event.NewDispatcher(outbox, event.NewWebhookSink(server.URL, secret, server.Client(), clock), clock, event.DefaultRetryPolicy())
+	assert.NoError(t, event.NewOutboxPublisher(outbox, clock).Publish(context.TODO(), &event.Event{Type: event.UserLoggedIn, UserID: "user"}))
+
+	delivered, err := dispatcher.Dispatch(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	clock.now = start.Add(event.DefaultRetryPolicy().BaseDelay)
+	delivered, err = dispatcher.Dispatch(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 2, deliveries)
+}
@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/problem"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrorHandler renders every error returned by a route as an RFC 7807 problem document.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	p := problem.FromError(err)
+	if err := c.Status(p.Status).JSON(p); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, problem.ContentType)
+	return nil
+}
@@ -1,18 +1,26 @@
 package handler
 
 import (
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/apikey"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/audit"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/auth"
 	fiber_util "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/util"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/identity"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/validation"
 	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
 	"github.com/gofiber/fiber/v2"
 )
 
 type FiberServerHandler struct {
-	AuthService auth.IAuthService
+	AuthService    auth.IAuthService
+	SessionService session.ISessionService
+	APIKeyService  apikey.IAPIKeyService
+	AuditService   audit.IAuditService
 }
 
-func NewFiberServerHandler(authService auth.IAuthService) *FiberServerHandler {
-	return &FiberServerHandler{AuthService: authService}
+func NewFiberServerHandler(authService auth.IAuthService, sessionService session.ISessionService, apiKeyService apikey.IAPIKeyService, auditService audit.IAuditService) *FiberServerHandler {
+	return &FiberServerHandler{AuthService: authService, SessionService: sessionService, APIKeyService: apiKeyService, AuditService: auditService}
 }
 
 func (f *FiberServerHandler) Login(c fiber_util.FiberContext) error {
@@ -21,6 +29,11 @@ func (f *FiberServerHandler) Login(c fiber_util.FiberContext) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
 	}
 
+	validation.NormalizeLogin(&loginModel)
+	if err := validation.ValidateLogin(&loginModel); err != nil {
+		return err
+	}
+
 	token, err := f.AuthService.Login(c.Context(), &loginModel)
 	if err != nil {
 		return err
@@ -35,6 +48,11 @@ func (f *FiberServerHandler) Register(c fiber_util.FiberContext) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
 	}
 
+	validation.NormalizeRegister(&registerModel)
+	if err := validation.ValidateRegister(&registerModel); err != nil {
+		return err
+	}
+
 	token, err := f.AuthService.Register(c.Context(), &registerModel)
 	if err != nil {
 		return err
@@ -42,3 +60,186 @@ func (f *FiberServerHandler) Register(c fiber_util.FiberContext) error {
 
 	return c.JSON(token)
 }
+
+func (f *FiberServerHandler) Refresh(c fiber_util.FiberContext) error {
+	refreshModel := public_model.TokenRefreshModel{}
+	if err := c.BodyParser(&refreshModel); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := validation.ValidateRefresh(&refreshModel); err != nil {
+		return err
+	}
+
+	token, err := f.AuthService.Refresh(c.Context(), &refreshModel)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(token)
+}
+
+func (f *FiberServerHandler) ListSessions(c fiber_util.FiberContext) error {
+	claims, err := identity.RequireClaims(c.Context())
+	if err != nil {
+		return err
+	}
+
+	sessions, err := f.SessionService.List(c.Context(), claims.Tenant, claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	sessionModels := make([]*public_model.SessionModel, 0, len(sessions))
+	for _, s := range sessions {
+		sessionModels = append(sessionModels, s.ToSessionModel(claims.SessionID))
+	}
+
+	return c.JSON(sessionModels)
+}
+
+func (f *FiberServerHandler) RevokeSession(c fiber_util.FiberContext) error {
+	claims, err := identity.RequireClaims(c.Context())
+	if err != nil {
+		return err
+	}
+
+	if err := f.SessionService.Revoke(c.Context(), claims.Tenant, claims.UserID, c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (f *FiberServerHandler) RevokeAllSessions(c fiber_util.FiberContext) error {
+	claims, err := identity.RequireClaims(c.Context())
+	if err != nil {
+		return err
+	}
+
+	if err := f.SessionService.RevokeAll(c.Context(), claims.Tenant, claims.UserID); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (f *FiberServerHandler) CreateAPIKey(c fiber_util.FiberContext) error {
+	claims, err := identity.RequireClaims(c.Context())
+	if err != nil {
+		return err
+	}
+
+	createModel := public_model.CreateAPIKeyModel{}
+	if err := c.BodyParser(&createModel); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := validation.ValidateCreateAPIKey(&createModel); err != nil {
+		return err
+	}
+
+	created, err := f.APIKeyService.Create(c.Context(), claims, &createModel)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(created)
+}
+
+func (f *FiberServerHandler) ListAPIKeys(c fiber_util.FiberContext) error {
+	claims, err := identity.RequireClaims(c.Context())
+	if err != nil {
+		return err
+	}
+
+	apiKeys, err := f.APIKeyService.List(c.Context(), claims)
+	if err != nil {
+		return err
+	}
+
+	apiKeyModels := make([]*public_model.APIKeyModel, 0, len(apiKeys))
+	for _, k := range apiKeys {
+		apiKeyModels = append(apiKeyModels, k.ToAPIKeyModel())
+	}
+
+	return c.JSON(apiKeyModels)
+}
+
+func (f *FiberServerHandler) RevokeAPIKey(c fiber_util.FiberContext) error {
+	claims, err := identity.RequireClaims(c.Context())
+	if err != nil {
+		return err
+	}
+
+	if err := f.APIKeyService.Revoke(c.Context(), claims, c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (f *FiberServerHandler) ExchangeAPIKey(c fiber_util.FiberContext) error {
+	exchangeModel := public_model.APIKeyExchangeModel{}
+	if err := c.BodyParser(&exchangeModel); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := validation.ValidateAPIKeyExchange(&exchangeModel); err != nil {
+		return err
+	}
+
+	token, err := f.APIKeyService.Exchange(c.Context(), exchangeModel.Key)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(token)
+}
+
+func (f *FiberServerHandler) Impersonate(c fiber_util.FiberContext) error {
+	claims, err := identity.RequireClaims(c.Context())
+	if err != nil {
+		return err
+	}
+
+	impersonateModel := public_model.ImpersonateModel{}
+	if err := c.BodyParser(&impersonateModel); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	validation.NormalizeImpersonate(&impersonateModel)
+	if err := validation.ValidateImpersonate(&impersonateModel); err != nil {
+		return err
+	}
+
+	token, err := f.AuthService.Impersonate(c.Context(), claims, &impersonateModel)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(token)
+}
+
+func (f *FiberServerHandler) ListAuditEvents(c fiber_util.FiberContext) error {
+	claims, err := identity.RequireClaims(c.Context())
+	if err != nil {
+		return err
+	}
+
+	queryModel := public_model.AuditQueryModel{}
+	if err := c.QueryParser(&queryModel); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query")
+	}
+
+	if err := validation.ValidateAuditQuery(&queryModel); err != nil {
+		return err
+	}
+
+	events, err := f.AuditService.List(c.Context(), claims, &queryModel)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(events)
+}
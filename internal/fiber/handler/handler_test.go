@@ -2,12 +2,22 @@ package handler_test
 
 import (
 	"context"
+	"encoding/json"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/apikey"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/audit"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/auth"
 	fiber_handler "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/handler"
 	fiber_util "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/util"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/identity"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/problem"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/validation"
 	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -28,6 +38,18 @@ func (m *MockAuthService) Register(ctx context.Context, registerModel *public_mo
 	return args.Get(0).(*public_model.TokenModel), args.Error(1)
 }
 
+// Refresh implements service.IAuthService.
+func (m *MockAuthService) Refresh(ctx context.Context, refreshModel *public_model.TokenRefreshModel) (*public_model.TokenModel, error) {
+	args := m.Called(ctx, refreshModel)
+	return args.Get(0).(*public_model.TokenModel), args.Error(1)
+}
+
+// Impersonate implements service.IAuthService.
+func (m *MockAuthService) Impersonate(ctx context.Context, actor *public_model.CustomClaims, impersonateModel *public_model.ImpersonateModel) (*public_model.AccessTokenModel, error) {
+	args := m.Called(ctx, actor, impersonateModel)
+	return args.Get(0).(*public_model.AccessTokenModel), args.Error(1)
+}
+
 // Ensure that MockAuthService implements IAuthService
 var _ auth.IAuthService = &MockAuthService{}
 
@@ -41,6 +63,12 @@ func (m *MockFiberContext) BodyParser(v interface{}) error {
 	return args.Error(0)
 }
 
+// QueryParser implements fiberserver.FiberContext.
+func (m *MockFiberContext) QueryParser(v interface{}) error {
+	args := m.Called(v)
+	return args.Error(0)
+}
+
 // Context implements fiberserver.FiberContext.
 func (m *MockFiberContext) Context() context.Context {
 	args := m.Called()
@@ -53,9 +81,98 @@ func (m *MockFiberContext) JSON(v interface{}) error {
 	return args.Error(0)
 }
 
+// Params implements fiberserver.FiberContext.
+func (m *MockFiberContext) Params(key string, defaultValue ...string) string {
+	args := m.Called(key)
+	return args.String(0)
+}
+
+// SendStatus implements fiberserver.FiberContext.
+func (m *MockFiberContext) SendStatus(status int) error {
+	args := m.Called(status)
+	return args.Error(0)
+}
+
+// Status implements fiberserver.FiberContext.
+func (m *MockFiberContext) Status(status int) fiber_util.FiberContext {
+	m.Called(status)
+	return m
+}
+
 // Ensure that MockFiberContext implements FiberContext
 var _ fiber_util.FiberContext = &MockFiberContext{}
 
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) Create(ctx context.Context, tenantID string, userID string) (*session.Session, error) {
+	args := m.Called(ctx, tenantID, userID)
+	return args.Get(0).(*session.Session), args.Error(1)
+}
+
+func (m *MockSessionService) Refresh(ctx context.Context, tenantID string, userID string, sessionID string, refreshTokenID string) (*session.Session, error) {
+	args := m.Called(ctx, tenantID, userID, sessionID, refreshTokenID)
+	return args.Get(0).(*session.Session), args.Error(1)
+}
+
+func (m *MockSessionService) List(ctx context.Context, tenantID string, userID string) ([]*session.Session, error) {
+	args := m.Called(ctx, tenantID, userID)
+	return args.Get(0).([]*session.Session), args.Error(1)
+}
+
+func (m *MockSessionService) Revoke(ctx context.Context, tenantID string, userID string, sessionID string) error {
+	args := m.Called(ctx, tenantID, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) RevokeAll(ctx context.Context, tenantID string, userID string) error {
+	args := m.Called(ctx, tenantID, userID)
+	return args.Error(0)
+}
+
+// Ensure that MockSessionService implements ISessionService
+var _ session.ISessionService = &MockSessionService{}
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) Create(ctx context.Context, owner *public_model.CustomClaims, createModel *public_model.CreateAPIKeyModel) (*public_model.CreatedAPIKeyModel, error) {
+	args := m.Called(ctx, owner, createModel)
+	return args.Get(0).(*public_model.CreatedAPIKeyModel), args.Error(1)
+}
+
+func (m *MockAPIKeyService) List(ctx context.Context, owner *public_model.CustomClaims) ([]*apikey.APIKey, error) {
+	args := m.Called(ctx, owner)
+	return args.Get(0).([]*apikey.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) Revoke(ctx context.Context, owner *public_model.CustomClaims, keyID string) error {
+	args := m.Called(ctx, owner, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) Exchange(ctx context.Context, key string) (*public_model.AccessTokenModel, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(*public_model.AccessTokenModel), args.Error(1)
+}
+
+// Ensure that MockAPIKeyService implements IAPIKeyService
+var _ apikey.IAPIKeyService = &MockAPIKeyService{}
+
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) List(ctx context.Context, caller *public_model.CustomClaims, query *public_model.AuditQueryModel) ([]*public_model.AuditEventModel, error) {
+	args := m.Called(ctx, caller, query)
+	return args.Get(0).([]*public_model.AuditEventModel), args.Error(1)
+}
+
+// Ensure that MockAuditService implements IAuditService
+var _ audit.IAuditService = &MockAuditService{}
+
 type MockFiberCtx struct {
 	mock.Mock
 }
@@ -75,17 +192,32 @@ func (m *MockFiberCtx) Context() context.Context {
 	return args.Get(0).(context.Context)
 }
 
+// fillLoginModel populates the parsed body with a valid login request
+func fillLoginModel(args mock.Arguments) {
+	loginModel := args.Get(0).(*public_model.LoginModel)
+	loginModel.Email = "test@test.com"
+	loginModel.Password = "password"
+}
+
+// fillRegisterModel populates the parsed body with a valid register request
+func fillRegisterModel(args mock.Arguments) {
+	registerModel := args.Get(0).(*public_model.RegisterModel)
+	registerModel.Email = "test@test.com"
+	registerModel.Username = "test"
+	registerModel.Password = "password"
+}
+
 func TestLogin_Success(t *testing.T) {
 	// Arrange
 	mockFiberContext := new(MockFiberContext)
 	mockAuthService := new(MockAuthService)
 
-	mockFiberContext.On("BodyParser", mock.Anything).Return(nil)
+	mockFiberContext.On("BodyParser", mock.Anything).Run(fillLoginModel).Return(nil)
 	mockFiberContext.On("Context").Return(context.Background())
 	mockFiberContext.On("JSON", mock.Anything).Return(nil)
 	mockAuthService.On("Login", mock.Anything, mock.Anything).Return(&public_model.TokenModel{}, nil)
 
-	handler := fiber_handler.NewFiberServerHandler(mockAuthService)
+	handler := fiber_handler.NewFiberServerHandler(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService))
 
 	// Act
 	err := handler.Login(mockFiberContext)
@@ -102,11 +234,11 @@ func TestLogin_Error_BadRequest(t *testing.T) {
 	mockFiberContext := new(MockFiberContext)
 	mockAuthService := new(MockAuthService)
 
-	mockFiberContext.On("BodyParser", mock.Anything).Return(nil)
+	mockFiberContext.On("BodyParser", mock.Anything).Run(fillLoginModel).Return(nil)
 	mockFiberContext.On("Context").Return(context.Background())
 	mockAuthService.On("Login", mock.Anything, mock.Anything).Return((*public_model.TokenModel)(nil), assert.AnError)
 
-	handler := fiber_handler.NewFiberServerHandler(mockAuthService)
+	handler := fiber_handler.NewFiberServerHandler(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService))
 
 	// Act
 	err := handler.Login(mockFiberContext)
@@ -125,7 +257,7 @@ func TestLogin_Error_BodyParser(t *testing.T) {
 
 	mockFiberContext.On("BodyParser", mock.Anything).Return(assert.AnError)
 
-	handler := fiber_handler.NewFiberServerHandler(mockAuthService)
+	handler := fiber_handler.NewFiberServerHandler(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService))
 
 	// Act
 	err := handler.Login(mockFiberContext)
@@ -141,12 +273,12 @@ func TestRegister_Success(t *testing.T) {
 	mockFiberContext := new(MockFiberContext)
 	mockAuthService := new(MockAuthService)
 
-	mockFiberContext.On("BodyParser", mock.Anything).Return(nil)
+	mockFiberContext.On("BodyParser", mock.Anything).Run(fillRegisterModel).Return(nil)
 	mockFiberContext.On("Context").Return(context.Background())
 	mockFiberContext.On("JSON", mock.Anything).Return(nil)
 	mockAuthService.On("Register", mock.Anything, mock.Anything).Return(&public_model.TokenModel{}, nil)
 
-	handler := fiber_handler.NewFiberServerHandler(mockAuthService)
+	handler := fiber_handler.NewFiberServerHandler(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService))
 
 	// Act
 	err := handler.Register(mockFiberContext)
@@ -163,11 +295,11 @@ func TestRegister_Error_BadRequest(t *testing.T) {
 	mockFiberContext := new(MockFiberContext)
 	mockAuthService := new(MockAuthService)
 
-	mockFiberContext.On("BodyParser", mock.Anything).Return(nil)
+	mockFiberContext.On("BodyParser", mock.Anything).Run(fillRegisterModel).Return(nil)
 	mockFiberContext.On("Context").Return(context.Background())
 	mockAuthService.On("Register", mock.Anything, mock.Anything).Return((*public_model.TokenModel)(nil), assert.AnError)
 
-	handler := fiber_handler.NewFiberServerHandler(mockAuthService)
+	handler := fiber_handler.NewFiberServerHandler(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService))
 
 	// Act
 	err := handler.Register(mockFiberContext)
@@ -186,7 +318,7 @@ func TestRegister_Error_BodyParser(t *testing.T) {
 
 	mockFiberContext.On("BodyParser", mock.Anything).Return(assert.AnError)
 
-	handler := fiber_handler.NewFiberServerHandler(mockAuthService)
+	handler := fiber_handler.NewFiberServerHandler(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService))
 
 	// Act
 	err := handler.Register(mockFiberContext)
@@ -196,3 +328,392 @@ func TestRegister_Error_BodyParser(t *testing.T) {
 
 	mockFiberContext.AssertExpectations(t)
 }
+
+func TestLogin_Error_Validation(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockAuthService := new(MockAuthService)
+
+	mockFiberContext.On("BodyParser", mock.Anything).Return(nil)
+
+	handler := fiber_handler.NewFiberServerHandler(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService))
+
+	// Act
+	err := handler.Login(mockFiberContext)
+
+	// Assert
+	var validationErr *validation.Error
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Violations, 2)
+
+	mockFiberContext.AssertExpectations(t)
+	mockAuthService.AssertNotCalled(t, "Login", mock.Anything, mock.Anything)
+}
+
+func TestRegister_Error_Validation(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockAuthService := new(MockAuthService)
+
+	mockFiberContext.On("BodyParser", mock.Anything).Run(func(args mock.Arguments) {
+		registerModel := args.Get(0).(*public_model.RegisterModel)
+		registerModel.Email = "not-an-email"
+		registerModel.Username = "test"
+		registerModel.Password = "password"
+	}).Return(nil)
+
+	handler := fiber_handler.NewFiberServerHandler(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService))
+
+	// Act
+	err := handler.Register(mockFiberContext)
+
+	// Assert
+	var validationErr *validation.Error
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []validation.FieldViolation{{Field: "email", Description: "must be a valid email address"}}, validationErr.Violations)
+
+	mockFiberContext.AssertExpectations(t)
+	mockAuthService.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+}
+
+func TestRefresh_Success(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockAuthService := new(MockAuthService)
+
+	mockFiberContext.On("BodyParser", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*public_model.TokenRefreshModel).Token = "refresh_token"
+	}).Return(nil)
+	mockFiberContext.On("Context").Return(context.Background())
+	mockFiberContext.On("JSON", mock.Anything).Return(nil)
+	mockAuthService.On("Refresh", mock.Anything, &public_model.TokenRefreshModel{Token: "refresh_token"}).Return(&public_model.TokenModel{}, nil)
+
+	handler := fiber_handler.NewFiberServerHandler(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService))
+
+	// Act
+	err := handler.Refresh(mockFiberContext)
+
+	// Assert
+	assert.Nil(t, err)
+
+	mockFiberContext.AssertExpectations(t)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestRefresh_Error_Validation(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockAuthService := new(MockAuthService)
+
+	mockFiberContext.On("BodyParser", mock.Anything).Return(nil)
+
+	handler := fiber_handler.NewFiberServerHandler(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService))
+
+	// Act
+	err := handler.Refresh(mockFiberContext)
+
+	// Assert
+	var validationErr *validation.Error
+	assert.ErrorAs(t, err, &validationErr)
+
+	mockAuthService.AssertNotCalled(t, "Refresh", mock.Anything, mock.Anything)
+}
+
+func TestListSessions_Success(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockSessionService := new(MockSessionService)
+	ctx := identity.WithClaims(context.Background(), &public_model.CustomClaims{UserID: "user", Tenant: "acme", SessionID: "current"})
+
+	mockFiberContext.On("Context").Return(ctx)
+	mockSessionService.On("List", mock.Anything, "acme", "user").Return([]*session.Session{{ID: "current"}, {ID: "other"}}, nil)
+	mockFiberContext.On("JSON", []*public_model.SessionModel{{ID: "current", Current: true}, {ID: "other"}}).Return(nil)
+
+	handler := fiber_handler.NewFiberServerHandler(new(MockAuthService), mockSessionService, new(MockAPIKeyService), new(MockAuditService))
+
+	// Act
+	err := handler.ListSessions(mockFiberContext)
+
+	// Assert
+	assert.Nil(t, err)
+
+	mockFiberContext.AssertExpectations(t)
+	mockSessionService.AssertExpectations(t)
+}
+
+func TestListSessions_Error_Unauthenticated(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockSessionService := new(MockSessionService)
+
+	mockFiberContext.On("Context").Return(context.Background())
+
+	handler := fiber_handler.NewFiberServerHandler(new(MockAuthService), mockSessionService, new(MockAPIKeyService), new(MockAuditService))
+
+	// Act
+	err := handler.ListSessions(mockFiberContext)
+
+	// Assert
+	assert.Equal(t, problem.Unauthenticated, problem.FromError(err).Code)
+
+	mockSessionService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestRevokeSession_Success(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockSessionService := new(MockSessionService)
+	ctx := identity.WithClaims(context.Background(), &public_model.CustomClaims{UserID: "user", Tenant: "acme"})
+
+	mockFiberContext.On("Context").Return(ctx)
+	mockFiberContext.On("Params", "id").Return("session")
+	mockFiberContext.On("SendStatus", fiber.StatusNoContent).Return(nil)
+	mockSessionService.On("Revoke", mock.Anything, "acme", "user", "session").Return(nil)
+
+	handler := fiber_handler.NewFiberServerHandler(new(MockAuthService), mockSessionService, new(MockAPIKeyService), new(MockAuditService))
+
+	// Act
+	err := handler.RevokeSession(mockFiberContext)
+
+	// Assert
+	assert.Nil(t, err)
+
+	mockFiberContext.AssertExpectations(t)
+	mockSessionService.AssertExpectations(t)
+}
+
+func TestRevokeSession_Error_NotFound(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockSessionService := new(MockSessionService)
+	ctx := identity.WithClaims(context.Background(), &public_model.CustomClaims{UserID: "user", Tenant: "acme"})
+
+	mockFiberContext.On("Context").Return(ctx)
+	mockFiberContext.On("Params", "id").Return("session")
+	mockSessionService.On("Revoke", mock.Anything, "acme", "user", "session").Return(common_error.NewServiceError(common_error.NotFound, "Session not found", nil))
+
+	handler := fiber_handler.NewFiberServerHandler(new(MockAuthService), mockSessionService, new(MockAPIKeyService), new(MockAuditService))
+
+	// Act
+	err := handler.RevokeSession(mockFiberContext)
+
+	// Assert
+	assert.NotNil(t, err)
+
+	mockFiberContext.AssertExpectations(t)
+	mockSessionService.AssertExpectations(t)
+}
+
+func TestRevokeAllSessions_Success(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockSessionService := new(MockSessionService)
+	ctx := identity.WithClaims(context.Background(), &public_model.CustomClaims{UserID: "user", Tenant: "acme"})
+
+	mockFiberContext.On("Context").Return(ctx)
+	mockFiberContext.On("SendStatus", fiber.StatusNoContent).Return(nil)
+	mockSessionService.On("RevokeAll", mock.Anything, "acme", "user").Return(nil)
+
+	handler := fiber_handler.NewFiberServerHandler(new(MockAuthService), mockSessionService, new(MockAPIKeyService), new(MockAuditService))
+
+	// Act
+	err := handler.RevokeAllSessions(mockFiberContext)
+
+	// Assert
+	assert.Nil(t, err)
+
+	mockFiberContext.AssertExpectations(t)
+	mockSessionService.AssertExpectations(t)
+}
+
+func TestErrorHandler_Validation(t *testing.T) {
+	// Arrange
+	app := fiber.New(fiber.Config{ErrorHandler: fiber_handler.ErrorHandler})
+	app.Post("/", func(c *fiber.Ctx) error {
+		return &validation.Error{Violations: []validation.FieldViolation{{Field: "email", Description: "must not be empty"}}}
+	})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/", nil))
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, problem.ContentType, resp.Header.Get(fiber.HeaderContentType))
+
+	var p problem.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
+	assert.Equal(t, problem.ValidationFailed, p.Code)
+	assert.Equal(t, fiber.StatusBadRequest, p.Status)
+	assert.Equal(t, "email", p.InvalidParams[0].Field)
+}
+
+func TestErrorHandler_ServiceError(t *testing.T) {
+	// Arrange
+	app := fiber.New(fiber.Config{ErrorHandler: fiber_handler.ErrorHandler})
+	app.Post("/", func(c *fiber.Ctx) error {
+		return common_error.NewServiceError(common_error.InvalidCredentials, "Invalid credentials", nil)
+	})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/", nil))
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, problem.ContentType, resp.Header.Get(fiber.HeaderContentType))
+
+	var p problem.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
+	assert.Equal(t, problem.InvalidCredentials, p.Code)
+	assert.Equal(t, "Invalid credentials", p.Detail)
+}
+
+func TestCreateAPIKey_Success(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockAPIKeyService := new(MockAPIKeyService)
+	claims := &public_model.CustomClaims{UserID: "user"}
+	ctx := identity.WithClaims(context.Background(), claims)
+	created := &public_model.CreatedAPIKeyModel{Key: "bbk_id_secret"}
+
+	mockFiberContext.On("Context").Return(ctx)
+	mockFiberContext.On("BodyParser", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*public_model.CreateAPIKeyModel).Name = "ci"
+	})
+	mockFiberContext.On("JSON", created).Return(nil)
+	mockAPIKeyService.On("Create", mock.Anything, claims, &public_model.CreateAPIKeyModel{Name: "ci"}).Return(created, nil)
+
+	handler := fiber_handler.NewFiberServerHandler(new(MockAuthService), new(MockSessionService), mockAPIKeyService, new(MockAuditService))
+
+	// Act
+	err := handler.CreateAPIKey(mockFiberContext)
+
+	// Assert
+	assert.Nil(t, err)
+
+	mockFiberContext.AssertExpectations(t)
+	mockAPIKeyService.AssertExpectations(t)
+}
+
+func TestCreateAPIKey_Error_Validation(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockAPIKeyService := new(MockAPIKeyService)
+	ctx := identity.WithClaims(context.Background(), &public_model.CustomClaims{UserID: "user"})
+
+	mockFiberContext.On("Context").Return(ctx)
+	mockFiberContext.On("BodyParser", mock.Anything).Return(nil)
+
+	handler := fiber_handler.NewFiberServerHandler(new(MockAuthService), new(MockSessionService), mockAPIKeyService, new(MockAuditService))
+
+	// Act
+	err := handler.CreateAPIKey(mockFiberContext)
+
+	// Assert
+	_, ok := err.(*validation.Error)
+	assert.True(t, ok)
+	mockAPIKeyService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExchangeAPIKey_Success(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockAPIKeyService := new(MockAPIKeyService)
+	accessToken := &public_model.AccessTokenModel{AccessToken: "access_token", TokenType: "Bearer", ExpiresIn: 300}
+
+	mockFiberContext.On("Context").Return(context.Background())
+	mockFiberContext.On("BodyParser", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*public_model.APIKeyExchangeModel).Key = "bbk_id_secret"
+	})
+	mockFiberContext.On("JSON", accessToken).Return(nil)
+	mockAPIKeyService.On("Exchange", mock.Anything, "bbk_id_secret").Return(accessToken, nil)
+
+	handler := fiber_handler.NewFiberServerHandler(new(MockAuthService), new(MockSessionService), mockAPIKeyService, new(MockAuditService))
+
+	// Act
+	err := handler.ExchangeAPIKey(mockFiberContext)
+
+	// Assert
+	assert.Nil(t, err)
+
+	mockFiberContext.AssertExpectations(t)
+	mockAPIKeyService.AssertExpectations(t)
+}
+
+func TestImpersonate_NormalizesIdentifier(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockAuthService := new(MockAuthService)
+	actor := &public_model.CustomClaims{UserID: "admin", Roles: []string{"admin"}}
+	accessToken := &public_model.AccessTokenModel{AccessToken: "access_token", TokenType: "Bearer", ExpiresIn: 600}
+
+	mockFiberContext.On("Context").Return(identity.WithClaims(context.Background(), actor))
+	mockFiberContext.On("BodyParser", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		impersonateModel := args.Get(0).(*public_model.ImpersonateModel)
+		impersonateModel.Identifier = "Alice@Example.com "
+		impersonateModel.Reason = "support ticket"
+	})
+	mockFiberContext.On("JSON", accessToken).Return(nil)
+	mockAuthService.On("Impersonate", mock.Anything, actor, &public_model.ImpersonateModel{Identifier: "alice@example.com", Reason: "support ticket"}).Return(accessToken, nil)
+
+	handler := fiber_handler.NewFiberServerHandler(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService))
+
+	// Act
+	err := handler.Impersonate(mockFiberContext)
+
+	// Assert
+	assert.Nil(t, err)
+
+	mockFiberContext.AssertExpectations(t)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestListAuditEvents_Success(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockAuditService := new(MockAuditService)
+	claims := &public_model.CustomClaims{UserID: "admin", Roles: []string{"admin"}}
+	ctx := identity.WithClaims(context.Background(), claims)
+	events := []*public_model.AuditEventModel{{ID: "event", Type: "login_failure", Outcome: "failure"}}
+
+	mockFiberContext.On("Context").Return(ctx)
+	mockFiberContext.On("QueryParser", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*public_model.AuditQueryModel).Type = "login_failure"
+	})
+	mockFiberContext.On("JSON", events).Return(nil)
+	mockAuditService.On("List", mock.Anything, claims, &public_model.AuditQueryModel{Type: "login_failure"}).Return(events, nil)
+
+	handler := fiber_handler.NewFiberServerHandler(new(MockAuthService), new(MockSessionService), new(MockAPIKeyService), mockAuditService)
+
+	// Act
+	err := handler.ListAuditEvents(mockFiberContext)
+
+	// Assert
+	assert.Nil(t, err)
+
+	mockFiberContext.AssertExpectations(t)
+	mockAuditService.AssertExpectations(t)
+}
+
+func TestListAuditEvents_Error_Validation(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	mockAuditService := new(MockAuditService)
+	ctx := identity.WithClaims(context.Background(), &public_model.CustomClaims{UserID: "admin"})
+
+	mockFiberContext.On("Context").Return(ctx)
+	mockFiberContext.On("QueryParser", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*public_model.AuditQueryModel).Type = "unknown"
+	})
+
+	handler := fiber_handler.NewFiberServerHandler(new(MockAuthService), new(MockSessionService), new(MockAPIKeyService), mockAuditService)
+
+	// Act
+	err := handler.ListAuditEvents(mockFiberContext)
+
+	// Assert
+	_, ok := err.(*validation.Error)
+	assert.True(t, ok)
+	mockAuditService.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	fiber_util "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/util"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/health"
+	"github.com/gofiber/fiber/v2"
+)
+
+// HealthHandler answers the orchestrator's liveness and readiness probes.
+type HealthHandler struct {
+	Registry *health.Registry
+}
+
+// NewHealthHandler initializes a new HealthHandler reporting the registry's checks for readiness.
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{Registry: registry}
+}
+
+// Liveness reports that the process serves requests. It checks no dependencies, so that an outage of
+// one does not get the service restarted.
+func (h *HealthHandler) Liveness(c fiber_util.FiberContext) error {
+	return c.JSON(&health.Report{Status: health.StatusUp})
+}
+
+// Readiness reports whether the service can handle requests, running every registered check. It
+// answers 503 Service Unavailable with the failing checks when any is down.
+func (h *HealthHandler) Readiness(c fiber_util.FiberContext) error {
+	report := h.Registry.Check(c.Context())
+	if report.Status != health.StatusUp {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(report)
+	}
+	return c.JSON(report)
+}
@@ -0,0 +1,77 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	fiber_handler "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/handler"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/health"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveness(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	registry := health.NewRegistry(time.Second)
+	registry.Register("user_service", health.CheckerFunc(func(ctx context.Context) error { return errors.New("down") }))
+	mockFiberContext.On("JSON", &health.Report{Status: health.StatusUp}).Return(nil)
+
+	handler := fiber_handler.NewHealthHandler(registry)
+
+	// Act
+	err := handler.Liveness(mockFiberContext)
+
+	// Assert
+	assert.Nil(t, err)
+	mockFiberContext.AssertExpectations(t)
+}
+
+func TestReadiness_Up(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	registry := health.NewRegistry(time.Second)
+	registry.Register("signing_keys", health.CheckerFunc(func(ctx context.Context) error { return nil }))
+	mockFiberContext.On("Context").Return(context.Background())
+	mockFiberContext.On("JSON", &health.Report{
+		Status: health.StatusUp,
+		Checks: map[string]health.Result{"signing_keys": {Status: health.StatusUp}},
+	}).Return(nil)
+
+	handler := fiber_handler.NewHealthHandler(registry)
+
+	// Act
+	err := handler.Readiness(mockFiberContext)
+
+	// Assert
+	assert.Nil(t, err)
+	mockFiberContext.AssertExpectations(t)
+}
+
+func TestReadiness_Down(t *testing.T) {
+	// Arrange
+	mockFiberContext := new(MockFiberContext)
+	registry := health.NewRegistry(time.Second)
+	registry.Register("signing_keys", health.CheckerFunc(func(ctx context.Context) error { return nil }))
+	registry.Register("user_service", health.CheckerFunc(func(ctx context.Context) error { return errors.New("not connected") }))
+	mockFiberContext.On("Context").Return(context.Background())
+	mockFiberContext.On("Status", fiber.StatusServiceUnavailable)
+	mockFiberContext.On("JSON", &health.Report{
+		Status: health.StatusDown,
+		Checks: map[string]health.Result{
+			"signing_keys": {Status: health.StatusUp},
+			"user_service": {Status: health.StatusDown, Error: "not connected"},
+		},
+	}).Return(nil)
+
+	handler := fiber_handler.NewHealthHandler(registry)
+
+	// Act
+	err := handler.Readiness(mockFiberContext)
+
+	// Assert
+	assert.Nil(t, err)
+	mockFiberContext.AssertExpectations(t)
+}
@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/identity"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/logging"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/metrics"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/problem"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/rbac"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tracing"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ClientInfo records the caller's IP address and user agent in the request context. The values are
+// copied, since Fiber's point into a request buffer that is reused once the request is done, and
+// sessions keep them.
+func ClientInfo() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.SetUserContext(session.WithClientInfo(c.UserContext(), session.ClientInfo{
+			IP:        utils.CopyString(c.IP()),
+			UserAgent: utils.CopyString(c.Get(fiber.HeaderUserAgent)),
+		}))
+		return c.Next()
+	}
+}
+
+// Tenant records the tenant the request was made for in the request context, derived from the
+// request's host or the resolver's header. Requests naming an unknown or conflicting tenant are
+// rejected. It must run before Authenticate.
+func Tenant(resolver *tenant.Resolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tenantID, err := resolver.Resolve(c.Hostname(), c.Get(resolver.Header))
+		if err != nil {
+			return err
+		}
+		// Kept in sessions and tokens beyond the request, so not left pointing into its buffer
+		tenantID = utils.CopyString(tenantID)
+		c.SetUserContext(tenant.WithResolver(tenant.WithTenant(c.UserContext(), tenantID), resolver))
+		return c.Next()
+	}
+}
+
+// Authenticate requires a valid access token of the request's tenant and stores its claims in the request context.
+func Authenticate(tokenService token.ITokenService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tokenString, ok := identity.BearerToken(c.Get(fiber.HeaderAuthorization))
+		if !ok {
+			return problem.New(problem.Unauthenticated, "Authorization token is required")
+		}
+
+		claims, err := tokenService.ParseToken(c.UserContext(), tokenString)
+		if err != nil || claims.TokenType != token.AccessTokenType || !tenant.Matches(c.UserContext(), claims.Tenant) {
+			return problem.New(problem.InvalidToken, "Invalid authorization token")
+		}
+
+		c.SetUserContext(identity.WithClaims(c.UserContext(), claims))
+		return c.Next()
+	}
+}
+
+// Authorize enforces the access control policy on the route it is attached to. It must be
+// registered on the route itself, after Authenticate, so that the matched route is known.
+func Authorize(evaluator rbac.IEvaluator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		route := routeKey(c.Route())
+		if evaluator.IsPublicRoute(route) {
+			return c.Next()
+		}
+
+		claims, err := identity.RequireClaims(c.UserContext())
+		if err != nil {
+			return err
+		}
+
+		permission, ok := evaluator.RoutePermission(route)
+		if !ok {
+			return problem.New(problem.Forbidden, "No access policy for this route")
+		}
+		if !evaluator.HasPermission(claims.Roles, permission) {
+			return problem.New(problem.Forbidden, "Missing permission "+permission)
+		}
+
+		return c.Next()
+	}
+}
+
+// routeKey names a route the way the access control policy does, as "METHOD /path/:param".
+// Group routes registered on "/" are named without the trailing slash.
+func routeKey(route *fiber.Route) string {
+	path := route.Path
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return route.Method + " " + path
+}
+
+// Metrics measures every request, labelled by the matched route rather than the path so that path
+// parameters do not create a series each. It must be registered first. Errors are handed to the
+// application's error handler here, so that the status they map to is the one recorded.
+func Metrics(m *metrics.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		handle(c, c.Next())
+		m.ObserveHTTP(c.Method(), matchedRoute(c), c.Response().StatusCode(), time.Since(start))
+		return nil
+	}
+}
+
+// Tracing records a server span for every request, continuing the trace the caller sent in the W3C
+// trace context headers. It must be registered first, so that the spans of the handlers are its
+// children. Like Metrics, it hands errors to the application's error handler to see their status.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c})
+		ctx, span := tracing.Start(ctx, c.Method(), trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPMethod(c.Method()), semconv.URLPath(c.Path())))
+		defer span.End()
+
+		c.SetUserContext(ctx)
+		handle(c, c.Next())
+
+		route := matchedRoute(c)
+		status := c.Response().StatusCode()
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, utils.StatusMessage(status))
+		}
+		return nil
+	}
+}
+
+// RequestID records the request ID the caller sent in the X-Request-ID header in the request context,
+// or a new one when it sent none, and returns it in the response header. It must be registered first.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID, err := logging.RequestID(c.Get(logging.RequestIDHeader))
+		if err != nil {
+			return err
+		}
+		c.Set(logging.RequestIDHeader, requestID)
+		c.SetUserContext(logging.WithRequestID(c.UserContext(), requestID))
+		return c.Next()
+	}
+}
+
+// AccessLog logs every request with its route, status and duration, and with its JSON body when the
+// logger is enabled for debug records. It must be registered after RequestID and Tracing, so that it
+// logs their IDs. Like Metrics, it hands errors to the application's error handler to see their status.
+func AccessLog(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		handle(c, c.Next())
+
+		ctx := c.UserContext()
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.String("route", matchedRoute(c)),
+			slog.Int("status", c.Response().StatusCode()),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("ip", c.IP()),
+			slog.String("user_agent", c.Get(fiber.HeaderUserAgent)),
+		}
+		if body := c.Body(); len(body) > 0 && logger.Enabled(ctx, slog.LevelDebug) {
+			attrs = append(attrs, slog.Any("request", logging.JSONValue(body)))
+		}
+		logger.LogAttrs(ctx, slog.LevelInfo, "HTTP request", attrs...)
+		return nil
+	}
+}
+
+// handle hands an error returned by the next handlers to the application's error handler.
+func handle(c *fiber.Ctx, err error) {
+	if err == nil {
+		return
+	}
+	if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+		_ = c.SendStatus(fiber.StatusInternalServerError)
+	}
+}
+
+// matchedRoute returns the path of the route that handled the request, or "unmatched" when only the
+// middleware matched.
+func matchedRoute(c *fiber.Ctx) string {
+	route := c.Route().Path
+	if route == "/" && c.Path() != "/" {
+		return "unmatched"
+	}
+	return route
+}
+
+// headerCarrier adapts the request headers for the propagator.
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+// Get implements propagation.TextMapCarrier.
+func (h headerCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
+
+// Set implements propagation.TextMapCarrier.
+func (h headerCarrier) Set(key string, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+// Keys implements propagation.TextMapCarrier.
+func (h headerCarrier) Keys() []string {
+	headers := h.c.GetReqHeaders()
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	return keys
+}
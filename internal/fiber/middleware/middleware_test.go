@@ -0,0 +1,292 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	fiber_handler "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/handler"
+	fiber_middleware "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/middleware"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/identity"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/logging"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/metrics"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/rbac"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	internal_time "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/time"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tracing"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type MockTokenService struct {
+	mock.Mock
+	token.ITokenService
+}
+
+func (m *MockTokenService) ParseToken(ctx context.Context, tokenString string) (*public_model.CustomClaims, error) {
+	args := m.Called(ctx, tokenString)
+	return args.Get(0).(*public_model.CustomClaims), args.Error(1)
+}
+
+func newApp(tokenService token.ITokenService) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: fiber_handler.ErrorHandler})
+	app.Use(fiber_middleware.ClientInfo())
+	app.Use(fiber_middleware.Tenant(tenant.NewResolver("", map[string]string{"acme.example.com": "acme", "globex.example.com": "globex"})))
+	app.Get("/", fiber_middleware.Authenticate(tokenService), func(c *fiber.Ctx) error {
+		claims, _ := identity.ClaimsFromContext(c.UserContext())
+		clientInfo := session.ClientInfoFromContext(c.UserContext())
+		return c.SendString(claims.UserID + " " + clientInfo.UserAgent)
+	})
+	return app
+}
+
+func TestAuthenticate_Success(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	mockTokenService.On("ParseToken", mock.Anything, "access_token").Return(&public_model.CustomClaims{UserID: "user", TokenType: token.AccessTokenType}, nil)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer access_token")
+	req.Header.Set(fiber.HeaderUserAgent, "test-agent")
+	resp, err := newApp(mockTokenService).Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "user test-agent", string(body))
+}
+
+func TestAuthenticate_Rejected(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	mockTokenService.On("ParseToken", mock.Anything, "refresh_token").Return(&public_model.CustomClaims{UserID: "user", TokenType: token.RefreshTokenType}, nil)
+	mockTokenService.On("ParseToken", mock.Anything, "service_token").Return(&public_model.CustomClaims{UserID: "-1"}, nil)
+	mockTokenService.On("ParseToken", mock.Anything, "bad_token").Return((*public_model.CustomClaims)(nil), errors.New("bad token"))
+
+	for _, header := range []string{"", "Bearer", "Basic abc", "Bearer bad_token", "Bearer refresh_token", "Bearer service_token"} {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set(fiber.HeaderAuthorization, header)
+		resp, err := newApp(mockTokenService).Test(req)
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, fiber.StatusOK, resp.StatusCode, header)
+	}
+}
+
+func TestClientInfo_OutlivesRequest(t *testing.T) {
+	sessionService := session.NewSessionService(session.NewMemoryStore(), internal_time.NewSystemTime(), session.Timeouts{})
+	app := fiber.New()
+	app.Use(fiber_middleware.ClientInfo())
+	app.Get("/", func(c *fiber.Ctx) error {
+		if _, err := sessionService.Create(c.UserContext(), "", "user"); err != nil {
+			return err
+		}
+		// Fiber reuses the request buffer for later requests
+		c.Request().Header.SetUserAgent("evil-agent")
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderUserAgent, "test-agent")
+	_, err := app.Test(req)
+	assert.NoError(t, err)
+
+	sessions, err := sessionService.List(context.TODO(), "", "user")
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "test-agent", sessions[0].UserAgent)
+}
+
+func TestAuthenticate_Tenant(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	mockTokenService.On("ParseToken", mock.Anything, "access_token").Return(&public_model.CustomClaims{UserID: "user", TokenType: token.AccessTokenType, Tenant: "acme"}, nil)
+
+	for _, tc := range []struct {
+		host   string
+		header string
+		status int
+	}{
+		{"example.com", "acme", fiber.StatusOK},
+		{"acme.example.com", "", fiber.StatusOK},
+		{"example.com", "", fiber.StatusUnauthorized},
+		{"example.com", "globex", fiber.StatusUnauthorized},
+		{"example.com", "initech", fiber.StatusBadRequest},
+		{"acme.example.com", "globex", fiber.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(fiber.MethodGet, "http://"+tc.host+"/", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer access_token")
+		if tc.header != "" {
+			req.Header.Set(tenant.DefaultHeader, tc.header)
+		}
+		resp, err := newApp(mockTokenService).Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.status, resp.StatusCode, tc.host+" "+tc.header)
+	}
+}
+
+func newAuthorizedApp(t *testing.T, claims *public_model.CustomClaims) *fiber.App {
+	evaluator, err := rbac.NewEvaluator(&rbac.Policy{
+		Roles: map[string]rbac.Role{"user": {Permissions: []string{"sessions:read"}}},
+		HTTP: map[string]string{
+			"GET /sessions":        "sessions:read",
+			"DELETE /sessions/:id": "sessions:revoke",
+		},
+	})
+	assert.NoError(t, err)
+
+	app := fiber.New(fiber.Config{ErrorHandler: fiber_handler.ErrorHandler})
+	sessions := app.Group("/sessions", func(c *fiber.Ctx) error {
+		if claims != nil {
+			c.SetUserContext(identity.WithClaims(c.UserContext(), claims))
+		}
+		return c.Next()
+	})
+	authorize := fiber_middleware.Authorize(evaluator)
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) }
+	sessions.Get("/", authorize, ok)
+	sessions.Delete("/:id", authorize, ok)
+	sessions.Post("/", authorize, ok)
+	return app
+}
+
+func TestAuthorize(t *testing.T) {
+	user := &public_model.CustomClaims{UserID: "user", Roles: []string{"user"}}
+
+	for _, tc := range []struct {
+		claims *public_model.CustomClaims
+		method string
+		path   string
+		status int
+	}{
+		{user, fiber.MethodGet, "/sessions", fiber.StatusNoContent},
+		{user, fiber.MethodDelete, "/sessions/abc", fiber.StatusForbidden},
+		{user, fiber.MethodPost, "/sessions", fiber.StatusForbidden},
+		{nil, fiber.MethodGet, "/sessions", fiber.StatusUnauthorized},
+	} {
+		resp, err := newAuthorizedApp(t, tc.claims).Test(httptest.NewRequest(tc.method, tc.path, nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.status, resp.StatusCode, tc.method+" "+tc.path)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m, err := metrics.NewMetrics(prometheus.NewRegistry())
+	assert.NoError(t, err)
+	app := fiber.New(fiber.Config{ErrorHandler: fiber_handler.ErrorHandler})
+	app.Use(fiber_middleware.Metrics(m))
+	app.Delete("/sessions/:id", func(c *fiber.Ctx) error {
+		if c.Params("id") == "missing" {
+			return common_error.NewServiceError(common_error.NotFound, "Session not found", nil)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	for _, path := range []string{"/sessions/one", "/sessions/two", "/sessions/missing", "/unknown"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodDelete, path, nil))
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// Requests are labelled by route, with the status the error handler responded with
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.HTTPRequests.WithLabelValues("DELETE", "/sessions/:id", "204")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.HTTPRequests.WithLabelValues("DELETE", "/sessions/:id", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.HTTPRequests.WithLabelValues("DELETE", "unmatched", "404")))
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(tracing.Propagator())
+	t.Cleanup(func() { otel.SetTracerProvider(trace.NewNoopTracerProvider()) })
+
+	app := fiber.New(fiber.Config{ErrorHandler: fiber_handler.ErrorHandler})
+	app.Use(fiber_middleware.Tracing())
+	var handlerSpan trace.SpanContext
+	app.Delete("/sessions/:id", func(c *fiber.Ctx) error {
+		handlerSpan = trace.SpanContextFromContext(c.UserContext())
+		if c.Params("id") == "broken" {
+			return errors.New("store down")
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(fiber.MethodDelete, "/sessions/one", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodDelete, "/sessions/broken", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	// Spans are named by route, continue the caller's trace and are the parents of the handler's spans
+	assert.Equal(t, "DELETE /sessions/:id", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("http.status_code", fiber.StatusNoContent))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	// Errors are answered by the error handler before the span ends
+	assert.Equal(t, spans[1].SpanContext().SpanID(), handlerSpan.SpanID())
+	assert.False(t, spans[1].Parent().IsValid())
+	assert.Contains(t, spans[1].Attributes(), attribute.Int("http.status_code", fiber.StatusInternalServerError))
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
+
+func TestRequestIDAndAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(logging.NewHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: logging.Redact})))
+	app := fiber.New(fiber.Config{ErrorHandler: fiber_handler.ErrorHandler})
+	app.Use(fiber_middleware.RequestID(), fiber_middleware.AccessLog(logger))
+	var requestID string
+	app.Post("/login", func(c *fiber.Ctx) error {
+		requestID = logging.RequestIDFromContext(c.UserContext())
+		return common_error.NewServiceError(common_error.Unauthorized, "Invalid credentials", nil)
+	})
+
+	req := httptest.NewRequest(fiber.MethodPost, "/login", strings.NewReader(`{"identifier":"user","password":"hunter2"}`))
+	req.Header.Set(logging.RequestIDHeader, "req-1")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "req-1", resp.Header.Get(logging.RequestIDHeader))
+	assert.Equal(t, "req-1", requestID)
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "HTTP request", record["msg"])
+	assert.Equal(t, "/login", record["route"])
+	assert.Equal(t, float64(fiber.StatusUnauthorized), record["status"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, map[string]interface{}{"identifier": "user", "password": logging.Redacted}, record["request"])
+
+	// Requests without a usable ID get a new one
+	req = httptest.NewRequest(fiber.MethodPost, "/login", nil)
+	req.Header.Set(logging.RequestIDHeader, "forged id")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Header.Get(logging.RequestIDHeader), 32)
+	assert.Equal(t, resp.Header.Get(logging.RequestIDHeader), requestID)
+}
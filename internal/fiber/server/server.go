@@ -1,37 +1,89 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+
 	fiber_handler "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/handler"
+	fiber_middleware "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/middleware"
 	fiber_util "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/fiber/util"
 	"github.com/gofiber/fiber/v2"
 )
 
 type FiberServer struct {
-	App *fiber.App
+	App       *fiber.App
+	TLSConfig *tls.Config // Serves HTTPS when set, plain HTTP when nil
 }
 
-func NewAuthFiberServer(config *fiber.Config, handler *fiber_handler.FiberServerHandler) *FiberServer {
+// NewAuthFiberServer creates the HTTP server. The middleware run first on every request, probes included.
+// The tenancy handler resolves the tenant of every request, the authenticator guards routes that act on
+// behalf of a user, and the authorizer enforces the access control policy on each of them. The metrics
+// handler serves /metrics unless nil.
+func NewAuthFiberServer(config *fiber.Config, handler *fiber_handler.FiberServerHandler, healthHandler *fiber_handler.HealthHandler, metricsHandler fiber.Handler, middleware []fiber.Handler, tenancy fiber.Handler, authenticator fiber.Handler, authorizer fiber.Handler) *FiberServer {
 	fiberServer := &FiberServer{App: fiber.New(*config)}
-	fiberServer.setupRoutes(handler)
+	for _, m := range middleware {
+		fiberServer.App.Use(m)
+	}
+	fiberServer.setupRoutes(handler, healthHandler, metricsHandler, tenancy, authenticator, authorizer)
 	return fiberServer
 }
 
-func (f *FiberServer) Run(port string) error {
-	return f.App.Listen(port)
+// Run serves on the address until the server is shut down, over TLS when a TLS configuration is set.
+func (f *FiberServer) Run(address string) error {
+	if f.TLSConfig == nil {
+		return f.App.Listen(address)
+	}
+	listener, err := tls.Listen("tcp", address, f.TLSConfig)
+	if err != nil {
+		return err
+	}
+	return f.App.Listener(listener)
 }
 
-func (f *FiberServer) setupRoutes(handler *fiber_handler.FiberServerHandler) {
-	f.App.Post("/login", func(c *fiber.Ctx) error {
-		fiberCtx := &fiber_util.FiberContextImpl{
-			Ctx: c,
-		}
-		return handler.Login(fiberCtx)
-	})
+// Shutdown stops accepting connections and waits for in-flight requests to complete. Connections still
+// open when ctx is done are closed.
+func (f *FiberServer) Shutdown(ctx context.Context) error {
+	return f.App.ShutdownWithContext(ctx)
+}
 
-	f.App.Post("/register", func(c *fiber.Ctx) error {
+// route adapts a handler method to a Fiber handler.
+func route(h func(fiber_util.FiberContext) error) fiber.Handler {
+	return func(c *fiber.Ctx) error {
 		fiberCtx := &fiber_util.FiberContextImpl{
 			Ctx: c,
 		}
-		return handler.Register(fiberCtx)
-	})
+		return h(fiberCtx)
+	}
+}
+
+func (f *FiberServer) setupRoutes(handler *fiber_handler.FiberServerHandler, healthHandler *fiber_handler.HealthHandler, metricsHandler fiber.Handler, tenancy fiber.Handler, authenticator fiber.Handler, authorizer fiber.Handler) {
+	// Probes and scrapes come from the orchestrator, not from a tenant, so they are registered ahead of
+	// the request middleware
+	f.App.Get("/healthz", route(healthHandler.Liveness))
+	f.App.Get("/readyz", route(healthHandler.Readiness))
+	if metricsHandler != nil {
+		f.App.Get("/metrics", metricsHandler)
+	}
+
+	f.App.Use(fiber_middleware.ClientInfo())
+	f.App.Use(tenancy)
+
+	f.App.Post("/login", route(handler.Login))
+	f.App.Post("/register", route(handler.Register))
+	f.App.Post("/refresh", route(handler.Refresh))
+	// Authenticated by the API key itself, so registered ahead of the authenticated group below
+	f.App.Post("/api-keys/exchange", route(handler.ExchangeAPIKey))
+
+	sessions := f.App.Group("/sessions", authenticator)
+	sessions.Get("/", authorizer, route(handler.ListSessions))
+	sessions.Delete("/", authorizer, route(handler.RevokeAllSessions))
+	sessions.Delete("/:id", authorizer, route(handler.RevokeSession))
+
+	apiKeys := f.App.Group("/api-keys", authenticator)
+	apiKeys.Get("/", authorizer, route(handler.ListAPIKeys))
+	apiKeys.Post("/", authorizer, route(handler.CreateAPIKey))
+	apiKeys.Delete("/:id", authorizer, route(handler.RevokeAPIKey))
+
+	f.App.Post("/impersonate", authenticator, authorizer, route(handler.Impersonate))
+	f.App.Get("/audit-events", authenticator, authorizer, route(handler.ListAuditEvents))
 }
@@ -8,7 +8,11 @@ import (
 
 type FiberContext interface {
 	BodyParser(v interface{}) error
+	QueryParser(v interface{}) error
 	JSON(v interface{}) error
+	Params(key string, defaultValue ...string) string
+	SendStatus(status int) error
+	Status(status int) FiberContext
 	Context() context.Context
 }
 
@@ -20,10 +24,29 @@ func (f *FiberContextImpl) BodyParser(v interface{}) error {
 	return f.Ctx.BodyParser(v)
 }
 
+func (f *FiberContextImpl) QueryParser(v interface{}) error {
+	return f.Ctx.QueryParser(v)
+}
+
 func (f *FiberContextImpl) JSON(v interface{}) error {
 	return f.Ctx.JSON(v)
 }
 
+func (f *FiberContextImpl) Params(key string, defaultValue ...string) string {
+	return f.Ctx.Params(key, defaultValue...)
+}
+
+func (f *FiberContextImpl) SendStatus(status int) error {
+	return f.Ctx.SendStatus(status)
+}
+
+// Status sets the status code of the response and returns the context for chaining.
+func (f *FiberContextImpl) Status(status int) FiberContext {
+	f.Ctx.Status(status)
+	return f
+}
+
+// Context returns the request's user context, which carries values set by middleware.
 func (f *FiberContextImpl) Context() context.Context {
-	return f.Ctx.Context()
+	return f.Ctx.UserContext()
 }
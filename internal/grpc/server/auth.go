@@ -0,0 +1,212 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/identity"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/problem"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/rbac"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// AuthInterceptor requires a valid access token of the request's tenant on every method not
+// listed in publicMethods and stores its claims in the request context.
+func AuthInterceptor(tokenService token.ITokenService, publicMethods map[string]struct{}) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, tokenService, publicMethods, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor is the stream equivalent of AuthInterceptor.
+func AuthStreamInterceptor(tokenService token.ITokenService, publicMethods map[string]struct{}) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), tokenService, publicMethods, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, withContext(stream, ctx))
+	}
+}
+
+// authenticate returns ctx with the claims of the call's access token, unless the method is public.
+func authenticate(ctx context.Context, tokenService token.ITokenService, publicMethods map[string]struct{}, fullMethod string) (context.Context, error) {
+	// Check if the method is public; if it is, bypass the authentication
+	if _, ok := publicMethods[fullMethod]; ok {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	authHeader := md.Get("authorization")
+	if len(authHeader) == 0 {
+		return nil, problem.New(problem.Unauthenticated, "Authorization token is required")
+	}
+
+	tokenString, ok := identity.BearerToken(authHeader[0])
+	if !ok {
+		return nil, problem.New(problem.InvalidToken, "Invalid authorization token")
+	}
+
+	claims, err := tokenService.ParseToken(ctx, tokenString)
+	if err != nil || claims.TokenType != token.AccessTokenType || !tenant.Matches(ctx, claims.Tenant) {
+		return nil, problem.New(problem.InvalidToken, "Invalid authorization token")
+	}
+
+	return identity.WithClaims(ctx, claims), nil
+}
+
+// AuthorizationInterceptor enforces the access control policy. Methods restricted to internal services,
+// public or not, need the verified identity ServiceIdentityInterceptor records; methods that are not
+// public need token claims whose roles grant their permission. It runs after AuthInterceptor.
+func AuthorizationInterceptor(evaluator rbac.IEvaluator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, evaluator, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthorizationStreamInterceptor is the stream equivalent of AuthorizationInterceptor.
+func AuthorizationStreamInterceptor(evaluator rbac.IEvaluator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(stream.Context(), evaluator, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// authorize checks that the caller is a service the method allows, and that the roles of the call's
+// claims grant the permission the method requires.
+func authorize(ctx context.Context, evaluator rbac.IEvaluator, fullMethod string) error {
+	service, _ := identity.ServiceFromContext(ctx)
+	if !evaluator.ServiceAllowed(fullMethod, service) {
+		return problem.New(problem.Forbidden, "This method is restricted to internal services")
+	}
+	if evaluator.IsPublicMethod(fullMethod) {
+		return nil
+	}
+
+	claims, err := identity.RequireClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	permission, ok := evaluator.MethodPermission(fullMethod)
+	if !ok {
+		return problem.New(problem.Forbidden, "No access policy for this method")
+	}
+	if !evaluator.HasPermission(claims.Roles, permission) {
+		return problem.New(problem.Forbidden, "Missing permission "+permission)
+	}
+	return nil
+}
+
+// TenantInterceptor records the tenant the request was made for in the request context. Calls naming
+// an unknown or conflicting tenant are rejected. It must run before AuthInterceptor, which rejects
+// tokens of other tenants.
+func TenantInterceptor(resolver *tenant.Resolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := withTenant(ctx, resolver)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// TenantStreamInterceptor is the stream equivalent of TenantInterceptor.
+func TenantStreamInterceptor(resolver *tenant.Resolver) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := withTenant(stream.Context(), resolver)
+		if err != nil {
+			return err
+		}
+		return handler(srv, withContext(stream, ctx))
+	}
+}
+
+// withTenant returns ctx with the tenant resolved from the call's authority or tenant header.
+func withTenant(ctx context.Context, resolver *tenant.Resolver) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tenantID, err := resolver.Resolve(firstValue(md, ":authority"), firstValue(md, resolver.Header))
+	if err != nil {
+		return nil, err
+	}
+	return tenant.WithResolver(tenant.WithTenant(ctx, tenantID), resolver), nil
+}
+
+// firstValue returns the first value of a metadata key, or an empty string.
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ClientInfoInterceptor records the caller's address and user agent in the request context.
+func ClientInfoInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(withClientInfo(ctx), req)
+}
+
+// ClientInfoStreamInterceptor is the stream equivalent of ClientInfoInterceptor.
+func ClientInfoStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, withContext(stream, withClientInfo(stream.Context())))
+}
+
+// withClientInfo returns ctx with the caller's address and user agent.
+func withClientInfo(ctx context.Context) context.Context {
+	clientInfo := session.ClientInfo{}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		clientInfo.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(clientInfo.IP); err == nil {
+			clientInfo.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgent := md.Get("user-agent"); len(userAgent) > 0 {
+			clientInfo.UserAgent = userAgent[0]
+		}
+	}
+
+	return session.WithClientInfo(ctx, clientInfo)
+}
+
+// ServiceIdentityInterceptor records the identity internal callers present in their client certificate
+// in the request context. Only certificates verified against the configured client CAs count, so
+// callers without one, or on a plaintext connection, carry no identity.
+func ServiceIdentityInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(withServiceIdentity(ctx), req)
+}
+
+// ServiceIdentityStreamInterceptor is the stream equivalent of ServiceIdentityInterceptor.
+func ServiceIdentityStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, withContext(stream, withServiceIdentity(stream.Context())))
+}
+
+// withServiceIdentity returns ctx with the service identity of the caller's verified client certificate.
+func withServiceIdentity(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ctx
+	}
+	if service, ok := identity.ServiceFromCertificate(tlsInfo.State.VerifiedChains[0][0]); ok {
+		return identity.WithService(ctx, service)
+	}
+	return ctx
+}
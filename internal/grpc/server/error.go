@@ -0,0 +1,33 @@
+package grpcserver
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/problem"
+	"google.golang.org/grpc"
+)
+
+// ErrorInterceptor converts handler errors into gRPC statuses carrying the same error
+// codes as the HTTP API, as ErrorInfo and BadRequest details.
+func ErrorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, convertError(ctx, info.FullMethod, err)
+	}
+	return resp, nil
+}
+
+// ErrorStreamInterceptor is the stream equivalent of ErrorInterceptor.
+func ErrorStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := handler(srv, stream); err != nil {
+		return convertError(stream.Context(), info.FullMethod, err)
+	}
+	return nil
+}
+
+// convertError logs a handler error and converts it into a gRPC status.
+func convertError(ctx context.Context, fullMethod string, err error) error {
+	slog.WarnContext(ctx, "Handler failed", "method", fullMethod, "error", err)
+	return problem.FromError(err).GRPCStatus().Err()
+}
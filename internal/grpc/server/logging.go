@@ -0,0 +1,94 @@
+package grpcserver
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/identity"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// RequestIDInterceptor records the request ID the caller sent in the metadata in the call context, or
+// a new one when it sent none, and returns it in the response header.
+func RequestIDInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	requestID, err := requestID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fails only outside of a call, as in tests
+	_ = grpc.SetHeader(ctx, metadata.Pairs(logging.RequestIDMetadataKey, requestID))
+	return handler(logging.WithRequestID(ctx, requestID), req)
+}
+
+// RequestIDStreamInterceptor is the stream equivalent of RequestIDInterceptor.
+func RequestIDStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	requestID, err := requestID(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	if err := stream.SetHeader(metadata.Pairs(logging.RequestIDMetadataKey, requestID)); err != nil {
+		return err
+	}
+	return handler(srv, withContext(stream, logging.WithRequestID(stream.Context(), requestID)))
+}
+
+// requestID returns the request ID the caller sent in the metadata, or a new one.
+func requestID(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return logging.RequestID(firstValue(md, logging.RequestIDMetadataKey))
+}
+
+// AccessLogInterceptor logs every call with its status code and duration, and with its request when
+// the logger is enabled for debug records. It must come after RequestIDInterceptor and
+// ServiceIdentityInterceptor and before ErrorInterceptor, so that it logs the request ID, the calling
+// service and the codes handler errors are converted to.
+func AccessLogInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		attrs := accessAttrs(ctx, info.FullMethod, start, err)
+		if message, ok := req.(proto.Message); ok && logger.Enabled(ctx, slog.LevelDebug) {
+			attrs = append(attrs, slog.Any("request", logging.ProtoValue(message)))
+		}
+		logger.LogAttrs(ctx, slog.LevelInfo, "gRPC request", attrs...)
+		return resp, err
+	}
+}
+
+// AccessLogStreamInterceptor is the stream equivalent of AccessLogInterceptor. Streams are logged
+// when they end, without their messages.
+func AccessLogStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+
+		ctx := stream.Context()
+		logger.LogAttrs(ctx, slog.LevelInfo, "gRPC stream", accessAttrs(ctx, info.FullMethod, start, err)...)
+		return err
+	}
+}
+
+// accessAttrs describes a call that started at start and ended with err.
+func accessAttrs(ctx context.Context, fullMethod string, start time.Time, err error) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("method", fullMethod),
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+	if service, ok := identity.ServiceFromContext(ctx); ok {
+		attrs = append(attrs, slog.String("service", service))
+	}
+	return attrs
+}
@@ -0,0 +1,38 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsInterceptor counts and times every call by method and status code. It must come before
+// ErrorInterceptor, so that it sees the codes handler errors are converted to.
+func MetricsInterceptor(m *metrics.Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observe(m, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// MetricsStreamInterceptor is the stream equivalent of MetricsInterceptor. Streams are timed until
+// they end.
+func MetricsStreamInterceptor(m *metrics.Metrics) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		observe(m, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// observe records a call that started at start and ended with err.
+func observe(m *metrics.Metrics, fullMethod string, start time.Time, err error) {
+	m.GRPCRequests.WithLabelValues(fullMethod, status.Code(err).String()).Inc()
+	m.GRPCDuration.WithLabelValues(fullMethod).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,38 @@
+package grpcserver
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/problem"
+	"google.golang.org/grpc"
+)
+
+// RecoveryInterceptor turns a panic in a handler or a later interceptor into an Internal error, so
+// that a bug fails the call rather than the server. The panic is logged with its stack. It must come
+// after the logging and metrics interceptors, so that they see the error.
+func RecoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			resp, err = nil, recovered(ctx, info.FullMethod, r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// RecoveryStreamInterceptor is the stream equivalent of RecoveryInterceptor.
+func RecoveryStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(stream.Context(), info.FullMethod, r)
+		}
+	}()
+	return handler(srv, stream)
+}
+
+// recovered logs a recovered panic and returns the error the call fails with, which does not reveal it.
+func recovered(ctx context.Context, fullMethod string, r interface{}) error {
+	slog.ErrorContext(ctx, "Handler panicked", "method", fullMethod, "panic", r, "stack", string(debug.Stack()))
+	return problem.New(problem.Internal, "An unexpected error occurred")
+}
@@ -3,19 +3,43 @@ package grpcserver
 import (
 	"context"
 	"net"
+	"time"
 
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/abac"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/apikey"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/audit"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/auth"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/identity"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/rbac"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/validation"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/proto/pb"
 	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
 	common_grpc "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/grpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // IAuthGRPCServer is an interface defining the authentication related methods that the GRPC server should implement.
 type IAuthGRPCServer interface {
 	Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error)
 	Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error)
+	Refresh(ctx context.Context, req *pb.RefreshRequest) (*pb.RefreshResponse, error)
+	ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error)
+	RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error)
+	RevokeAllSessions(ctx context.Context, req *pb.RevokeAllSessionsRequest) (*pb.RevokeAllSessionsResponse, error)
+	CheckPermission(ctx context.Context, req *pb.CheckPermissionRequest) (*pb.CheckPermissionResponse, error)
+	Authorize(ctx context.Context, req *pb.AuthorizeRequest) (*pb.AuthorizeResponse, error)
+	CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error)
+	RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error)
+	ExchangeAPIKey(ctx context.Context, req *pb.ExchangeAPIKeyRequest) (*pb.ExchangeAPIKeyResponse, error)
+	Impersonate(ctx context.Context, req *pb.ImpersonateRequest) (*pb.ImpersonateResponse, error)
+	ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error)
 	Run() error
+	Shutdown(ctx context.Context) error
 	InitServer(port string, listener common_grpc.Listener) error
 }
 
@@ -27,17 +51,42 @@ type ServerConfig struct {
 
 // AuthGRPCServer is a struct that embeds the services and configurations needed for the authentication server.
 type AuthGRPCServer struct {
-	AuthService                       auth.IAuthService             // Authentication service
-	Interceptors                      []grpc.UnaryServerInterceptor // Interceptors for the GRPC server
-	Config                            ServerConfig                  // Server configuration
-	pb.UnimplementedAuthServiceServer                               // Embedding the unimplemented server for forward compatibility
+	AuthService                       auth.IAuthService                // Authentication service
+	SessionService                    session.ISessionService          // Session management service
+	APIKeyService                     apikey.IAPIKeyService            // API key management and exchange
+	AuditService                      audit.IAuditService              // Audit log queries for admins
+	PermissionService                 rbac.IPermissionService          // Access control checks for other services
+	Authorizer                        abac.IAuthorizer                 // Attribute-based authorization decisions
+	Health                            grpc_health_v1.HealthServer      // Standard health service for probes, none when nil
+	Interceptors                      []grpc.UnaryServerInterceptor    // Interceptors for the GRPC server
+	StreamInterceptors                []grpc.StreamServerInterceptor   // Interceptors for streaming calls, such as health watches
+	Credentials                       credentials.TransportCredentials // Transport security, such as TLS, plaintext when nil
+	Config                            ServerConfig                     // Server configuration
+	pb.UnimplementedAuthServiceServer                                  // Embedding the unimplemented server for forward compatibility
 }
 
 // NewAuthGRPCServer is a constructor for creating an instance of AuthGRPCServer with necessary dependencies.
-func NewAuthGRPCServer(authService auth.IAuthService, interceptors []grpc.UnaryServerInterceptor) *AuthGRPCServer {
+func NewAuthGRPCServer(
+	authService auth.IAuthService,
+	sessionService session.ISessionService,
+	apiKeyService apikey.IAPIKeyService,
+	auditService audit.IAuditService,
+	permissionService rbac.IPermissionService,
+	authorizer abac.IAuthorizer,
+	health grpc_health_v1.HealthServer,
+	interceptors []grpc.UnaryServerInterceptor,
+	streamInterceptors []grpc.StreamServerInterceptor,
+) *AuthGRPCServer {
 	return &AuthGRPCServer{
-		AuthService:  authService,
-		Interceptors: interceptors,
+		AuthService:        authService,
+		SessionService:     sessionService,
+		APIKeyService:      apiKeyService,
+		AuditService:       auditService,
+		PermissionService:  permissionService,
+		Authorizer:         authorizer,
+		Health:             health,
+		Interceptors:       interceptors,
+		StreamInterceptors: streamInterceptors,
 	}
 }
 
@@ -48,10 +97,19 @@ func (s *AuthGRPCServer) InitServer(port string, listener common_grpc.Listener)
 		return err
 	}
 	s.Config.Listener = lis
-	s.Config.GRPCServer = grpc.NewServer(
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(validation.MaxRequestBodySize),
 		grpc.UnaryInterceptor(common_grpc.ChainUnaryInterceptors(s.Interceptors...)),
-	)
+		grpc.ChainStreamInterceptor(s.StreamInterceptors...),
+	}
+	if s.Credentials != nil {
+		opts = append(opts, grpc.Creds(s.Credentials))
+	}
+	s.Config.GRPCServer = grpc.NewServer(opts...)
 	pb.RegisterAuthServiceServer(s.Config.GRPCServer, s)
+	if s.Health != nil {
+		grpc_health_v1.RegisterHealthServer(s.Config.GRPCServer, s.Health)
+	}
 	return nil
 }
 
@@ -60,11 +118,44 @@ func (s *AuthGRPCServer) Run() error {
 	return s.Config.GRPCServer.Serve(s.Config.Listener)
 }
 
+// Shutdown stops accepting connections and waits for in-flight RPCs to complete. RPCs still running when
+// ctx is done are cancelled.
+func (s *AuthGRPCServer) Shutdown(ctx context.Context) error {
+	// Probes see the server going away, and health watches end so that they do not hold up GracefulStop
+	if health, ok := s.Health.(interface{ Shutdown() }); ok {
+		health.Shutdown()
+	}
+	if s.Config.GRPCServer == nil {
+		return nil
+	}
+	stopped := make(chan struct{})
+	go func() {
+		s.Config.GRPCServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.Config.GRPCServer.Stop()
+		<-stopped
+		return ctx.Err()
+	}
+}
+
 // Login handles the login requests, authenticating users and returning tokens.
 func (s *AuthGRPCServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
 	loginModel := &public_model.LoginModel{
-		Email:    req.GetEmail(),
-		Password: req.GetPassword(),
+		Identifier: req.GetIdentifier(),
+		Email:      req.GetEmail(),
+		Password:   req.GetPassword(),
+		ClientID:   req.GetClientId(),
+		Scope:      req.GetScope(),
+		TenantID:   req.GetTenantId(),
+	}
+	validation.NormalizeLogin(loginModel)
+	if err := validation.ValidateLogin(loginModel); err != nil {
+		return nil, err
 	}
 
 	token, err := s.AuthService.Login(ctx, loginModel)
@@ -84,6 +175,13 @@ func (s *AuthGRPCServer) Register(ctx context.Context, req *pb.RegisterRequest)
 		Email:    req.GetEmail(),
 		Username: req.GetUsername(),
 		Password: req.GetPassword(),
+		ClientID: req.GetClientId(),
+		Scope:    req.GetScope(),
+		TenantID: req.GetTenantId(),
+	}
+	validation.NormalizeRegister(registerModel)
+	if err := validation.ValidateRegister(registerModel); err != nil {
+		return nil, err
 	}
 
 	token, err := s.AuthService.Register(ctx, registerModel)
@@ -97,5 +195,289 @@ func (s *AuthGRPCServer) Register(ctx context.Context, req *pb.RegisterRequest)
 	}, nil
 }
 
+// Refresh exchanges a refresh token for a new token pair in the same session.
+func (s *AuthGRPCServer) Refresh(ctx context.Context, req *pb.RefreshRequest) (*pb.RefreshResponse, error) {
+	refreshModel := &public_model.TokenRefreshModel{
+		Token:    req.GetRefreshToken(),
+		Scope:    req.GetScope(),
+		TenantID: req.GetTenantId(),
+	}
+	if err := validation.ValidateRefresh(refreshModel); err != nil {
+		return nil, err
+	}
+
+	token, err := s.AuthService.Refresh(ctx, refreshModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RefreshResponse{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+	}, nil
+}
+
+// ListSessions returns the active sessions of the authenticated user.
+func (s *AuthGRPCServer) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	claims, err := identity.RequireClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.SessionService.List(ctx, claims.Tenant, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListSessionsResponse{}
+	for _, userSession := range sessions {
+		sessionModel := userSession.ToSessionModel(claims.SessionID)
+		resp.Sessions = append(resp.Sessions, &pb.Session{
+			Id:              sessionModel.ID,
+			CreatedAt:       sessionModel.CreatedAt.Format(time.RFC3339),
+			LastRefreshedAt: sessionModel.LastRefreshedAt.Format(time.RFC3339),
+			Ip:              sessionModel.IP,
+			UserAgent:       sessionModel.UserAgent,
+			Current:         sessionModel.Current,
+		})
+	}
+
+	return resp, nil
+}
+
+// RevokeSession ends one of the authenticated user's sessions.
+func (s *AuthGRPCServer) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	claims, err := identity.RequireClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.SessionService.Revoke(ctx, claims.Tenant, claims.UserID, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	return &pb.RevokeSessionResponse{}, nil
+}
+
+// RevokeAllSessions ends every session of the authenticated user.
+func (s *AuthGRPCServer) RevokeAllSessions(ctx context.Context, req *pb.RevokeAllSessionsRequest) (*pb.RevokeAllSessionsResponse, error) {
+	claims, err := identity.RequireClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.SessionService.RevokeAll(ctx, claims.Tenant, claims.UserID); err != nil {
+		return nil, err
+	}
+
+	return &pb.RevokeAllSessionsResponse{}, nil
+}
+
 // Ensuring at compile time that AuthGRPCServer implements IAuthGRPCServer interface.
 var _ IAuthGRPCServer = (*AuthGRPCServer)(nil)
+
+// CheckPermission reports whether the roles in the given access token grant a permission.
+// The token must belong to the tenant named in the request or derived from its metadata.
+func (s *AuthGRPCServer) CheckPermission(ctx context.Context, req *pb.CheckPermissionRequest) (*pb.CheckPermissionResponse, error) {
+	if req.GetPermission() == "" {
+		return nil, &validation.Error{Violations: []validation.FieldViolation{{Field: "permission", Description: "must not be empty"}}}
+	}
+
+	tenantID, err := tenant.Select(ctx, req.GetTenantId())
+	if err != nil {
+		return nil, err
+	}
+	ctx = tenant.WithTenant(ctx, tenantID)
+
+	allowed, err := s.PermissionService.CheckPermission(ctx, req.GetAccessToken(), req.GetPermission())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CheckPermissionResponse{Allowed: allowed}, nil
+}
+
+// Authorize decides whether the authenticated user may perform an action on a resource.
+func (s *AuthGRPCServer) Authorize(ctx context.Context, req *pb.AuthorizeRequest) (*pb.AuthorizeResponse, error) {
+	claims, err := identity.RequireClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetAction() == "" {
+		return nil, &validation.Error{Violations: []validation.FieldViolation{{Field: "action", Description: "must not be empty"}}}
+	}
+
+	decision := s.Authorizer.Authorize(ctx, claims, req.GetAction(), req.GetResource().AsMap())
+
+	return &pb.AuthorizeResponse{
+		Allowed: decision.Allowed,
+		Reasons: decision.Reasons,
+	}, nil
+}
+
+// CreateAPIKey issues a new API key for the authenticated user. The key is only returned once.
+func (s *AuthGRPCServer) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	claims, err := identity.RequireClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	createModel := &public_model.CreateAPIKeyModel{
+		Name:          req.GetName(),
+		Scope:         req.GetScope(),
+		ExpiresInDays: int(req.GetExpiresInDays()),
+	}
+	if err := validation.ValidateCreateAPIKey(createModel); err != nil {
+		return nil, err
+	}
+
+	created, err := s.APIKeyService.Create(ctx, claims, createModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateAPIKeyResponse{
+		ApiKey: apiKeyMessage(&created.APIKeyModel),
+		Key:    created.Key,
+	}, nil
+}
+
+// ListAPIKeys returns the API keys of the authenticated user.
+func (s *AuthGRPCServer) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	claims, err := identity.RequireClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := s.APIKeyService.List(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListAPIKeysResponse{}
+	for _, apiKey := range apiKeys {
+		resp.ApiKeys = append(resp.ApiKeys, apiKeyMessage(apiKey.ToAPIKeyModel()))
+	}
+
+	return resp, nil
+}
+
+// RevokeAPIKey deletes one of the authenticated user's API keys.
+func (s *AuthGRPCServer) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	claims, err := identity.RequireClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.APIKeyService.Revoke(ctx, claims, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	return &pb.RevokeAPIKeyResponse{}, nil
+}
+
+// ExchangeAPIKey trades an API key for a short-lived access token.
+func (s *AuthGRPCServer) ExchangeAPIKey(ctx context.Context, req *pb.ExchangeAPIKeyRequest) (*pb.ExchangeAPIKeyResponse, error) {
+	exchangeModel := &public_model.APIKeyExchangeModel{Key: req.GetApiKey()}
+	if err := validation.ValidateAPIKeyExchange(exchangeModel); err != nil {
+		return nil, err
+	}
+
+	token, err := s.APIKeyService.Exchange(ctx, exchangeModel.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ExchangeAPIKeyResponse{
+		AccessToken: token.AccessToken,
+		ExpiresIn:   token.ExpiresIn,
+	}, nil
+}
+
+// Impersonate issues a short-lived access token that lets the authenticated admin act as another user.
+func (s *AuthGRPCServer) Impersonate(ctx context.Context, req *pb.ImpersonateRequest) (*pb.ImpersonateResponse, error) {
+	claims, err := identity.RequireClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	impersonateModel := &public_model.ImpersonateModel{
+		Identifier: req.GetIdentifier(),
+		Reason:     req.GetReason(),
+	}
+	validation.NormalizeImpersonate(impersonateModel)
+	if err := validation.ValidateImpersonate(impersonateModel); err != nil {
+		return nil, err
+	}
+
+	token, err := s.AuthService.Impersonate(ctx, claims, impersonateModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ImpersonateResponse{
+		AccessToken: token.AccessToken,
+		ExpiresIn:   token.ExpiresIn,
+	}, nil
+}
+
+// ListAuditEvents returns the audit events of the caller's tenant selected by the request, newest first.
+func (s *AuthGRPCServer) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	claims, err := identity.RequireClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	queryModel := &public_model.AuditQueryModel{
+		Type:      req.GetType(),
+		ActorID:   req.GetActorId(),
+		SubjectID: req.GetSubjectId(),
+		Outcome:   req.GetOutcome(),
+		Since:     req.GetSince(),
+		Until:     req.GetUntil(),
+		Limit:     int(req.GetLimit()),
+	}
+	if err := validation.ValidateAuditQuery(queryModel); err != nil {
+		return nil, err
+	}
+
+	events, err := s.AuditService.List(ctx, claims, queryModel)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListAuditEventsResponse{}
+	for _, event := range events {
+		resp.Events = append(resp.Events, &pb.AuditEvent{
+			Id:        event.ID,
+			Type:      event.Type,
+			Time:      event.Time.Format(time.RFC3339),
+			Outcome:   event.Outcome,
+			ActorId:   event.ActorID,
+			SubjectId: event.SubjectID,
+			Tenant:    event.Tenant,
+			Ip:        event.IP,
+			UserAgent: event.UserAgent,
+			Reason:    event.Reason,
+			Details:   event.Details,
+		})
+	}
+
+	return resp, nil
+}
+
+// apiKeyMessage converts an API key model into its protobuf message.
+func apiKeyMessage(apiKeyModel *public_model.APIKeyModel) *pb.APIKey {
+	message := &pb.APIKey{
+		Id:        apiKeyModel.ID,
+		Name:      apiKeyModel.Name,
+		Prefix:    apiKeyModel.Prefix,
+		Scope:     apiKeyModel.Scope,
+		CreatedAt: apiKeyModel.CreatedAt.Format(time.RFC3339),
+		ExpiresAt: apiKeyModel.ExpiresAt.Format(time.RFC3339),
+	}
+	if apiKeyModel.LastUsedAt != nil {
+		message.LastUsedAt = apiKeyModel.LastUsedAt.Format(time.RFC3339)
+	}
+	return message
+}
@@ -1,17 +1,57 @@
 package grpcserver_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/abac"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/apikey"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/audit"
 	grpc_server "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/grpc/server"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/health"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/identity"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/logging"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/metrics"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/problem"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/rbac"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tracing"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/validation"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/proto/pb"
 	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	common_grpc "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/grpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otel_codes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type MockListener struct{}
@@ -40,18 +80,135 @@ func (m *MockAuthService) Register(ctx context.Context, model *public_model.Regi
 	return args.Get(0).(*public_model.TokenModel), args.Error(1)
 }
 
+func (m *MockAuthService) Refresh(ctx context.Context, model *public_model.TokenRefreshModel) (*public_model.TokenModel, error) {
+	args := m.Called(ctx, model)
+	return args.Get(0).(*public_model.TokenModel), args.Error(1)
+}
+
+func (m *MockAuthService) Impersonate(ctx context.Context, actor *public_model.CustomClaims, impersonateModel *public_model.ImpersonateModel) (*public_model.AccessTokenModel, error) {
+	args := m.Called(ctx, actor, impersonateModel)
+	return args.Get(0).(*public_model.AccessTokenModel), args.Error(1)
+}
+
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) Create(ctx context.Context, tenantID string, userID string) (*session.Session, error) {
+	args := m.Called(ctx, tenantID, userID)
+	return args.Get(0).(*session.Session), args.Error(1)
+}
+
+func (m *MockSessionService) Refresh(ctx context.Context, tenantID string, userID string, sessionID string, refreshTokenID string) (*session.Session, error) {
+	args := m.Called(ctx, tenantID, userID, sessionID, refreshTokenID)
+	return args.Get(0).(*session.Session), args.Error(1)
+}
+
+func (m *MockSessionService) List(ctx context.Context, tenantID string, userID string) ([]*session.Session, error) {
+	args := m.Called(ctx, tenantID, userID)
+	return args.Get(0).([]*session.Session), args.Error(1)
+}
+
+func (m *MockSessionService) Revoke(ctx context.Context, tenantID string, userID string, sessionID string) error {
+	args := m.Called(ctx, tenantID, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) RevokeAll(ctx context.Context, tenantID string, userID string) error {
+	args := m.Called(ctx, tenantID, userID)
+	return args.Error(0)
+}
+
+type MockPermissionService struct {
+	mock.Mock
+}
+
+func (m *MockPermissionService) CheckPermission(ctx context.Context, accessToken string, permission string) (bool, error) {
+	args := m.Called(ctx, accessToken, permission)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) Create(ctx context.Context, owner *public_model.CustomClaims, createModel *public_model.CreateAPIKeyModel) (*public_model.CreatedAPIKeyModel, error) {
+	args := m.Called(ctx, owner, createModel)
+	return args.Get(0).(*public_model.CreatedAPIKeyModel), args.Error(1)
+}
+
+func (m *MockAPIKeyService) List(ctx context.Context, owner *public_model.CustomClaims) ([]*apikey.APIKey, error) {
+	args := m.Called(ctx, owner)
+	return args.Get(0).([]*apikey.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) Revoke(ctx context.Context, owner *public_model.CustomClaims, keyID string) error {
+	args := m.Called(ctx, owner, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) Exchange(ctx context.Context, key string) (*public_model.AccessTokenModel, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(*public_model.AccessTokenModel), args.Error(1)
+}
+
+// Ensure that MockAPIKeyService implements IAPIKeyService
+var _ apikey.IAPIKeyService = &MockAPIKeyService{}
+
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) List(ctx context.Context, caller *public_model.CustomClaims, query *public_model.AuditQueryModel) ([]*public_model.AuditEventModel, error) {
+	args := m.Called(ctx, caller, query)
+	return args.Get(0).([]*public_model.AuditEventModel), args.Error(1)
+}
+
+// Ensure that MockAuditService implements IAuditService
+var _ audit.IAuditService = &MockAuditService{}
+
+type MockAuthorizer struct {
+	mock.Mock
+}
+
+func (m *MockAuthorizer) Authorize(ctx context.Context, claims *public_model.CustomClaims, action string, resource map[string]interface{}) abac.Decision {
+	args := m.Called(ctx, claims, action, resource)
+	return args.Get(0).(abac.Decision)
+}
+
+type MockTokenService struct {
+	mock.Mock
+	token.ITokenService
+}
+
+func (m *MockTokenService) ParseToken(ctx context.Context, tokenString string) (*public_model.CustomClaims, error) {
+	args := m.Called(ctx, tokenString)
+	return args.Get(0).(*public_model.CustomClaims), args.Error(1)
+}
+
 func TestAuthGRPCServer_InitServer_Success(t *testing.T) {
 	mockAuthService := new(MockAuthService)
-	s := grpc_server.NewAuthGRPCServer(mockAuthService, []grpc.UnaryServerInterceptor{})
+	s := grpc_server.NewAuthGRPCServer(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
 	err := s.InitServer(":5000", &MockListener{})
 
 	assert.Nil(t, err)
 	assert.NotNil(t, s.Config.GRPCServer)
 }
 
+func TestAuthGRPCServer_InitServer_Health(t *testing.T) {
+	healthServer := health.NewGRPCServer(health.NewRegistry(time.Second), pb.AuthService_ServiceDesc.ServiceName)
+	s := grpc_server.NewAuthGRPCServer(new(MockAuthService), new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), healthServer, []grpc.UnaryServerInterceptor{}, nil)
+	err := s.InitServer(":5000", &MockListener{})
+
+	assert.Nil(t, err)
+	services := s.Config.GRPCServer.GetServiceInfo()
+	assert.Contains(t, services, "grpc.health.v1.Health")
+	assert.Contains(t, services, pb.AuthService_ServiceDesc.ServiceName)
+}
+
 func TestAuthGRPCServer_InitServer_Error(t *testing.T) {
 	mockAuthService := new(MockAuthService)
-	s := grpc_server.NewAuthGRPCServer(mockAuthService, []grpc.UnaryServerInterceptor{})
+	s := grpc_server.NewAuthGRPCServer(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
 	err := s.InitServer(":5000", &MockListenerWithError{})
 
 	assert.NotNil(t, err)
@@ -91,7 +248,7 @@ func TestAuthGRPCServer_Login_Success(t *testing.T) {
 		RefreshToken: "expected_refresh_token",
 	}, nil)
 
-	s := grpc_server.NewAuthGRPCServer(mockAuthService, []grpc.UnaryServerInterceptor{})
+	s := grpc_server.NewAuthGRPCServer(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
 
 	req := &pb.LoginRequest{
 		Email:    "test@test.com",
@@ -107,13 +264,39 @@ func TestAuthGRPCServer_Login_Success(t *testing.T) {
 	mockAuthService.AssertExpectations(t)
 }
 
+// Test Login method with a username identifier
+func TestAuthGRPCServer_Login_Identifier(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+	mockAuthService.On("Login", mock.Anything, &public_model.LoginModel{
+		Identifier: "test_user",
+		Password:   "password",
+	}).Return(&public_model.TokenModel{
+		AccessToken:  "expected_access_token",
+		RefreshToken: "expected_refresh_token",
+	}, nil)
+
+	s := grpc_server.NewAuthGRPCServer(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	req := &pb.LoginRequest{
+		Identifier: " Test_User ",
+		Password:   "password",
+	}
+	resp, err := s.Login(context.TODO(), req)
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, "expected_access_token", resp.GetAccessToken())
+
+	mockAuthService.AssertExpectations(t)
+}
+
 // Test Login method with an expected error
 func TestAuthGRPCServer_Login_Error(t *testing.T) {
 	mockAuthService := new(MockAuthService)
 	expectedError := fmt.Errorf("login failed")
 	mockAuthService.On("Login", mock.Anything, mock.Anything).Return((*public_model.TokenModel)(nil), expectedError)
 
-	s := grpc_server.NewAuthGRPCServer(mockAuthService, []grpc.UnaryServerInterceptor{})
+	s := grpc_server.NewAuthGRPCServer(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
 
 	req := &pb.LoginRequest{
 		Email:    "test@test.com",
@@ -137,7 +320,7 @@ func TestAuthGRPCServer_Register_Success(t *testing.T) {
 		RefreshToken: "expected_refresh_token",
 	}, nil)
 
-	s := grpc_server.NewAuthGRPCServer(mockAuthService, []grpc.UnaryServerInterceptor{})
+	s := grpc_server.NewAuthGRPCServer(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
 
 	req := &pb.RegisterRequest{
 		Email:    "test@test.com",
@@ -160,7 +343,7 @@ func TestAuthGRPCServer_Register_Error(t *testing.T) {
 	expectedError := fmt.Errorf("register failed")
 	mockAuthService.On("Register", mock.Anything, mock.Anything).Return((*public_model.TokenModel)(nil), expectedError)
 
-	s := grpc_server.NewAuthGRPCServer(mockAuthService, []grpc.UnaryServerInterceptor{})
+	s := grpc_server.NewAuthGRPCServer(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
 
 	req := &pb.RegisterRequest{
 		Email:    "test@test.com",
@@ -176,3 +359,744 @@ func TestAuthGRPCServer_Register_Error(t *testing.T) {
 
 	mockAuthService.AssertExpectations(t)
 }
+
+// Test Login method with an invalid request
+func TestAuthGRPCServer_Login_ValidationError(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+
+	s := grpc_server.NewAuthGRPCServer(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	req := &pb.LoginRequest{
+		Email: "not-an-email",
+	}
+	resp, err := s.Login(context.TODO(), req)
+
+	// Assertions
+	var validationErr *validation.Error
+	assert.Nil(t, resp)
+	assert.ErrorAs(t, err, &validationErr)
+
+	mockAuthService.AssertNotCalled(t, "Login", mock.Anything, mock.Anything)
+}
+
+// Test Register method with an invalid request
+func TestAuthGRPCServer_Register_ValidationError(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+
+	s := grpc_server.NewAuthGRPCServer(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	req := &pb.RegisterRequest{
+		Email:    "test@test.com",
+		Username: "a",
+		Password: "password",
+	}
+	resp, err := s.Register(context.TODO(), req)
+
+	// Assertions
+	var validationErr *validation.Error
+	assert.Nil(t, resp)
+	assert.ErrorAs(t, err, &validationErr)
+
+	mockAuthService.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+}
+
+// Test that validation errors are returned with BadRequest details
+func TestErrorInterceptor_Validation(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, &validation.Error{Violations: []validation.FieldViolation{{Field: "email", Description: "must not be empty"}}}
+	}
+
+	resp, err := grpc_server.ErrorInterceptor(context.TODO(), nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/Login"}, handler)
+
+	// Assertions
+	assert.Nil(t, resp)
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Len(t, st.Details(), 2)
+
+	errorInfo, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.True(t, ok)
+	assert.Equal(t, "validation_failed", errorInfo.GetReason())
+
+	badRequest, ok := st.Details()[1].(*errdetails.BadRequest)
+	assert.True(t, ok)
+	assert.Equal(t, "email", badRequest.GetFieldViolations()[0].GetField())
+	assert.Equal(t, "must not be empty", badRequest.GetFieldViolations()[0].GetDescription())
+}
+
+// Test that service errors are mapped to the shared error codes
+func TestErrorInterceptor_ServiceError(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, common_error.NewServiceError(common_error.InvalidCredentials, "Invalid credentials", nil)
+	}
+
+	resp, err := grpc_server.ErrorInterceptor(context.TODO(), nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/Login"}, handler)
+
+	// Assertions
+	assert.Nil(t, resp)
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.Unauthenticated, st.Code())
+	assert.Equal(t, "Invalid credentials", st.Message())
+	assert.Equal(t, "invalid_credentials", st.Details()[0].(*errdetails.ErrorInfo).GetReason())
+}
+
+func TestMetricsInterceptor(t *testing.T) {
+	m, err := metrics.NewMetrics(prometheus.NewRegistry())
+	assert.NoError(t, err)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, common_error.NewServiceError(common_error.InvalidCredentials, "Invalid credentials", nil)
+	}
+	// Chained ahead of the error interceptor, as in the server, so that the converted code is counted
+	interceptor := common_grpc.ChainUnaryInterceptors(grpc_server.MetricsInterceptor(m), grpc_server.ErrorInterceptor)
+
+	_, err = interceptor(context.TODO(), nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/Login"}, handler)
+
+	assert.Error(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.GRPCRequests.WithLabelValues("/AuthService/Login", "Unauthenticated")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.GRPCDuration))
+}
+
+func TestTracingInterceptor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(tracing.Propagator())
+	t.Cleanup(func() { otel.SetTracerProvider(trace.NewNoopTracerProvider()) })
+
+	var handlerSpan trace.SpanContext
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return nil, common_error.NewServiceError(common_error.InvalidCredentials, "Invalid credentials", nil)
+	}
+	interceptor := common_grpc.ChainUnaryInterceptors(grpc_server.TracingInterceptor(), grpc_server.ErrorInterceptor)
+	ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/Login"}, handler)
+
+	assert.Error(t, err)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "AuthService/Login", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	// The span continues the caller's trace and is the parent of the handler's spans
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID())
+	assert.Contains(t, span.Attributes(), attribute.Int("rpc.grpc.status_code", int(codes.Unauthenticated)))
+	assert.Equal(t, otel_codes.Error, span.Status().Code)
+}
+
+func TestRequestIDInterceptor(t *testing.T) {
+	var requestID string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		requestID = logging.RequestIDFromContext(ctx)
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/AuthService/Login"}
+
+	ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs("x-request-id", "req-1"))
+	_, err := grpc_server.RequestIDInterceptor(ctx, nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-1", requestID)
+
+	// Calls without an ID get a new one
+	_, err = grpc_server.RequestIDInterceptor(context.TODO(), nil, info, handler)
+	assert.NoError(t, err)
+	assert.Len(t, requestID, 32)
+}
+
+func TestAccessLogInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(logging.NewHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: logging.Redact})))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, common_error.NewServiceError(common_error.InvalidCredentials, "Invalid credentials", nil)
+	}
+	// Chained as in the server, so that the request ID and the converted code are logged
+	interceptor := common_grpc.ChainUnaryInterceptors(grpc_server.RequestIDInterceptor, grpc_server.AccessLogInterceptor(logger), grpc_server.ErrorInterceptor)
+	ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs("x-request-id", "req-1"))
+
+	_, err := interceptor(ctx, &pb.LoginRequest{Email: "user@example.com", Password: "hunter2"}, &grpc.UnaryServerInfo{FullMethod: "/AuthService/Login"}, handler)
+
+	assert.Error(t, err)
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "gRPC request", record["msg"])
+	assert.Equal(t, "/AuthService/Login", record["method"])
+	assert.Equal(t, "Unauthenticated", record["code"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, map[string]interface{}{"email": "user@example.com", "password": logging.Redacted}, record["request"])
+}
+
+// Test Refresh method
+func TestAuthGRPCServer_Refresh_Success(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+	mockAuthService.On("Refresh", mock.Anything, &public_model.TokenRefreshModel{Token: "refresh_token"}).Return(&public_model.TokenModel{
+		AccessToken:  "expected_access_token",
+		RefreshToken: "expected_refresh_token",
+	}, nil)
+
+	s := grpc_server.NewAuthGRPCServer(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	resp, err := s.Refresh(context.TODO(), &pb.RefreshRequest{RefreshToken: "refresh_token"})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, "expected_access_token", resp.GetAccessToken())
+	assert.Equal(t, "expected_refresh_token", resp.GetRefreshToken())
+
+	mockAuthService.AssertExpectations(t)
+}
+
+// Test ListSessions method
+func TestAuthGRPCServer_ListSessions_Success(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	createdAt := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	mockSessionService.On("List", mock.Anything, "acme", "user").Return([]*session.Session{
+		{ID: "current", CreatedAt: createdAt, LastRefreshedAt: createdAt, IP: "127.0.0.1", UserAgent: "test"},
+		{ID: "other", CreatedAt: createdAt, LastRefreshedAt: createdAt},
+	}, nil)
+
+	s := grpc_server.NewAuthGRPCServer(new(MockAuthService), mockSessionService, new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	ctx := identity.WithClaims(context.TODO(), &public_model.CustomClaims{UserID: "user", Tenant: "acme", SessionID: "current"})
+	resp, err := s.ListSessions(ctx, &pb.ListSessionsRequest{})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Len(t, resp.GetSessions(), 2)
+	assert.Equal(t, "current", resp.GetSessions()[0].GetId())
+	assert.Equal(t, "2023-11-01T12:00:00Z", resp.GetSessions()[0].GetCreatedAt())
+	assert.Equal(t, "127.0.0.1", resp.GetSessions()[0].GetIp())
+	assert.True(t, resp.GetSessions()[0].GetCurrent())
+	assert.False(t, resp.GetSessions()[1].GetCurrent())
+
+	mockSessionService.AssertExpectations(t)
+}
+
+// Test ListSessions method without an authenticated caller
+func TestAuthGRPCServer_ListSessions_Unauthenticated(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+
+	s := grpc_server.NewAuthGRPCServer(new(MockAuthService), mockSessionService, new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	resp, err := s.ListSessions(context.TODO(), &pb.ListSessionsRequest{})
+
+	// Assertions
+	assert.Nil(t, resp)
+	assert.NotNil(t, err)
+
+	mockSessionService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+// Test RevokeSession method
+func TestAuthGRPCServer_RevokeSession_Success(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	mockSessionService.On("Revoke", mock.Anything, "acme", "user", "session").Return(nil)
+
+	s := grpc_server.NewAuthGRPCServer(new(MockAuthService), mockSessionService, new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	ctx := identity.WithClaims(context.TODO(), &public_model.CustomClaims{UserID: "user", Tenant: "acme"})
+	resp, err := s.RevokeSession(ctx, &pb.RevokeSessionRequest{Id: "session"})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+
+	mockSessionService.AssertExpectations(t)
+}
+
+// Test RevokeAllSessions method
+func TestAuthGRPCServer_RevokeAllSessions_Success(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	mockSessionService.On("RevokeAll", mock.Anything, "acme", "user").Return(nil)
+
+	s := grpc_server.NewAuthGRPCServer(new(MockAuthService), mockSessionService, new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	ctx := identity.WithClaims(context.TODO(), &public_model.CustomClaims{UserID: "user", Tenant: "acme"})
+	resp, err := s.RevokeAllSessions(ctx, &pb.RevokeAllSessionsRequest{})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+
+	mockSessionService.AssertExpectations(t)
+}
+
+// Test that the auth interceptor stores the caller's claims
+func TestAuthInterceptor_Success(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	mockTokenService.On("ParseToken", mock.Anything, "access_token").Return(&public_model.CustomClaims{UserID: "user", TokenType: token.AccessTokenType}, nil)
+
+	interceptor := grpc_server.AuthInterceptor(mockTokenService, map[string]struct{}{})
+	ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs("authorization", "Bearer access_token"))
+
+	var userID string
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/ListSessions"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		claims, _ := identity.ClaimsFromContext(ctx)
+		userID = claims.UserID
+		return nil, nil
+	})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, "user", userID)
+}
+
+// Test that the auth interceptor rejects missing and malformed tokens, and tokens other than access tokens
+func TestAuthInterceptor_Rejected(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	mockTokenService.On("ParseToken", mock.Anything, "refresh_token").Return(&public_model.CustomClaims{UserID: "user", TokenType: token.RefreshTokenType}, nil)
+	mockTokenService.On("ParseToken", mock.Anything, "service_token").Return(&public_model.CustomClaims{UserID: "-1"}, nil)
+	mockTokenService.On("ParseToken", mock.Anything, "bad_token").Return((*public_model.CustomClaims)(nil), fmt.Errorf("bad token"))
+
+	interceptor := grpc_server.AuthInterceptor(mockTokenService, map[string]struct{}{})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Fatal("handler must not be called")
+		return nil, nil
+	}
+
+	for _, md := range []metadata.MD{
+		{},
+		metadata.Pairs("authorization", "Basic abc"),
+		metadata.Pairs("authorization", "Bearer bad_token"),
+		metadata.Pairs("authorization", "Bearer refresh_token"),
+		metadata.Pairs("authorization", "Bearer service_token"),
+	} {
+		ctx := metadata.NewIncomingContext(context.TODO(), md)
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/ListSessions"}, handler)
+
+		assert.Equal(t, codes.Unauthenticated, problem.FromError(err).GRPCStatus().Code())
+	}
+}
+
+// Test that the tenant interceptor resolves the tenant and the auth interceptor rejects tokens of other tenants
+func TestTenantInterceptor(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	mockTokenService.On("ParseToken", mock.Anything, "access_token").Return(&public_model.CustomClaims{UserID: "user", TokenType: token.AccessTokenType, Tenant: "acme"}, nil)
+
+	tenantInterceptor := grpc_server.TenantInterceptor(tenant.NewResolver("", map[string]string{"acme.example.com": "acme", "globex.example.com": "globex"}))
+	authInterceptor := grpc_server.AuthInterceptor(mockTokenService, map[string]struct{}{})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return authInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/AuthService/ListSessions"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return tenant.FromContext(ctx), nil
+		})
+	}
+
+	for _, tc := range []struct {
+		md   metadata.MD
+		code codes.Code
+	}{
+		{metadata.Pairs("authorization", "Bearer access_token", "x-tenant-id", "acme"), codes.OK},
+		{metadata.Pairs("authorization", "Bearer access_token", ":authority", "acme.example.com:3003"), codes.OK},
+		{metadata.Pairs("authorization", "Bearer access_token"), codes.Unauthenticated},
+		{metadata.Pairs("authorization", "Bearer access_token", "x-tenant-id", "globex"), codes.Unauthenticated},
+		{metadata.Pairs("authorization", "Bearer access_token", "x-tenant-id", "initech"), codes.InvalidArgument},
+		{metadata.Pairs("authorization", "Bearer access_token", "x-tenant-id", "acme", ":authority", "globex.example.com"), codes.InvalidArgument},
+	} {
+		ctx := metadata.NewIncomingContext(context.TODO(), tc.md)
+		resp, err := tenantInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/ListSessions"}, handler)
+
+		if tc.code == codes.OK {
+			assert.Nil(t, err)
+			assert.Equal(t, "acme", resp)
+		} else {
+			assert.Equal(t, tc.code, problem.FromError(err).GRPCStatus().Code())
+		}
+	}
+}
+
+// Test that public methods bypass the auth interceptor
+func TestAuthInterceptor_PublicMethod(t *testing.T) {
+	interceptor := grpc_server.AuthInterceptor(new(MockTokenService), map[string]struct{}{"/AuthService/Login": {}})
+
+	called := false
+	_, err := interceptor(context.TODO(), nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/Login"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.True(t, called)
+}
+
+// Test that the client info interceptor records the peer address and user agent
+func TestClientInfoInterceptor(t *testing.T) {
+	ctx := peer.NewContext(context.TODO(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("user-agent", "grpc-go/1.59.0"))
+
+	var clientInfo session.ClientInfo
+	_, err := grpc_server.ClientInfoInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		clientInfo = session.ClientInfoFromContext(ctx)
+		return nil, nil
+	})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, session.ClientInfo{IP: "10.0.0.1", UserAgent: "grpc-go/1.59.0"}, clientInfo)
+}
+
+func TestServiceIdentityInterceptor(t *testing.T) {
+	spiffeID, _ := url.Parse("spiffe://bitbridge/user-service")
+	serviceIdentity := func(authInfo credentials.AuthInfo) (string, bool) {
+		ctx := peer.NewContext(context.TODO(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}, AuthInfo: authInfo})
+		var service string
+		var ok bool
+		_, err := grpc_server.ServiceIdentityInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			service, ok = identity.ServiceFromContext(ctx)
+			return nil, nil
+		})
+		assert.NoError(t, err)
+		return service, ok
+	}
+	verified := func(cert *x509.Certificate) credentials.TLSInfo {
+		return credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}}
+	}
+
+	// The URI is preferred over the DNS name
+	service, ok := serviceIdentity(verified(&x509.Certificate{URIs: []*url.URL{spiffeID}, DNSNames: []string{"user-service.internal"}}))
+	assert.True(t, ok)
+	assert.Equal(t, "spiffe://bitbridge/user-service", service)
+
+	service, ok = serviceIdentity(verified(&x509.Certificate{DNSNames: []string{"user-service.internal"}}))
+	assert.True(t, ok)
+	assert.Equal(t, "user-service.internal", service)
+
+	// Unverified certificates, certificates without names and plaintext connections carry no identity
+	_, ok = serviceIdentity(credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{{URIs: []*url.URL{spiffeID}}}}})
+	assert.False(t, ok)
+	_, ok = serviceIdentity(verified(&x509.Certificate{}))
+	assert.False(t, ok)
+	_, ok = serviceIdentity(nil)
+	assert.False(t, ok)
+}
+
+// newTestEvaluator builds an evaluator for a small policy
+func newTestEvaluator(t *testing.T) *rbac.Evaluator {
+	evaluator, err := rbac.NewEvaluator(&rbac.Policy{
+		Roles:  map[string]rbac.Role{"user": {Permissions: []string{"sessions:read"}}},
+		Public: rbac.Public{GRPC: []string{"/AuthService/Login", "/AuthService/CheckPermission"}},
+		GRPC: map[string]string{
+			"/AuthService/ListSessions":  "sessions:read",
+			"/AuthService/RevokeSession": "sessions:revoke",
+		},
+		Services: map[string][]string{"/AuthService/CheckPermission": {"spiffe://bitbridge/gateway"}},
+	})
+	assert.NoError(t, err)
+	return evaluator
+}
+
+// Test that the authorization interceptor enforces the policy
+func TestAuthorizationInterceptor(t *testing.T) {
+	interceptor := grpc_server.AuthorizationInterceptor(newTestEvaluator(t))
+	ctx := identity.WithClaims(context.TODO(), &public_model.CustomClaims{UserID: "user", Roles: []string{"user"}})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/ListSessions"}, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/RevokeSession"}, handler)
+	assert.Equal(t, codes.PermissionDenied, problem.FromError(err).GRPCStatus().Code())
+
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/Unlisted"}, handler)
+	assert.Equal(t, codes.PermissionDenied, problem.FromError(err).GRPCStatus().Code())
+
+	_, err = interceptor(context.TODO(), nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/ListSessions"}, handler)
+	assert.Equal(t, codes.Unauthenticated, problem.FromError(err).GRPCStatus().Code())
+
+	resp, err = interceptor(context.TODO(), nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/Login"}, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+// Test that methods restricted to internal services require a verified service identity, even when public
+func TestAuthorizationInterceptor_Service(t *testing.T) {
+	interceptor := grpc_server.AuthorizationInterceptor(newTestEvaluator(t))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/AuthService/CheckPermission"}
+
+	_, err := interceptor(context.TODO(), nil, info, handler)
+	assert.Equal(t, codes.PermissionDenied, problem.FromError(err).GRPCStatus().Code())
+
+	_, err = interceptor(identity.WithService(context.TODO(), "spiffe://bitbridge/user-service"), nil, info, handler)
+	assert.Equal(t, codes.PermissionDenied, problem.FromError(err).GRPCStatus().Code())
+
+	resp, err := interceptor(identity.WithService(context.TODO(), "spiffe://bitbridge/gateway"), nil, info, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+// Test that CheckPermission reports the permission service's decision
+func TestAuthGRPCServer_CheckPermission(t *testing.T) {
+	mockPermissionService := new(MockPermissionService)
+	s := grpc_server.NewAuthGRPCServer(new(MockAuthService), new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), mockPermissionService, new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	mockPermissionService.On("CheckPermission", mock.Anything, "access_token", "sessions:read").Return(true, nil)
+
+	resp, err := s.CheckPermission(context.TODO(), &pb.CheckPermissionRequest{AccessToken: "access_token", Permission: "sessions:read"})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.True(t, resp.GetAllowed())
+
+	_, err = s.CheckPermission(context.TODO(), &pb.CheckPermissionRequest{AccessToken: "access_token"})
+	assert.Equal(t, codes.InvalidArgument, problem.FromError(err).GRPCStatus().Code())
+}
+
+// Test that Authorize passes the caller's claims and resource attributes to the authorizer
+func TestAuthGRPCServer_Authorize(t *testing.T) {
+	mockAuthorizer := new(MockAuthorizer)
+	s := grpc_server.NewAuthGRPCServer(new(MockAuthService), new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), mockAuthorizer, nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	claims := &public_model.CustomClaims{UserID: "user"}
+	ctx := identity.WithClaims(context.TODO(), claims)
+	resource, _ := structpb.NewStruct(map[string]interface{}{"owner": "user"})
+	mockAuthorizer.On("Authorize", mock.Anything, claims, "project:edit", map[string]interface{}{"owner": "user"}).Return(abac.Decision{Allowed: true, Reasons: []string{"allowed by owner"}})
+
+	resp, err := s.Authorize(ctx, &pb.AuthorizeRequest{Action: "project:edit", Resource: resource})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.True(t, resp.GetAllowed())
+	assert.Equal(t, []string{"allowed by owner"}, resp.GetReasons())
+
+	_, err = s.Authorize(ctx, &pb.AuthorizeRequest{})
+	assert.Equal(t, codes.InvalidArgument, problem.FromError(err).GRPCStatus().Code())
+
+	_, err = s.Authorize(context.TODO(), &pb.AuthorizeRequest{Action: "project:edit"})
+	assert.Equal(t, codes.Unauthenticated, problem.FromError(err).GRPCStatus().Code())
+}
+
+// Test that CreateAPIKey issues a key for the caller and ListAPIKeys returns it without the secret
+func TestAuthGRPCServer_APIKeys(t *testing.T) {
+	mockAPIKeyService := new(MockAPIKeyService)
+	s := grpc_server.NewAuthGRPCServer(new(MockAuthService), new(MockSessionService), mockAPIKeyService, new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	claims := &public_model.CustomClaims{UserID: "user"}
+	ctx := identity.WithClaims(context.TODO(), claims)
+	createdAt := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	apiKey := &apikey.APIKey{ID: "id", UserID: "user", Name: "ci", Scopes: []string{"profile"}, CreatedAt: createdAt, ExpiresAt: createdAt.Add(24 * time.Hour)}
+	mockAPIKeyService.On("Create", mock.Anything, claims, &public_model.CreateAPIKeyModel{Name: "ci", ExpiresInDays: 1}).Return(&public_model.CreatedAPIKeyModel{
+		APIKeyModel: *apiKey.ToAPIKeyModel(),
+		Key:         "bbk_id_secret",
+	}, nil)
+	mockAPIKeyService.On("List", mock.Anything, claims).Return([]*apikey.APIKey{apiKey}, nil)
+
+	created, err := s.CreateAPIKey(ctx, &pb.CreateAPIKeyRequest{Name: "ci", ExpiresInDays: 1})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, "bbk_id_secret", created.GetKey())
+	assert.Equal(t, "bbk_id", created.GetApiKey().GetPrefix())
+	assert.Equal(t, "2023-11-02T12:00:00Z", created.GetApiKey().GetExpiresAt())
+	assert.Empty(t, created.GetApiKey().GetLastUsedAt())
+
+	listed, err := s.ListAPIKeys(ctx, &pb.ListAPIKeysRequest{})
+	assert.Nil(t, err)
+	assert.Len(t, listed.GetApiKeys(), 1)
+	assert.Equal(t, "profile", listed.GetApiKeys()[0].GetScope())
+
+	_, err = s.CreateAPIKey(ctx, &pb.CreateAPIKeyRequest{})
+	assert.Equal(t, codes.InvalidArgument, problem.FromError(err).GRPCStatus().Code())
+}
+
+// Test that ExchangeAPIKey returns the access token issued for the key
+func TestAuthGRPCServer_ExchangeAPIKey(t *testing.T) {
+	mockAPIKeyService := new(MockAPIKeyService)
+	s := grpc_server.NewAuthGRPCServer(new(MockAuthService), new(MockSessionService), mockAPIKeyService, new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	mockAPIKeyService.On("Exchange", mock.Anything, "bbk_id_secret").Return(&public_model.AccessTokenModel{AccessToken: "access_token", ExpiresIn: 300}, nil)
+
+	resp, err := s.ExchangeAPIKey(context.TODO(), &pb.ExchangeAPIKeyRequest{ApiKey: "bbk_id_secret"})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, "access_token", resp.GetAccessToken())
+	assert.Equal(t, int64(300), resp.GetExpiresIn())
+}
+
+// Test that ExchangeAPIKey reports rejected keys with API key error codes
+func TestAuthGRPCServer_ExchangeAPIKey_Rejected(t *testing.T) {
+	mockAPIKeyService := new(MockAPIKeyService)
+	s := grpc_server.NewAuthGRPCServer(new(MockAuthService), new(MockSessionService), mockAPIKeyService, new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	mockAPIKeyService.On("Exchange", mock.Anything, "bbk_id_secret").Return((*public_model.AccessTokenModel)(nil), common_error.NewServiceError(common_error.Unauthorized, "Invalid API key", apikey.ErrInvalidKey))
+	mockAPIKeyService.On("Exchange", mock.Anything, "bbk_id_expired").Return((*public_model.AccessTokenModel)(nil), common_error.NewServiceError(common_error.TokenExpired, "API key has expired", apikey.ErrKeyExpired))
+
+	_, err := s.ExchangeAPIKey(context.TODO(), &pb.ExchangeAPIKeyRequest{ApiKey: "bbk_id_secret"})
+	assert.Equal(t, problem.InvalidAPIKey, problem.FromError(err).Code)
+
+	_, err = s.ExchangeAPIKey(context.TODO(), &pb.ExchangeAPIKeyRequest{ApiKey: "bbk_id_expired"})
+	assert.Equal(t, problem.APIKeyExpired, problem.FromError(err).Code)
+	assert.Equal(t, codes.Unauthenticated, problem.FromError(err).GRPCStatus().Code())
+}
+
+func TestAuthGRPCServer_Impersonate(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+	s := grpc_server.NewAuthGRPCServer(mockAuthService, new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	actor := &public_model.CustomClaims{UserID: "admin"}
+	mockAuthService.On("Impersonate", mock.Anything, actor, &public_model.ImpersonateModel{Identifier: "target", Reason: "support ticket"}).Return(&public_model.AccessTokenModel{AccessToken: "access_token", ExpiresIn: 600}, nil)
+
+	resp, err := s.Impersonate(identity.WithClaims(context.TODO(), actor), &pb.ImpersonateRequest{Identifier: "target", Reason: "support ticket"})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, "access_token", resp.GetAccessToken())
+	assert.Equal(t, int64(600), resp.GetExpiresIn())
+
+	// The identifier is normalized as it is for a login
+	_, err = s.Impersonate(identity.WithClaims(context.TODO(), actor), &pb.ImpersonateRequest{Identifier: " Target\t", Reason: "support ticket"})
+	assert.Nil(t, err)
+
+	// A reason is required
+	_, err = s.Impersonate(identity.WithClaims(context.TODO(), actor), &pb.ImpersonateRequest{Identifier: "target"})
+	assert.Error(t, err)
+}
+
+func TestAuthGRPCServer_ListAuditEvents(t *testing.T) {
+	mockAuditService := new(MockAuditService)
+	s := grpc_server.NewAuthGRPCServer(new(MockAuthService), new(MockSessionService), new(MockAPIKeyService), mockAuditService, new(MockPermissionService), new(MockAuthorizer), nil, []grpc.UnaryServerInterceptor{}, nil)
+
+	admin := &public_model.CustomClaims{UserID: "admin"}
+	at := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	mockAuditService.On("List", mock.Anything, admin, &public_model.AuditQueryModel{ActorID: "user", Limit: 10}).Return([]*public_model.AuditEventModel{
+		{ID: "event", Type: "login_success", Time: at, Outcome: "success", ActorID: "user", Details: map[string]string{"identifier": "user"}},
+	}, nil)
+
+	resp, err := s.ListAuditEvents(identity.WithClaims(context.TODO(), admin), &pb.ListAuditEventsRequest{ActorId: "user", Limit: 10})
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Len(t, resp.GetEvents(), 1)
+	assert.Equal(t, "login_success", resp.GetEvents()[0].GetType())
+	assert.Equal(t, "2023-11-01T12:00:00Z", resp.GetEvents()[0].GetTime())
+	assert.Equal(t, map[string]string{"identifier": "user"}, resp.GetEvents()[0].GetDetails())
+
+	// Limits above the maximum are rejected
+	_, err = s.ListAuditEvents(identity.WithClaims(context.TODO(), admin), &pb.ListAuditEventsRequest{Limit: 1000})
+	assert.Error(t, err)
+}
+
+// MockServerStream is a server stream carrying a context, without messages.
+type MockServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (m *MockServerStream) Context() context.Context {
+	return m.ctx
+}
+
+func (m *MockServerStream) SetHeader(md metadata.MD) error {
+	m.header = metadata.Join(m.header, md)
+	return nil
+}
+
+// Test that the stream interceptors pass the context they derive on to the handler
+func TestStreamInterceptors(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	mockTokenService.On("ParseToken", mock.Anything, "access_token").Return(&public_model.CustomClaims{UserID: "user", TokenType: token.AccessTokenType, Roles: []string{"user"}}, nil)
+	chain := []grpc.StreamServerInterceptor{
+		grpc_server.RequestIDStreamInterceptor,
+		grpc_server.ErrorStreamInterceptor,
+		grpc_server.TenantStreamInterceptor(tenant.NewResolver("", nil)),
+		grpc_server.AuthStreamInterceptor(mockTokenService, map[string]struct{}{}),
+		grpc_server.AuthorizationStreamInterceptor(newTestEvaluator(t)),
+		grpc_server.ClientInfoStreamInterceptor,
+	}
+	var ctx context.Context
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		ctx = stream.Context()
+		return nil
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		interceptor, next := chain[i], handler
+		handler = func(srv interface{}, stream grpc.ServerStream) error {
+			return interceptor(srv, stream, &grpc.StreamServerInfo{FullMethod: "/AuthService/ListSessions", IsServerStream: true}, next)
+		}
+	}
+
+	stream := &MockServerStream{ctx: metadata.NewIncomingContext(context.TODO(), metadata.Pairs(
+		"authorization", "Bearer access_token", "x-request-id", "req-1", "user-agent", "test-agent",
+	))}
+	err := handler(nil, stream)
+
+	assert.NoError(t, err)
+	claims, _ := identity.ClaimsFromContext(ctx)
+	assert.Equal(t, "user", claims.UserID)
+	assert.Equal(t, "req-1", logging.RequestIDFromContext(ctx))
+	assert.Equal(t, "test-agent", session.ClientInfoFromContext(ctx).UserAgent)
+	assert.Equal(t, []string{"req-1"}, stream.header.Get("x-request-id"))
+
+	// Rejections are converted to statuses like unary errors
+	err = handler(nil, &MockServerStream{ctx: context.TODO()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "unauthenticated", errorInfo(t, err).GetReason())
+}
+
+// errorInfo returns the ErrorInfo detail of a status error.
+func errorInfo(t *testing.T, err error) *errdetails.ErrorInfo {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	t.Fatalf("no ErrorInfo in %v", err)
+	return nil
+}
+
+func TestRecoveryInterceptor(t *testing.T) {
+	interceptor := common_grpc.ChainUnaryInterceptors(grpc_server.RecoveryInterceptor, grpc_server.ErrorInterceptor)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		var claims *public_model.CustomClaims
+		return claims.UserID, nil
+	}
+
+	resp, err := interceptor(context.TODO(), nil, &grpc.UnaryServerInfo{FullMethod: "/AuthService/Login"}, handler)
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "An unexpected error occurred", status.Convert(err).Message())
+}
+
+func TestRecoveryStreamInterceptor(t *testing.T) {
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		panic("boom")
+	}
+
+	err := grpc_server.RecoveryStreamInterceptor(nil, &MockServerStream{ctx: context.TODO()}, &grpc.StreamServerInfo{FullMethod: "/grpc.health.v1.Health/Watch"}, handler)
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotContains(t, err.Error(), "boom")
+}
+
+// Test that the server applies the stream interceptors to streaming calls
+func TestAuthGRPCServer_InitServer_StreamInterceptors(t *testing.T) {
+	healthServer := health.NewGRPCServer(health.NewRegistry(time.Second), pb.AuthService_ServiceDesc.ServiceName)
+	s := grpc_server.NewAuthGRPCServer(new(MockAuthService), new(MockSessionService), new(MockAPIKeyService), new(MockAuditService), new(MockPermissionService), new(MockAuthorizer), healthServer, []grpc.UnaryServerInterceptor{}, []grpc.StreamServerInterceptor{
+		grpc_server.ErrorStreamInterceptor,
+		// Health watches are public in the shipped policy, not in this one
+		grpc_server.AuthStreamInterceptor(new(MockTokenService), map[string]struct{}{}),
+	})
+	assert.NoError(t, s.InitServer("127.0.0.1:0", &common_grpc.DefaultListener{}))
+	go s.Run()
+	defer s.Config.GRPCServer.Stop()
+
+	conn, err := grpc.Dial(s.Config.Listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+	watch, err := grpc_health_v1.NewHealthClient(conn).Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	assert.NoError(t, err)
+	_, err = watch.Recv()
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
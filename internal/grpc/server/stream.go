@@ -0,0 +1,24 @@
+package grpcserver
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// serverStream replaces the context of a stream, which stream interceptors pass on to the handler the
+// way unary interceptors pass on a derived context.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// withContext returns the stream with ctx as its context.
+func withContext(stream grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	return &serverStream{ServerStream: stream, ctx: ctx}
+}
@@ -0,0 +1,38 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// TracingInterceptor records a server span for every call, continuing the trace the caller sent in the
+// metadata. It must come first, so that the spans of the other interceptors and of the handler are its
+// children and it sees the codes handler errors are converted to.
+func TracingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startSpan(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		tracing.EndRPC(span, err)
+		return resp, err
+	}
+}
+
+// TracingStreamInterceptor is the stream equivalent of TracingInterceptor. The span lasts as long as
+// the stream.
+func TracingStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startSpan(stream.Context(), info.FullMethod)
+		err := handler(srv, withContext(stream, ctx))
+		tracing.EndRPC(span, err)
+		return err
+	}
+}
+
+// startSpan starts the server span of a call to the full method.
+func startSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	return tracing.Start(tracing.Extract(ctx), tracing.SpanName(fullMethod),
+		trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(tracing.RPCAttributes(fullMethod)...))
+}
@@ -0,0 +1,133 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultWatchInterval is how often Watch re-runs the checks.
+const DefaultWatchInterval = 5 * time.Second
+
+// GRPCServer implements the standard grpc.health.v1.Health service on top of a Registry. The overall
+// health, named by the empty service name, and every listed service share the registry's checks.
+// After Shutdown, every service is reported NOT_SERVING.
+type GRPCServer struct {
+	Registry      *Registry
+	Services      map[string]struct{} // Services that can be asked about by name
+	WatchInterval time.Duration       // How often Watch re-runs the checks
+	grpc_health_v1.UnimplementedHealthServer
+
+	mu       sync.Mutex
+	shutdown chan struct{} // Closed by Shutdown
+}
+
+// NewGRPCServer initializes a new GRPCServer reporting the registry's health for the named services.
+func NewGRPCServer(registry *Registry, services ...string) *GRPCServer {
+	known := make(map[string]struct{}, len(services))
+	for _, service := range services {
+		known[service] = struct{}{}
+	}
+	return &GRPCServer{Registry: registry, Services: known, WatchInterval: DefaultWatchInterval}
+}
+
+// Shutdown reports every service NOT_SERVING from now on and ends the Watch streams, so that the
+// gRPC server can stop gracefully while probes see it going away. It is safe to call more than once.
+func (s *GRPCServer) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shutdown == nil {
+		s.shutdown = make(chan struct{})
+	}
+	select {
+	case <-s.shutdown:
+	default:
+		close(s.shutdown)
+	}
+}
+
+// Check implements grpc_health_v1.HealthServer.
+func (s *GRPCServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if !s.known(req.GetService()) {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: s.status(ctx)}, nil
+}
+
+// Watch implements grpc_health_v1.HealthServer. It sends the current status, then every change until
+// the client goes away or the server shuts down, which is sent as NOT_SERVING before the stream ends.
+// Unknown services are reported as SERVICE_UNKNOWN, as the protocol asks.
+func (s *GRPCServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	ctx := stream.Context()
+	shutdown := s.done()
+	if !s.known(req.GetService()) {
+		if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN}); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-shutdown:
+			return nil
+		}
+	}
+
+	ticker := time.NewTicker(s.WatchInterval)
+	defer ticker.Stop()
+	last := grpc_health_v1.HealthCheckResponse_UNKNOWN
+	for {
+		if current := s.status(ctx); current != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+			last = current
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-shutdown:
+			if last == grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+				return nil
+			}
+			return stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING})
+		case <-ticker.C:
+		}
+	}
+}
+
+// done returns the channel Shutdown closes, creating it for servers not built by NewGRPCServer.
+func (s *GRPCServer) done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shutdown == nil {
+		s.shutdown = make(chan struct{})
+	}
+	return s.shutdown
+}
+
+func (s *GRPCServer) known(service string) bool {
+	if service == "" {
+		return true
+	}
+	_, ok := s.Services[service]
+	return ok
+}
+
+func (s *GRPCServer) status(ctx context.Context) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	select {
+	case <-s.done():
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	default:
+	}
+	if s.Registry.Check(ctx).Status != StatusUp {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_SERVING
+}
+
+// Ensure GRPCServer implements grpc_health_v1.HealthServer.
+var _ grpc_health_v1.HealthServer = (*GRPCServer)(nil)
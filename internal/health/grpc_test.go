@@ -0,0 +1,130 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/health"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// MockWatchServer records the statuses sent on a Watch stream.
+type MockWatchServer struct {
+	grpc.ServerStream
+	ctx context.Context
+
+	mu       sync.Mutex
+	statuses []grpc_health_v1.HealthCheckResponse_ServingStatus
+	sent     chan struct{}
+}
+
+func (m *MockWatchServer) Context() context.Context {
+	return m.ctx
+}
+
+func (m *MockWatchServer) Send(resp *grpc_health_v1.HealthCheckResponse) error {
+	m.mu.Lock()
+	m.statuses = append(m.statuses, resp.Status)
+	m.mu.Unlock()
+	m.sent <- struct{}{}
+	return nil
+}
+
+func (m *MockWatchServer) Statuses() []grpc_health_v1.HealthCheckResponse_ServingStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]grpc_health_v1.HealthCheckResponse_ServingStatus(nil), m.statuses...)
+}
+
+func TestGRPCServer_Check(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	server := health.NewGRPCServer(registry, "AuthService")
+
+	resp, err := server.Check(context.TODO(), &grpc_health_v1.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+
+	registry.Register("user_service", health.CheckerFunc(func(ctx context.Context) error { return errors.New("down") }))
+	resp, err = server.Check(context.TODO(), &grpc_health_v1.HealthCheckRequest{Service: "AuthService"})
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	_, err = server.Check(context.TODO(), &grpc_health_v1.HealthCheckRequest{Service: "UserService"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCServer_Watch(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	var mu sync.Mutex
+	var checkErr error
+	registry.Register("user_service", health.CheckerFunc(func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return checkErr
+	}))
+	server := health.NewGRPCServer(registry, "AuthService")
+	server.WatchInterval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &MockWatchServer{ctx: ctx, sent: make(chan struct{}, 10)}
+
+	done := make(chan error, 1)
+	go func() { done <- server.Watch(&grpc_health_v1.HealthCheckRequest{Service: "AuthService"}, stream) }()
+
+	<-stream.sent
+	mu.Lock()
+	checkErr = errors.New("down")
+	mu.Unlock()
+	<-stream.sent
+	cancel()
+
+	assert.Equal(t, codes.Canceled, status.Code(<-done))
+	// Only changes are sent
+	assert.Equal(t, []grpc_health_v1.HealthCheckResponse_ServingStatus{
+		grpc_health_v1.HealthCheckResponse_SERVING,
+		grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+	}, stream.Statuses())
+}
+
+func TestGRPCServer_Watch_UnknownService(t *testing.T) {
+	server := health.NewGRPCServer(health.NewRegistry(time.Second), "AuthService")
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &MockWatchServer{ctx: ctx, sent: make(chan struct{}, 1)}
+
+	done := make(chan error, 1)
+	go func() { done <- server.Watch(&grpc_health_v1.HealthCheckRequest{Service: "UserService"}, stream) }()
+	<-stream.sent
+	cancel()
+
+	assert.Equal(t, codes.Canceled, status.Code(<-done))
+	assert.Equal(t, []grpc_health_v1.HealthCheckResponse_ServingStatus{grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN}, stream.Statuses())
+}
+
+func TestGRPCServer_Shutdown(t *testing.T) {
+	server := health.NewGRPCServer(health.NewRegistry(time.Second), "AuthService")
+	server.WatchInterval = time.Hour
+	stream := &MockWatchServer{ctx: context.Background(), sent: make(chan struct{}, 10)}
+
+	done := make(chan error, 1)
+	go func() { done <- server.Watch(&grpc_health_v1.HealthCheckRequest{Service: "AuthService"}, stream) }()
+	<-stream.sent
+	server.Shutdown()
+	server.Shutdown()
+
+	// The stream ends without the client going away
+	assert.NoError(t, <-done)
+	assert.Equal(t, []grpc_health_v1.HealthCheckResponse_ServingStatus{
+		grpc_health_v1.HealthCheckResponse_SERVING,
+		grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+	}, stream.Statuses())
+
+	resp, err := server.Check(context.TODO(), &grpc_health_v1.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.Status)
+}
@@ -0,0 +1,157 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// DefaultCheckTimeout bounds a single check when the registry has no timeout of its own.
+const DefaultCheckTimeout = 2 * time.Second
+
+// Status is the outcome of a health check.
+type Status string
+
+// Statuses of a check and of the whole service.
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// IChecker defines a dependency check. Check returns an error describing why the dependency is unusable.
+type IChecker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to IChecker.
+type CheckerFunc func(ctx context.Context) error
+
+// Check implements IChecker.
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the outcome of every registered check. The service is up when every check is.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Registry holds the checks the service's readiness depends on. Components register their own checks.
+type Registry struct {
+	Timeout time.Duration // Time limit of each check
+
+	mu       sync.RWMutex
+	checkers map[string]IChecker
+}
+
+// NewRegistry initializes a new, empty Registry whose checks each run within timeout.
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	return &Registry{Timeout: timeout, checkers: map[string]IChecker{}}
+}
+
+// Register adds a check under name, replacing any check registered under the same name.
+func (r *Registry) Register(name string, checker IChecker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers[name] = checker
+}
+
+// Names returns the names of the registered checks in order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.checkers))
+	for name := range r.checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check runs every registered check concurrently and reports their outcome.
+func (r *Registry) Check(ctx context.Context) *Report {
+	r.mu.RLock()
+	checkers := make(map[string]IChecker, len(r.checkers))
+	for name, checker := range r.checkers {
+		checkers[name] = checker
+	}
+	r.mu.RUnlock()
+
+	report := &Report{Status: StatusUp, Checks: make(map[string]Result, len(checkers))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, checker := range checkers {
+		wg.Add(1)
+		go func(name string, checker IChecker) {
+			defer wg.Done()
+			result := r.run(ctx, checker)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(name, checker)
+	}
+	wg.Wait()
+	return report
+}
+
+// run runs one check within the registry's timeout. A check that does not return in time is reported
+// down without waiting for it.
+func (r *Registry) run(ctx context.Context, checker IChecker) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- checker.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		return Result{Status: StatusDown, Error: err.Error()}
+	}
+	return Result{Status: StatusUp}
+}
+
+// ErrNotConnected is returned by ConnChecker when a client connection cannot currently reach its server.
+var ErrNotConnected = errors.New("not connected")
+
+// ConnChecker checks that a gRPC client connection is usable. An idle connection counts as usable and
+// is asked to connect, since connections only dial on their first call.
+func ConnChecker(conn *grpc.ClientConn) IChecker {
+	return CheckerFunc(func(ctx context.Context) error {
+		switch state := conn.GetState(); state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Idle:
+			conn.Connect()
+			return nil
+		default:
+			return fmt.Errorf("%w: %s", ErrNotConnected, state)
+		}
+	})
+}
+
+// Ensure CheckerFunc implements IChecker.
+var _ IChecker = CheckerFunc(nil)
@@ -0,0 +1,105 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/health"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func up(ctx context.Context) error {
+	return nil
+}
+
+func TestRegistry_Check(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register("signing_keys", health.CheckerFunc(up))
+	registry.Register("user_service", health.CheckerFunc(func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}))
+
+	report := registry.Check(context.TODO())
+
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Equal(t, health.Result{Status: health.StatusUp}, report.Checks["signing_keys"])
+	assert.Equal(t, health.Result{Status: health.StatusDown, Error: "connection refused"}, report.Checks["user_service"])
+	assert.Equal(t, []string{"signing_keys", "user_service"}, registry.Names())
+}
+
+func TestRegistry_Check_AllUp(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register("signing_keys", health.CheckerFunc(up))
+
+	report := registry.Check(context.TODO())
+
+	assert.Equal(t, health.StatusUp, report.Status)
+	// Registering under the same name replaces the check
+	registry.Register("signing_keys", health.CheckerFunc(func(ctx context.Context) error { return errors.New("down") }))
+	assert.Equal(t, health.StatusDown, registry.Check(context.TODO()).Status)
+}
+
+func TestRegistry_Check_Timeout(t *testing.T) {
+	registry := health.NewRegistry(20 * time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	registry.Register("session_store", health.CheckerFunc(func(ctx context.Context) error {
+		// A check that ignores its context does not hold up the report
+		<-release
+		return nil
+	}))
+
+	report := registry.Check(context.TODO())
+
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["session_store"].Error)
+}
+
+func TestConnChecker(t *testing.T) {
+	// Nothing listens on the address once the listener is closed
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+	checker := health.ConnChecker(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for state := conn.GetState(); state != connectivity.TransientFailure; state = conn.GetState() {
+		if !conn.WaitForStateChange(ctx, state) {
+			t.Fatal("connection did not fail")
+		}
+	}
+	err = checker.Check(context.TODO())
+	assert.ErrorIs(t, err, health.ErrNotConnected)
+	assert.ErrorContains(t, err, "TRANSIENT_FAILURE")
+}
+
+func TestConnChecker_Ready(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer()
+	go server.Serve(listener)
+	defer server.Stop()
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+		if !conn.WaitForStateChange(ctx, state) {
+			t.Fatal("connection did not become ready")
+		}
+	}
+	assert.NoError(t, health.ConnChecker(conn).Check(context.TODO()))
+}
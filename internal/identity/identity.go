@@ -0,0 +1,70 @@
+package identity
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/problem"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+)
+
+type contextKey string
+
+const (
+	claimsKey  contextKey = "claims"
+	serviceKey contextKey = "service"
+)
+
+// WithClaims returns a copy of ctx carrying the claims of the authenticated caller.
+func WithClaims(ctx context.Context, claims *public_model.CustomClaims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// ClaimsFromContext returns the claims of the authenticated caller, if any.
+func ClaimsFromContext(ctx context.Context) (*public_model.CustomClaims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*public_model.CustomClaims)
+	return claims, ok && claims != nil
+}
+
+// RequireClaims returns the claims of the authenticated caller, or an unauthenticated problem.
+func RequireClaims(ctx context.Context) (*public_model.CustomClaims, error) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return nil, problem.New(problem.Unauthenticated, "Authorization token is required")
+	}
+	return claims, nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header value.
+func BearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// WithService returns a copy of ctx carrying the identity of the internal service that made the call.
+func WithService(ctx context.Context, service string) context.Context {
+	return context.WithValue(ctx, serviceKey, service)
+}
+
+// ServiceFromContext returns the identity of the internal service that made the call, if any.
+func ServiceFromContext(ctx context.Context) (string, bool) {
+	service, ok := ctx.Value(serviceKey).(string)
+	return service, ok && service != ""
+}
+
+// ServiceFromCertificate returns the service identity a verified client certificate names in its
+// subject alternative names: its first URI, such as a SPIFFE ID, or else its first DNS name.
+func ServiceFromCertificate(cert *x509.Certificate) (string, bool) {
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String(), true
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0], true
+	}
+	return "", false
+}
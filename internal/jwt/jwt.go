@@ -1,6 +1,14 @@
 package jwt
 
-import "github.com/golang-jwt/jwt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/secret"
+	"github.com/golang-jwt/jwt"
+)
 
 // JWTHandler defines methods to generate and parse JWT tokens.
 type JWTHandler interface {
@@ -32,3 +40,82 @@ func (s *SimpleJWTHandler) Parse(tokenString string, claims jwt.Claims) (*jwt.To
 
 // Ensure SimpleJWTHandler implements JWTHandler.
 var _ JWTHandler = (*SimpleJWTHandler)(nil)
+
+// ErrUnknownTenant is returned when claims name a tenant that has no signing key.
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// TenantClaims are claims that name the tenant they were issued for.
+type TenantClaims interface {
+	TenantID() string
+}
+
+// DefaultKeyTimeout bounds the lookup of a signing key by a TenantJWTHandler.
+const DefaultKeyTimeout = 5 * time.Second
+
+// TenantJWTHandler signs and verifies tokens with the key of the tenant named in their claims,
+// so a token issued for one tenant never verifies for another. Keys are looked up in the secret
+// provider on use, so rotated keys take effect without a restart; the provider should cache them.
+type TenantJWTHandler struct {
+	Secrets    secret.ISecretProvider // Source of the signing keys
+	DefaultKey string                 // Path of the key of the default tenant, used for claims without a tenant
+	TenantKeys map[string]string      // Paths of the keys of the other tenants, keyed by tenant ID
+	KeyTimeout time.Duration          // How long a key lookup may take before the token operation fails
+}
+
+// NewTenantJWTHandler initializes a new TenantJWTHandler with the paths of the default key and of the keys of each tenant.
+func NewTenantJWTHandler(secrets secret.ISecretProvider, defaultKey string, tenantKeys map[string]string) *TenantJWTHandler {
+	return &TenantJWTHandler{Secrets: secrets, DefaultKey: defaultKey, TenantKeys: tenantKeys, KeyTimeout: DefaultKeyTimeout}
+}
+
+// key returns the signing key of the tenant the claims were issued for.
+func (t *TenantJWTHandler) key(claims jwt.Claims) ([]byte, error) {
+	path := t.DefaultKey
+	if tenantClaims, ok := claims.(TenantClaims); ok && tenantClaims.TenantID() != "" {
+		path, ok = t.TenantKeys[tenantClaims.TenantID()]
+		if !ok {
+			return nil, ErrUnknownTenant
+		}
+	}
+	// JWTHandler carries no request context, so a slow provider is cut off here rather than blocking the request
+	ctx, cancel := context.WithTimeout(context.Background(), t.KeyTimeout)
+	defer cancel()
+	return t.Secrets.Secret(ctx, path)
+}
+
+// Check reports whether the signing keys of every tenant can be loaded, so that tokens can be issued and
+// verified. It suits a readiness check.
+func (t *TenantJWTHandler) Check(ctx context.Context) error {
+	if _, err := t.Secrets.Secret(ctx, t.DefaultKey); err != nil {
+		return fmt.Errorf("default signing key: %w", err)
+	}
+	for tenantID, path := range t.TenantKeys {
+		if _, err := t.Secrets.Secret(ctx, path); err != nil {
+			return fmt.Errorf("signing key of tenant %s: %w", tenantID, err)
+		}
+	}
+	return nil
+}
+
+// Generate implements JWTHandler.
+func (t *TenantJWTHandler) Generate(claims jwt.Claims) (string, error) {
+	key, err := t.key(claims)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(key)
+}
+
+// Parse implements JWTHandler. The claims are decoded before the signature is checked,
+// and the key is chosen by the tenant they name.
+func (t *TenantJWTHandler) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return t.key(token.Claims)
+	})
+}
+
+// Ensure TenantJWTHandler implements JWTHandler.
+var _ JWTHandler = (*TenantJWTHandler)(nil)
@@ -0,0 +1,186 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// RequestIDHeader is the HTTP header carrying the request ID, on requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMetadataKey is the gRPC metadata key carrying the request ID, in both directions.
+const RequestIDMetadataKey = "x-request-id"
+
+// MaxRequestIDLength bounds the request IDs accepted from callers.
+const MaxRequestIDLength = 128
+
+// Redacted replaces the values of sensitive attributes.
+const Redacted = "[REDACTED]"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// NewRequestID generates a random 128-bit request ID.
+func NewRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// RequestID returns the request ID the caller sent, or a new one when it sent none. IDs longer than
+// MaxRequestIDLength or holding characters other than letters, digits, '-', '_', '.' and ':' are
+// replaced, so that callers cannot forge log lines.
+func RequestID(sent string) (string, error) {
+	if ValidRequestID(sent) {
+		return sent, nil
+	}
+	return NewRequestID()
+}
+
+// ValidRequestID reports whether a request ID sent by a caller can be used as is.
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > MaxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("-_.:", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Handler adds the request ID and trace ID of the context to every record it passes on. The handler it
+// wraps should redact with Redact.
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps handler so that records carry the IDs of their context.
+func NewHandler(handler slog.Handler) *Handler {
+	return &Handler{Handler: handler}
+}
+
+// Handle implements slog.Handler.
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return NewHandler(h.Handler.WithAttrs(attrs))
+}
+
+// WithGroup implements slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return NewHandler(h.Handler.WithGroup(name))
+}
+
+// sensitiveKeys name attributes that are redacted wherever they appear, compared without case,
+// underscores, dashes and a plural s. Keys ending in password, token or secret are redacted too.
+var sensitiveKeys = map[string]bool{
+	"authorization": true,
+	"cookie":        true,
+	"apikey":        true,
+	"key":           true,
+	"hash":          true,
+}
+
+// Sensitive reports whether values logged under the key must be redacted.
+func Sensitive(key string) bool {
+	key = strings.TrimSuffix(strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key)), "s")
+	return sensitiveKeys[key] ||
+		strings.HasSuffix(key, "password") || strings.HasSuffix(key, "token") || strings.HasSuffix(key, "secret")
+}
+
+// Redact replaces the values of sensitive attributes. It suits slog.HandlerOptions.ReplaceAttr, which
+// also passes the members of groups, such as the fields of logged payloads.
+func Redact(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindGroup && Sensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+// JSONValue logs a JSON document as nested groups, so that its sensitive fields are redacted like any
+// other attribute. Documents that cannot be decoded are not logged.
+func JSONValue(data []byte) slog.Value {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return slog.StringValue("[unparsed]")
+	}
+	return value(document)
+}
+
+// ProtoValue logs a message as nested groups named by its proto field names.
+func ProtoValue(message proto.Message) slog.Value {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(message)
+	if err != nil {
+		return slog.StringValue("[unparsed]")
+	}
+	return JSONValue(data)
+}
+
+// value converts a decoded JSON value, with the keys of objects in order.
+func value(v interface{}) slog.Value {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		attrs := make([]slog.Attr, 0, len(v))
+		for _, key := range keys {
+			if Sensitive(key) {
+				// Redacted here as well, as Redact does not see the values of nested objects and arrays
+				attrs = append(attrs, slog.String(key, Redacted))
+				continue
+			}
+			attrs = append(attrs, slog.Attr{Key: key, Value: value(v[key])})
+		}
+		return slog.GroupValue(attrs...)
+	case []interface{}:
+		// Groups keep the elements of arrays apart, so that objects in them are redacted too
+		attrs := make([]slog.Attr, 0, len(v))
+		for i, element := range v {
+			attrs = append(attrs, slog.Attr{Key: strconv.Itoa(i), Value: value(element)})
+		}
+		return slog.GroupValue(attrs...)
+	default:
+		return slog.AnyValue(v)
+	}
+}
+
+// Ensure Handler implements slog.Handler.
+var _ slog.Handler = (*Handler)(nil)
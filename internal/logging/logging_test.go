@@ -0,0 +1,142 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/logging"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/proto/pb"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// newLogger returns a logger writing JSON records to the buffer, as the service does.
+func newLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(logging.NewHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{ReplaceAttr: logging.Redact})))
+}
+
+// decode returns the last record written to the buffer.
+func decode(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &record))
+	return record
+}
+
+func TestRequestID(t *testing.T) {
+	requestID, err := logging.RequestID("req-1.a:b_C")
+	assert.NoError(t, err)
+	assert.Equal(t, "req-1.a:b_C", requestID)
+
+	// Missing and unsafe IDs are replaced with new ones
+	for _, sent := range []string{"", "forged\n{\"level\":\"ERROR\"}", "with space", strings.Repeat("a", logging.MaxRequestIDLength+1)} {
+		requestID, err := logging.RequestID(sent)
+		assert.NoError(t, err)
+		assert.NotEqual(t, sent, requestID)
+		assert.Len(t, requestID, 32)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf)
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}})
+	ctx := trace.ContextWithSpanContext(logging.WithRequestID(context.Background(), "req-1"), spanContext)
+
+	logger.With("component", "test").InfoContext(ctx, "Logged in", "user_id", "user")
+
+	record := decode(t, &buf)
+	assert.Equal(t, "Logged in", record["msg"])
+	assert.Equal(t, "test", record["component"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, spanContext.TraceID().String(), record["trace_id"])
+
+	// Records without IDs in their context carry none
+	logger.Info("Started")
+	record = decode(t, &buf)
+	assert.NotContains(t, record, "request_id")
+	assert.NotContains(t, record, "trace_id")
+}
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf)
+
+	logger.Info("Request",
+		"password", "hunter2",
+		"refresh_token", "refresh",
+		"Authorization", "Bearer access",
+		slog.Group("user", "email", "user@example.com", "new-password", "hunter3"),
+		"token_type", "Bearer",
+	)
+
+	record := decode(t, &buf)
+	assert.Equal(t, logging.Redacted, record["password"])
+	assert.Equal(t, logging.Redacted, record["refresh_token"])
+	assert.Equal(t, logging.Redacted, record["Authorization"])
+	assert.Equal(t, map[string]interface{}{"email": "user@example.com", "new-password": logging.Redacted}, record["user"])
+	assert.Equal(t, "Bearer", record["token_type"])
+	assert.NotContains(t, buf.String(), "hunter")
+}
+
+func TestJSONValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf)
+
+	logger.Info("Request", "request", logging.JSONValue([]byte(`{
+		"identifier": "user",
+		"password": "hunter2",
+		"tokens": ["one", "two"],
+		"clients": [{"id": "web", "secret": "s3cret"}]
+	}`)))
+	logger.Info("Request", "request", logging.JSONValue([]byte(`password=hunter2`)))
+
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.NotContains(t, buf.String(), "s3cret")
+	assert.NotContains(t, buf.String(), `"one"`)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, map[string]interface{}{
+		"identifier": "user",
+		"password":   logging.Redacted,
+		"tokens":     logging.Redacted,
+		"clients":    map[string]interface{}{"0": map[string]interface{}{"id": "web", "secret": logging.Redacted}},
+	}, record["request"])
+	assert.Equal(t, "[unparsed]", decode(t, &buf)["request"])
+}
+
+func TestProtoValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf)
+
+	logger.Info("Request", "request", logging.ProtoValue(&pb.LoginRequest{Email: "user@example.com", Password: "hunter2"}))
+
+	assert.Equal(t, map[string]interface{}{"email": "user@example.com", "password": logging.Redacted}, decode(t, &buf)["request"])
+}
+
+func TestUserServiceInterceptor(t *testing.T) {
+	var sent metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	ctx := metadata.AppendToOutgoingContext(logging.WithRequestID(context.Background(), "req-1"), "authorization", "Bearer token")
+
+	err := logging.UserServiceInterceptor()(ctx, "/UserService/CreateUser", nil, nil, nil, invoker)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"req-1"}, sent.Get(logging.RequestIDMetadataKey))
+	assert.Equal(t, []string{"Bearer token"}, sent.Get("authorization"))
+
+	// Calls outside of a request carry no ID
+	err = logging.UserServiceInterceptor()(context.Background(), "/UserService/CreateUser", nil, nil, nil, invoker)
+	assert.NoError(t, err)
+	assert.Empty(t, sent.Get(logging.RequestIDMetadataKey))
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Namespace prefixes the names of every metric of the service.
+const Namespace = "bitbridge_auth"
+
+// Auth flows counted by AuthFlows.
+const (
+	FlowLogin    = "login"
+	FlowRegister = "register"
+	FlowRefresh  = "refresh"
+)
+
+// Outcomes of auth flows.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Token types counted by TokensIssued.
+const (
+	TokenTypeAccess        = "access"
+	TokenTypeRefresh       = "refresh"
+	TokenTypeService       = "service"
+	TokenTypeAPIKey        = "api_key"
+	TokenTypeImpersonation = "impersonation"
+)
+
+// Metrics holds the collectors of the service. Every collector is registered with the registerer given
+// to NewMetrics, so tests can gather them from a registry of their own.
+type Metrics struct {
+	AuthFlows           *prometheus.CounterVec   // Auth flow attempts by flow, outcome and failure reason
+	AuthFlowDuration    *prometheus.HistogramVec // Auth flow latency by flow
+	TokensIssued        *prometheus.CounterVec   // Issued tokens by type
+	HTTPRequests        *prometheus.CounterVec   // HTTP requests by method, route and status
+	HTTPDuration        *prometheus.HistogramVec // HTTP handler latency by method and route
+	GRPCRequests        *prometheus.CounterVec   // gRPC calls by method and code
+	GRPCDuration        *prometheus.HistogramVec // gRPC handler latency by method
+	UserServiceDuration *prometheus.HistogramVec // Latency of calls to the user service by method and code
+}
+
+// NewMetrics initializes the collectors and registers them with registerer.
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		AuthFlows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "flows_total",
+			Help:      "Login, register and refresh attempts by outcome and failure reason.",
+		}, []string{"flow", "outcome", "reason"}),
+		AuthFlowDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "flow_duration_seconds",
+			Help:      "Time taken by login, register and refresh attempts.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"flow"}),
+		TokensIssued: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "tokens_issued_total",
+			Help:      "Tokens issued by type.",
+		}, []string{"type"}),
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		HTTPDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to handle HTTP requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		GRPCRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "grpc_requests_total",
+			Help:      "gRPC calls handled by method and status code.",
+		}, []string{"method", "code"}),
+		GRPCDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Time taken to handle gRPC calls.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		UserServiceDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "user_service_request_duration_seconds",
+			Help:      "Time taken by calls to the user service by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+	}
+
+	for _, collector := range []prometheus.Collector{
+		m.AuthFlows, m.AuthFlowDuration, m.TokensIssued,
+		m.HTTPRequests, m.HTTPDuration, m.GRPCRequests, m.GRPCDuration,
+		m.UserServiceDuration,
+	} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ObserveFlow records an auth flow attempt that started at start and ended with err.
+func (m *Metrics) ObserveFlow(flow string, start time.Time, err error) {
+	m.AuthFlowDuration.WithLabelValues(flow).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.AuthFlows.WithLabelValues(flow, OutcomeFailure, Reason(err)).Inc()
+		return
+	}
+	m.AuthFlows.WithLabelValues(flow, OutcomeSuccess, "").Inc()
+}
+
+// ObserveHTTP records a handled HTTP request.
+func (m *Metrics) ObserveHTTP(method string, route string, status int, duration time.Duration) {
+	m.HTTPRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.HTTPDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// reasons names the service error codes the auth flows fail with. Reasons are a closed set, so that
+// failures do not create a series per error message.
+var reasons = map[int]string{
+	common_error.BadRequest:         "bad_request",
+	common_error.Unauthorized:       "unauthorized",
+	common_error.InvalidCredentials: "invalid_credentials",
+	common_error.Forbidden:          "forbidden",
+	common_error.NotFound:           "not_found",
+	common_error.Conflict:           "conflict",
+	common_error.TooManyRequests:    "too_many_requests",
+	common_error.ServiceUnavailable: "unavailable",
+	common_error.SessionExpired:     "session_expired",
+	common_error.TokenInvalid:       "token_invalid",
+	common_error.TokenExpired:       "token_expired",
+}
+
+// Reason returns the label a failed auth flow is counted under.
+func Reason(err error) string {
+	var serviceErr *common_error.ServiceError
+	if errors.As(err, &serviceErr) {
+		if reason, ok := reasons[serviceErr.Code]; ok {
+			return reason
+		}
+	}
+	return "internal"
+}
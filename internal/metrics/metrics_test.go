@@ -0,0 +1,81 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/metrics"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newMetrics(t *testing.T) *metrics.Metrics {
+	m, err := metrics.NewMetrics(prometheus.NewRegistry())
+	assert.NoError(t, err)
+	return m
+}
+
+func TestNewMetrics_Registers(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m, err := metrics.NewMetrics(registry)
+	assert.NoError(t, err)
+	m.TokensIssued.WithLabelValues(metrics.TokenTypeAccess).Inc()
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	assert.Equal(t, "bitbridge_auth_tokens_issued_total", families[0].GetName())
+
+	// The same collectors cannot be registered twice
+	_, err = metrics.NewMetrics(registry)
+	assert.Error(t, err)
+}
+
+func TestObserveFlow(t *testing.T) {
+	m := newMetrics(t)
+
+	m.ObserveFlow(metrics.FlowLogin, time.Now(), nil)
+	m.ObserveFlow(metrics.FlowLogin, time.Now(), common_error.NewServiceError(common_error.Unauthorized, "Invalid credentials", nil))
+	m.ObserveFlow(metrics.FlowLogin, time.Now(), common_error.NewServiceError(common_error.Unauthorized, "Account locked", nil))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.AuthFlows.WithLabelValues("login", "success", "")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.AuthFlows.WithLabelValues("login", "failure", "unauthorized")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.AuthFlowDuration))
+}
+
+func TestReason(t *testing.T) {
+	assert.Equal(t, "conflict", metrics.Reason(common_error.NewServiceError(common_error.Conflict, "User already exists", nil)))
+	assert.Equal(t, "session_expired", metrics.Reason(common_error.NewServiceError(common_error.SessionExpired, "Session expired", nil)))
+	assert.Equal(t, "invalid_credentials", metrics.Reason(common_error.NewServiceError(common_error.InvalidCredentials, "Invalid credentials", nil)))
+	// Unknown codes and plain errors do not leak their messages into labels
+	assert.Equal(t, "internal", metrics.Reason(common_error.NewServiceError(common_error.PasswordHashingFailed, "bcrypt failed", nil)))
+	assert.Equal(t, "internal", metrics.Reason(errors.New("boom")))
+}
+
+func TestObserveHTTP(t *testing.T) {
+	m := newMetrics(t)
+
+	m.ObserveHTTP("DELETE", "/sessions/:id", 204, time.Millisecond)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.HTTPRequests.WithLabelValues("DELETE", "/sessions/:id", "204")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.HTTPDuration))
+}
+
+func TestUserServiceInterceptor(t *testing.T) {
+	m := newMetrics(t)
+	interceptor := metrics.UserServiceInterceptor(m)
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Error(codes.NotFound, "user not found")
+	}
+
+	err := interceptor(context.TODO(), "/UserService/GetUserByEmail", nil, nil, nil, invoker)
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.UserServiceDuration, "bitbridge_auth_user_service_request_duration_seconds"))
+}
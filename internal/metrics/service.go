@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/auth"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// AuthService counts the outcomes and measures the latency of the auth flows of the service it wraps.
+type AuthService struct {
+	auth.IAuthService
+	Metrics *Metrics
+}
+
+// NewAuthService wraps authService so that its login, register and refresh flows are measured.
+func NewAuthService(authService auth.IAuthService, metrics *Metrics) *AuthService {
+	return &AuthService{IAuthService: authService, Metrics: metrics}
+}
+
+// Login implements auth.IAuthService.
+func (a *AuthService) Login(ctx context.Context, loginModel *public_model.LoginModel) (*public_model.TokenModel, error) {
+	start := time.Now()
+	tokenModel, err := a.IAuthService.Login(ctx, loginModel)
+	a.Metrics.ObserveFlow(FlowLogin, start, err)
+	return tokenModel, err
+}
+
+// Register implements auth.IAuthService.
+func (a *AuthService) Register(ctx context.Context, registerModel *public_model.RegisterModel) (*public_model.TokenModel, error) {
+	start := time.Now()
+	tokenModel, err := a.IAuthService.Register(ctx, registerModel)
+	a.Metrics.ObserveFlow(FlowRegister, start, err)
+	return tokenModel, err
+}
+
+// Refresh implements auth.IAuthService.
+func (a *AuthService) Refresh(ctx context.Context, refreshModel *public_model.TokenRefreshModel) (*public_model.TokenModel, error) {
+	start := time.Now()
+	tokenModel, err := a.IAuthService.Refresh(ctx, refreshModel)
+	a.Metrics.ObserveFlow(FlowRefresh, start, err)
+	return tokenModel, err
+}
+
+// TokenService counts the tokens issued by the service it wraps.
+type TokenService struct {
+	token.ITokenService
+	Metrics *Metrics
+}
+
+// NewTokenService wraps tokenService so that the tokens it issues are counted.
+func NewTokenService(tokenService token.ITokenService, metrics *Metrics) *TokenService {
+	return &TokenService{ITokenService: tokenService, Metrics: metrics}
+}
+
+// CreateToken implements token.ITokenService.
+func (t *TokenService) CreateToken(ctx context.Context, userID string, duration time.Duration) (string, error) {
+	tokenString, err := t.ITokenService.CreateToken(ctx, userID, duration)
+	t.issued(err, TokenTypeAccess)
+	return tokenString, err
+}
+
+// CreateServiceToken implements token.ITokenService.
+func (t *TokenService) CreateServiceToken(ctx context.Context) (string, error) {
+	tokenString, err := t.ITokenService.CreateServiceToken(ctx)
+	t.issued(err, TokenTypeService)
+	return tokenString, err
+}
+
+// CreateAPIKeyToken implements token.ITokenService.
+func (t *TokenService) CreateAPIKeyToken(ctx context.Context, grant *token.APIKeyGrant) (*public_model.AccessTokenModel, error) {
+	tokenModel, err := t.ITokenService.CreateAPIKeyToken(ctx, grant)
+	t.issued(err, TokenTypeAPIKey)
+	return tokenModel, err
+}
+
+// CreateImpersonationToken implements token.ITokenService.
+func (t *TokenService) CreateImpersonationToken(ctx context.Context, subject *token.Subject, actor *public_model.CustomClaims) (*public_model.AccessTokenModel, error) {
+	tokenModel, err := t.ITokenService.CreateImpersonationToken(ctx, subject, actor)
+	t.issued(err, TokenTypeImpersonation)
+	return tokenModel, err
+}
+
+// CreateTokenPair implements token.ITokenService.
+func (t *TokenService) CreateTokenPair(ctx context.Context, subject *token.Subject, policy token.Policy) (*public_model.TokenModel, error) {
+	tokenModel, err := t.ITokenService.CreateTokenPair(ctx, subject, policy)
+	t.issued(err, TokenTypeAccess, TokenTypeRefresh)
+	return tokenModel, err
+}
+
+func (t *TokenService) issued(err error, tokenTypes ...string) {
+	if err != nil {
+		return
+	}
+	for _, tokenType := range tokenTypes {
+		t.Metrics.TokensIssued.WithLabelValues(tokenType).Inc()
+	}
+}
+
+// UserServiceInterceptor measures the calls made to the user service. It is installed on the client
+// connection to the user service.
+func UserServiceInterceptor(metrics *Metrics) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		metrics.UserServiceDuration.WithLabelValues(method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// Ensure the decorators implement the interfaces they wrap.
+var (
+	_ auth.IAuthService   = (*AuthService)(nil)
+	_ token.ITokenService = (*TokenService)(nil)
+)
@@ -0,0 +1,92 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/auth"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/metrics"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuthService struct {
+	mock.Mock
+	auth.IAuthService
+}
+
+func (m *MockAuthService) Login(ctx context.Context, loginModel *public_model.LoginModel) (*public_model.TokenModel, error) {
+	args := m.Called(ctx, loginModel)
+	return args.Get(0).(*public_model.TokenModel), args.Error(1)
+}
+
+func (m *MockAuthService) Register(ctx context.Context, registerModel *public_model.RegisterModel) (*public_model.TokenModel, error) {
+	args := m.Called(ctx, registerModel)
+	return args.Get(0).(*public_model.TokenModel), args.Error(1)
+}
+
+func (m *MockAuthService) Refresh(ctx context.Context, refreshModel *public_model.TokenRefreshModel) (*public_model.TokenModel, error) {
+	args := m.Called(ctx, refreshModel)
+	return args.Get(0).(*public_model.TokenModel), args.Error(1)
+}
+
+type MockTokenService struct {
+	mock.Mock
+	token.ITokenService
+}
+
+func (m *MockTokenService) CreateTokenPair(ctx context.Context, subject *token.Subject, policy token.Policy) (*public_model.TokenModel, error) {
+	args := m.Called(ctx, subject, policy)
+	return args.Get(0).(*public_model.TokenModel), args.Error(1)
+}
+
+func (m *MockTokenService) CreateAPIKeyToken(ctx context.Context, grant *token.APIKeyGrant) (*public_model.AccessTokenModel, error) {
+	args := m.Called(ctx, grant)
+	return args.Get(0).(*public_model.AccessTokenModel), args.Error(1)
+}
+
+func TestAuthService_Flows(t *testing.T) {
+	m := newMetrics(t)
+	mockAuthService := new(MockAuthService)
+	loginModel := &public_model.LoginModel{Identifier: "user", Password: "password"}
+	registerModel := &public_model.RegisterModel{Username: "user"}
+	mockAuthService.On("Login", mock.Anything, loginModel).Return(&public_model.TokenModel{}, nil)
+	mockAuthService.On("Register", mock.Anything, registerModel).Return((*public_model.TokenModel)(nil), common_error.NewServiceError(common_error.Conflict, "User already exists", nil))
+	mockAuthService.On("Refresh", mock.Anything, mock.Anything).Return((*public_model.TokenModel)(nil), common_error.NewServiceError(common_error.SessionExpired, "Session expired", nil))
+	authService := metrics.NewAuthService(mockAuthService, m)
+
+	_, err := authService.Login(context.TODO(), loginModel)
+	assert.NoError(t, err)
+	_, err = authService.Register(context.TODO(), registerModel)
+	assert.Error(t, err)
+	_, err = authService.Refresh(context.TODO(), &public_model.TokenRefreshModel{Token: "refresh"})
+	assert.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.AuthFlows.WithLabelValues("login", "success", "")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.AuthFlows.WithLabelValues("register", "failure", "conflict")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.AuthFlows.WithLabelValues("refresh", "failure", "session_expired")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.AuthFlowDuration))
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestTokenService_CountsIssuedTokens(t *testing.T) {
+	m := newMetrics(t)
+	mockTokenService := new(MockTokenService)
+	mockTokenService.On("CreateTokenPair", mock.Anything, mock.Anything, mock.Anything).Return(&public_model.TokenModel{}, nil)
+	mockTokenService.On("CreateAPIKeyToken", mock.Anything, mock.Anything).Return((*public_model.AccessTokenModel)(nil), common_error.NewServiceError(common_error.BadRequest, "Unknown tenant", nil))
+	tokenService := metrics.NewTokenService(mockTokenService, m)
+
+	_, err := tokenService.CreateTokenPair(context.TODO(), &token.Subject{}, token.Policy{})
+	assert.NoError(t, err)
+	_, err = tokenService.CreateAPIKeyToken(context.TODO(), &token.APIKeyGrant{})
+	assert.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.TokensIssued.WithLabelValues("access")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.TokensIssued.WithLabelValues("refresh")))
+	// Failed issuance is not counted
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.TokensIssued.WithLabelValues("api_key")))
+}
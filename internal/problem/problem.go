@@ -0,0 +1,246 @@
+package problem
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/apikey"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/auth"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/validation"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ContentType is the media type of RFC 7807 problem documents.
+const ContentType = "application/problem+json"
+
+// Domain identifies this service in gRPC ErrorInfo details.
+const Domain = "auth.bitbridge"
+
+// Code is a stable, machine-readable error code shared by the HTTP and gRPC APIs.
+type Code string
+
+const (
+	InvalidRequest      Code = "invalid_request"
+	ValidationFailed    Code = "validation_failed"
+	InvalidCredentials  Code = "invalid_credentials"
+	AccountLocked       Code = "account_locked"
+	MFARequired         Code = "mfa_required"
+	InvalidAPIKey       Code = "invalid_api_key"
+	APIKeyExpired       Code = "api_key_expired"
+	Unauthenticated     Code = "unauthenticated"
+	InvalidToken        Code = "invalid_token"
+	TokenExpired        Code = "token_expired"
+	SessionExpired      Code = "session_expired"
+	Forbidden           Code = "forbidden"
+	NotFound            Code = "not_found"
+	Conflict            Code = "conflict"
+	PayloadTooLarge     Code = "payload_too_large"
+	RateLimited         Code = "rate_limited"
+	UpstreamUnavailable Code = "upstream_unavailable"
+	Timeout             Code = "timeout"
+	NotImplemented      Code = "not_implemented"
+	Internal            Code = "internal_error"
+)
+
+// codeInfo holds the transport-specific representation of a Code.
+type codeInfo struct {
+	HTTPStatus int
+	GRPCCode   codes.Code
+}
+
+var codeInfos = map[Code]codeInfo{
+	InvalidRequest:      {http.StatusBadRequest, codes.InvalidArgument},
+	ValidationFailed:    {http.StatusBadRequest, codes.InvalidArgument},
+	InvalidCredentials:  {http.StatusUnauthorized, codes.Unauthenticated},
+	AccountLocked:       {http.StatusLocked, codes.PermissionDenied},
+	MFARequired:         {http.StatusUnauthorized, codes.Unauthenticated},
+	InvalidAPIKey:       {http.StatusUnauthorized, codes.Unauthenticated},
+	APIKeyExpired:       {http.StatusUnauthorized, codes.Unauthenticated},
+	Unauthenticated:     {http.StatusUnauthorized, codes.Unauthenticated},
+	InvalidToken:        {http.StatusUnauthorized, codes.Unauthenticated},
+	TokenExpired:        {http.StatusUnauthorized, codes.Unauthenticated},
+	SessionExpired:      {http.StatusUnauthorized, codes.Unauthenticated},
+	Forbidden:           {http.StatusForbidden, codes.PermissionDenied},
+	NotFound:            {http.StatusNotFound, codes.NotFound},
+	Conflict:            {http.StatusConflict, codes.AlreadyExists},
+	PayloadTooLarge:     {http.StatusRequestEntityTooLarge, codes.ResourceExhausted},
+	RateLimited:         {http.StatusTooManyRequests, codes.ResourceExhausted},
+	UpstreamUnavailable: {http.StatusServiceUnavailable, codes.Unavailable},
+	Timeout:             {http.StatusGatewayTimeout, codes.DeadlineExceeded},
+	NotImplemented:      {http.StatusNotImplemented, codes.Unimplemented},
+	Internal:            {http.StatusInternalServerError, codes.Internal},
+}
+
+// serviceErrorCodes maps common service error codes to problem codes.
+var serviceErrorCodes = map[int]Code{
+	common_error.InternalServerError:        Internal,
+	common_error.NotFound:                   NotFound,
+	common_error.BadRequest:                 InvalidRequest,
+	common_error.Unauthorized:               Unauthenticated,
+	common_error.Forbidden:                  Forbidden,
+	common_error.Conflict:                   Conflict,
+	common_error.TooManyRequests:            RateLimited,
+	common_error.ServiceUnavailable:         UpstreamUnavailable,
+	common_error.NotImplemented:             NotImplemented,
+	common_error.Timeout:                    Timeout,
+	common_error.InvalidCredentials:         InvalidCredentials,
+	common_error.AccessDenied:               Forbidden,
+	common_error.SessionExpired:             SessionExpired,
+	common_error.TokenInvalid:               InvalidToken,
+	common_error.TokenExpired:               TokenExpired,
+	common_error.EmailAlreadyExists:         Conflict,
+	common_error.UsernameAlreadyExists:      Conflict,
+	common_error.InvalidInputData:           InvalidRequest,
+	common_error.MissingRequiredField:       InvalidRequest,
+	common_error.InvalidEmailFormat:         InvalidRequest,
+	common_error.NetworkError:               UpstreamUnavailable,
+	common_error.ServiceCommunicationFailed: UpstreamUnavailable,
+}
+
+// causeCodes maps the causes of service errors that need a more specific problem code than their
+// service error code gives, so that clients can tell, say, a rejected API key from other failed
+// authentication.
+var causeCodes = map[error]Code{
+	auth.ErrAccountLocked: AccountLocked,
+	auth.ErrMFARequired:   MFARequired,
+	apikey.ErrInvalidKey:  InvalidAPIKey,
+	apikey.ErrKeyExpired:  APIKeyExpired,
+}
+
+// grpcCodes maps gRPC status codes to problem codes.
+var grpcCodes = map[codes.Code]Code{
+	codes.InvalidArgument:    InvalidRequest,
+	codes.FailedPrecondition: InvalidRequest,
+	codes.OutOfRange:         InvalidRequest,
+	codes.Unauthenticated:    Unauthenticated,
+	codes.PermissionDenied:   Forbidden,
+	codes.NotFound:           NotFound,
+	codes.AlreadyExists:      Conflict,
+	codes.ResourceExhausted:  RateLimited,
+	codes.Unavailable:        UpstreamUnavailable,
+	codes.DeadlineExceeded:   Timeout,
+	codes.Unimplemented:      NotImplemented,
+}
+
+// httpCodes maps HTTP status codes raised by Fiber itself to problem codes.
+var httpCodes = map[int]Code{
+	http.StatusBadRequest:            InvalidRequest,
+	http.StatusUnauthorized:          Unauthenticated,
+	http.StatusForbidden:             Forbidden,
+	http.StatusNotFound:              NotFound,
+	http.StatusMethodNotAllowed:      NotFound,
+	http.StatusConflict:              Conflict,
+	http.StatusRequestEntityTooLarge: PayloadTooLarge,
+	http.StatusTooManyRequests:       RateLimited,
+	http.StatusServiceUnavailable:    UpstreamUnavailable,
+	http.StatusNotImplemented:        NotImplemented,
+}
+
+// Problem is an RFC 7807 problem document extended with a stable error code.
+type Problem struct {
+	Type          string                      `json:"type"`
+	Title         string                      `json:"title"`
+	Status        int                         `json:"status"`
+	Detail        string                      `json:"detail,omitempty"`
+	Code          Code                        `json:"code"`
+	InvalidParams []validation.FieldViolation `json:"invalid_params,omitempty"`
+}
+
+// New creates a Problem for the given code with a human-readable detail.
+func New(code Code, detail string) *Problem {
+	info, ok := codeInfos[code]
+	if !ok {
+		code, info = Internal, codeInfos[Internal]
+	}
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(info.HTTPStatus),
+		Status: info.HTTPStatus,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Error implements error so handlers and middleware can return a Problem directly.
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	return p.Title
+}
+
+// FromError classifies an error returned by a handler into a Problem.
+// Unrecognised errors become internal errors without leaking their message.
+func FromError(err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) {
+		p := New(ValidationFailed, "The request contains invalid fields")
+		p.InvalidParams = validationErr.Violations
+		return p
+	}
+
+	var serviceErr *common_error.ServiceError
+	if errors.As(err, &serviceErr) {
+		for cause, code := range causeCodes {
+			if errors.Is(serviceErr.Cause, cause) {
+				return New(code, serviceErr.Message)
+			}
+		}
+		if code, ok := serviceErrorCodes[serviceErr.Code]; ok {
+			return New(code, serviceErr.Message)
+		}
+		return New(Internal, "An unexpected error occurred")
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		if code, ok := httpCodes[fiberErr.Code]; ok {
+			return New(code, fiberErr.Message)
+		}
+		return New(Internal, "An unexpected error occurred")
+	}
+
+	if st, ok := status.FromError(err); ok && err != nil {
+		if code, ok := grpcCodes[st.Code()]; ok {
+			return New(code, st.Message())
+		}
+	}
+
+	return New(Internal, "An unexpected error occurred")
+}
+
+// GRPCStatus renders the problem as a gRPC status carrying ErrorInfo and, for validation
+// failures, BadRequest details.
+func (p *Problem) GRPCStatus() *status.Status {
+	st := status.New(codeInfos[p.Code].GRPCCode, p.Error())
+
+	errorInfo := &errdetails.ErrorInfo{Reason: string(p.Code), Domain: Domain}
+
+	var detailed *status.Status
+	var err error
+	if len(p.InvalidParams) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range p.InvalidParams {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+		detailed, err = st.WithDetails(errorInfo, badRequest)
+	} else {
+		detailed, err = st.WithDetails(errorInfo)
+	}
+	if err != nil {
+		return st
+	}
+	return detailed
+}
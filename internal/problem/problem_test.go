@@ -0,0 +1,110 @@
+package problem_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/apikey"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/auth"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/problem"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/validation"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   problem.Code
+		status int
+		detail string
+	}{
+		{"validation", &validation.Error{Violations: []validation.FieldViolation{{Field: "email", Description: "must not be empty"}}}, problem.ValidationFailed, http.StatusBadRequest, "The request contains invalid fields"},
+		{"service error", common_error.NewServiceError(common_error.InvalidCredentials, "Invalid credentials", nil), problem.InvalidCredentials, http.StatusUnauthorized, "Invalid credentials"},
+		{"unauthorized", common_error.NewServiceError(common_error.Unauthorized, "Invalid API key", nil), problem.Unauthenticated, http.StatusUnauthorized, "Invalid API key"},
+		{"service unavailable", common_error.NewServiceError(common_error.ServiceUnavailable, "User service unavailable", nil), problem.UpstreamUnavailable, http.StatusServiceUnavailable, "User service unavailable"},
+		{"unknown service error", common_error.NewServiceError(9999, "boom", nil), problem.Internal, http.StatusInternalServerError, "An unexpected error occurred"},
+		{"fiber error", fiber.NewError(fiber.StatusBadRequest, "Invalid request body"), problem.InvalidRequest, http.StatusBadRequest, "Invalid request body"},
+		{"fiber body limit", fiber.ErrRequestEntityTooLarge, problem.PayloadTooLarge, http.StatusRequestEntityTooLarge, "Request Entity Too Large"},
+		{"grpc status", status.Error(codes.Unavailable, "connection refused"), problem.UpstreamUnavailable, http.StatusServiceUnavailable, "connection refused"},
+		{"plain error", errors.New("secret internals"), problem.Internal, http.StatusInternalServerError, "An unexpected error occurred"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := problem.FromError(tt.err)
+
+			assert.Equal(t, tt.code, p.Code)
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, tt.detail, p.Detail)
+			assert.Equal(t, http.StatusText(tt.status), p.Title)
+		})
+	}
+}
+
+func TestFromError_Cause(t *testing.T) {
+	tests := []struct {
+		err    error
+		code   problem.Code
+		status int
+	}{
+		{common_error.NewServiceError(common_error.Unauthorized, "Invalid API key", apikey.ErrInvalidKey), problem.InvalidAPIKey, http.StatusUnauthorized},
+		{common_error.NewServiceError(common_error.TokenExpired, "API key has expired", apikey.ErrKeyExpired), problem.APIKeyExpired, http.StatusUnauthorized},
+		{common_error.NewServiceError(common_error.Forbidden, "Account is locked", auth.ErrAccountLocked), problem.AccountLocked, http.StatusLocked},
+		{common_error.NewServiceError(common_error.Unauthorized, "Multi-factor authentication is required", auth.ErrMFARequired), problem.MFARequired, http.StatusUnauthorized},
+		// Other causes are classified by the service error code
+		{common_error.NewServiceError(common_error.TokenExpired, "Token has expired", errors.New("exp")), problem.TokenExpired, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		p := problem.FromError(tt.err)
+
+		assert.Equal(t, tt.code, p.Code)
+		assert.Equal(t, tt.status, p.Status)
+		assert.Equal(t, tt.err.Error(), p.Detail)
+	}
+
+	assert.Equal(t, codes.PermissionDenied, problem.New(problem.AccountLocked, "").GRPCStatus().Code())
+}
+
+func TestGRPCStatus_ErrorInfo(t *testing.T) {
+	st := problem.New(problem.InvalidCredentials, "Invalid credentials").GRPCStatus()
+
+	assert.Equal(t, codes.Unauthenticated, st.Code())
+	assert.Equal(t, "Invalid credentials", st.Message())
+	assert.Len(t, st.Details(), 1)
+
+	errorInfo, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.True(t, ok)
+	assert.Equal(t, "invalid_credentials", errorInfo.GetReason())
+	assert.Equal(t, problem.Domain, errorInfo.GetDomain())
+}
+
+func TestGRPCStatus_BadRequest(t *testing.T) {
+	err := &validation.Error{Violations: []validation.FieldViolation{{Field: "email", Description: "must not be empty"}}}
+
+	st := problem.FromError(err).GRPCStatus()
+
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Len(t, st.Details(), 2)
+
+	badRequest, ok := st.Details()[1].(*errdetails.BadRequest)
+	assert.True(t, ok)
+	assert.Equal(t, "email", badRequest.GetFieldViolations()[0].GetField())
+}
+
+func TestFromError_Problem(t *testing.T) {
+	original := problem.New(problem.Unauthenticated, "Authorization token is required")
+
+	p := problem.FromError(original)
+
+	assert.Same(t, original, p)
+	assert.Equal(t, http.StatusUnauthorized, p.Status)
+	assert.Equal(t, "Authorization token is required", p.Error())
+}
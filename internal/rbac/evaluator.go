@@ -0,0 +1,197 @@
+package rbac
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+)
+
+// Wildcard grants every permission. A permission ending in ":*" grants everything under its prefix.
+const Wildcard = "*"
+
+// IEvaluator answers access control questions against a policy.
+type IEvaluator interface {
+	HasPermission(roles []string, permission string) bool
+	IsPublicMethod(method string) bool
+	MethodPermission(method string) (string, bool)
+	IsPublicRoute(route string) bool
+	RoutePermission(route string) (string, bool)
+	ServiceAllowed(method string, service string) bool
+}
+
+// Evaluator is an IEvaluator over a policy whose role inheritance has been resolved.
+type Evaluator struct {
+	permissions map[string][]string            // Effective permissions keyed by role
+	users       map[string]map[string][]string // Assigned roles keyed by tenant and user ID
+	publicGRPC  map[string]struct{}
+	publicHTTP  map[string]struct{}
+	grpc        map[string]string
+	http        map[string]string
+	services    map[string][]string // Service identities allowed keyed by restricted gRPC method
+}
+
+// NewEvaluator resolves the roles of the policy. It fails on unknown or cyclic inherited roles and on
+// users assigned unknown roles.
+func NewEvaluator(policy *Policy) (*Evaluator, error) {
+	e := &Evaluator{
+		permissions: make(map[string][]string, len(policy.Roles)),
+		publicGRPC:  toSet(policy.Public.GRPC),
+		publicHTTP:  toSet(policy.Public.HTTP),
+		users:       policy.Users,
+		grpc:        policy.GRPC,
+		http:        policy.HTTP,
+		services:    policy.Services,
+	}
+	for name := range policy.Roles {
+		permissions, err := resolve(policy, name, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+		e.permissions[name] = permissions
+	}
+	for tenantID, users := range policy.Users {
+		for userID, roles := range users {
+			for _, role := range roles {
+				if _, ok := policy.Roles[role]; !ok {
+					return nil, fmt.Errorf("rbac policy: user %q of tenant %q is assigned unknown role %q", userID, tenantID, role)
+				}
+			}
+		}
+	}
+	for method, permission := range policy.GRPC {
+		if permission == "" {
+			return nil, fmt.Errorf("rbac policy: gRPC method %q has no permission", method)
+		}
+	}
+	for route, permission := range policy.HTTP {
+		if permission == "" {
+			return nil, fmt.Errorf("rbac policy: HTTP route %q has no permission", route)
+		}
+	}
+	for method, services := range policy.Services {
+		for _, service := range services {
+			if service == "" {
+				return nil, fmt.Errorf("rbac policy: gRPC method %q allows an empty service identity", method)
+			}
+		}
+	}
+	return e, nil
+}
+
+// resolve collects the permissions of a role and the roles it inherits.
+func resolve(policy *Policy, name string, visiting map[string]bool) ([]string, error) {
+	role, ok := policy.Roles[name]
+	if !ok {
+		return nil, fmt.Errorf("rbac policy: unknown role %q", name)
+	}
+	if visiting[name] {
+		return nil, fmt.Errorf("rbac policy: role %q inherits itself", name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	permissions := append([]string(nil), role.Permissions...)
+	for _, parent := range role.Inherits {
+		inherited, err := resolve(policy, parent, visiting)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, inherited...)
+	}
+	return permissions, nil
+}
+
+// HasPermission reports whether any of the roles grants the permission. Unknown roles grant nothing.
+func (e *Evaluator) HasPermission(roles []string, permission string) bool {
+	for _, role := range roles {
+		for _, granted := range e.permissions[role] {
+			if matches(granted, permission) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UserRoles returns the roles assigned to the user of the tenant, if any. User IDs are only unique
+// within a tenant, so assignments never carry over to another tenant.
+func (e *Evaluator) UserRoles(tenantID string, userID string) []string {
+	return e.users[tenantID][userID]
+}
+
+// IsPublicMethod reports whether the gRPC method may be called without a token.
+func (e *Evaluator) IsPublicMethod(method string) bool {
+	_, ok := e.publicGRPC[method]
+	return ok
+}
+
+// MethodPermission returns the permission the gRPC method requires.
+func (e *Evaluator) MethodPermission(method string) (string, bool) {
+	permission, ok := e.grpc[method]
+	return permission, ok
+}
+
+// IsPublicRoute reports whether the HTTP route ("METHOD /path") may be called without a token.
+func (e *Evaluator) IsPublicRoute(route string) bool {
+	_, ok := e.publicHTTP[route]
+	return ok
+}
+
+// RoutePermission returns the permission the HTTP route ("METHOD /path") requires.
+func (e *Evaluator) RoutePermission(route string) (string, bool) {
+	permission, ok := e.http[route]
+	return permission, ok
+}
+
+// ServiceAllowed reports whether the internal service may call the gRPC method. Methods the policy does
+// not restrict allow every caller; restricted ones require a verified service, one of those listed if any.
+// An empty service stands for a caller without a verified identity.
+func (e *Evaluator) ServiceAllowed(method string, service string) bool {
+	allowed, restricted := e.services[method]
+	if !restricted {
+		return true
+	}
+	if service == "" {
+		return false
+	}
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, s := range allowed {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicMethods returns the gRPC methods that need no token, in the form the auth interceptor expects.
+func (e *Evaluator) PublicMethods() map[string]struct{} {
+	return e.publicGRPC
+}
+
+// matches reports whether a granted permission covers the wanted one.
+func matches(granted, wanted string) bool {
+	if granted == Wildcard || granted == wanted {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(granted, Wildcard); ok && strings.HasSuffix(prefix, ":") {
+		return strings.HasPrefix(wanted, prefix)
+	}
+	return false
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
+
+// Ensure Evaluator implements IEvaluator.
+var _ IEvaluator = (*Evaluator)(nil)
+
+// Ensure Evaluator implements token.IRoleSource.
+var _ token.IRoleSource = (*Evaluator)(nil)
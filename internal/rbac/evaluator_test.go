@@ -0,0 +1,144 @@
+package rbac_test
+
+import (
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/rbac"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPolicy = `
+roles:
+  user:
+    permissions: [sessions:read]
+  support:
+    inherits: [user]
+    permissions: ["users:*"]
+  admin:
+    inherits: [support]
+    permissions: ["*"]
+users:
+  "":
+    "1": [admin]
+  acme:
+    "2": [support]
+public:
+  grpc: [/AuthService/Login]
+  http: [POST /login]
+grpc:
+  /AuthService/ListSessions: sessions:read
+http:
+  GET /sessions: sessions:read
+services:
+  /AuthService/CheckPermission: []
+  /AuthService/Authorize: [spiffe://bitbridge/gateway]
+`
+
+func newEvaluator(t *testing.T) *rbac.Evaluator {
+	policy, err := rbac.ParsePolicy([]byte(testPolicy), ".yaml")
+	assert.NoError(t, err)
+	evaluator, err := rbac.NewEvaluator(policy)
+	assert.NoError(t, err)
+	return evaluator
+}
+
+func TestHasPermission(t *testing.T) {
+	evaluator := newEvaluator(t)
+
+	assert.True(t, evaluator.HasPermission([]string{"user"}, "sessions:read"))
+	assert.False(t, evaluator.HasPermission([]string{"user"}, "sessions:revoke"))
+	assert.True(t, evaluator.HasPermission([]string{"support"}, "sessions:read"))
+	assert.True(t, evaluator.HasPermission([]string{"support"}, "users:read"))
+	assert.False(t, evaluator.HasPermission([]string{"support"}, "usersettings:read"))
+	assert.True(t, evaluator.HasPermission([]string{"admin"}, "anything:at:all"))
+	assert.True(t, evaluator.HasPermission([]string{"unknown", "user"}, "sessions:read"))
+	assert.False(t, evaluator.HasPermission(nil, "sessions:read"))
+}
+
+func TestUserRoles(t *testing.T) {
+	evaluator := newEvaluator(t)
+
+	assert.Equal(t, []string{"admin"}, evaluator.UserRoles("", "1"))
+	assert.Empty(t, evaluator.UserRoles("", "2"))
+	assert.Equal(t, []string{"support"}, evaluator.UserRoles("acme", "2"))
+	assert.Empty(t, evaluator.UserRoles("acme", "1"))
+}
+
+func TestEndpoints(t *testing.T) {
+	evaluator := newEvaluator(t)
+
+	assert.True(t, evaluator.IsPublicMethod("/AuthService/Login"))
+	assert.False(t, evaluator.IsPublicMethod("/AuthService/ListSessions"))
+	assert.Equal(t, map[string]struct{}{"/AuthService/Login": {}}, evaluator.PublicMethods())
+
+	permission, ok := evaluator.MethodPermission("/AuthService/ListSessions")
+	assert.True(t, ok)
+	assert.Equal(t, "sessions:read", permission)
+	_, ok = evaluator.MethodPermission("/AuthService/Unlisted")
+	assert.False(t, ok)
+
+	assert.True(t, evaluator.IsPublicRoute("POST /login"))
+	permission, ok = evaluator.RoutePermission("GET /sessions")
+	assert.True(t, ok)
+	assert.Equal(t, "sessions:read", permission)
+}
+
+func TestServiceAllowed(t *testing.T) {
+	evaluator := newEvaluator(t)
+
+	assert.True(t, evaluator.ServiceAllowed("/AuthService/Login", ""))
+	assert.True(t, evaluator.ServiceAllowed("/AuthService/CheckPermission", "spiffe://bitbridge/user-service"))
+	assert.False(t, evaluator.ServiceAllowed("/AuthService/CheckPermission", ""))
+	assert.True(t, evaluator.ServiceAllowed("/AuthService/Authorize", "spiffe://bitbridge/gateway"))
+	assert.False(t, evaluator.ServiceAllowed("/AuthService/Authorize", "spiffe://bitbridge/user-service"))
+	assert.False(t, evaluator.ServiceAllowed("/AuthService/Authorize", ""))
+}
+
+func TestNewEvaluator_InvalidRoles(t *testing.T) {
+	_, err := rbac.NewEvaluator(&rbac.Policy{Roles: map[string]rbac.Role{
+		"user": {Inherits: []string{"missing"}},
+	}})
+	assert.EqualError(t, err, `rbac policy: unknown role "missing"`)
+
+	_, err = rbac.NewEvaluator(&rbac.Policy{Roles: map[string]rbac.Role{
+		"a": {Inherits: []string{"b"}},
+		"b": {Inherits: []string{"a"}},
+	}})
+	assert.ErrorContains(t, err, "inherits itself")
+
+	_, err = rbac.NewEvaluator(&rbac.Policy{
+		Roles: map[string]rbac.Role{"user": {}},
+		Users: map[string]map[string][]string{"acme": {"1": {"root"}}},
+	})
+	assert.EqualError(t, err, `rbac policy: user "1" of tenant "acme" is assigned unknown role "root"`)
+
+	_, err = rbac.NewEvaluator(&rbac.Policy{GRPC: map[string]string{"/AuthService/Login": ""}})
+	assert.Error(t, err)
+
+	_, err = rbac.NewEvaluator(&rbac.Policy{Services: map[string][]string{"/AuthService/CheckPermission": {""}}})
+	assert.Error(t, err)
+}
+
+func TestParsePolicy_JSON(t *testing.T) {
+	policy, err := rbac.ParsePolicy([]byte(`{"roles": {"user": {"permissions": ["sessions:read"]}}, "grpc": {"/AuthService/ListSessions": "sessions:read"}}`), ".json")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sessions:read"}, policy.Roles["user"].Permissions)
+	assert.Equal(t, "sessions:read", policy.GRPC["/AuthService/ListSessions"])
+
+	_, err = rbac.ParsePolicy([]byte(`{"roles": [}`), ".json")
+	assert.Error(t, err)
+}
+
+func TestLoadPolicy_Default(t *testing.T) {
+	policy, err := rbac.LoadPolicy("../../config/rbac.yaml")
+	assert.NoError(t, err)
+
+	evaluator, err := rbac.NewEvaluator(policy)
+	assert.NoError(t, err)
+	assert.True(t, evaluator.IsPublicMethod("/AuthService/Login"))
+	assert.True(t, evaluator.HasPermission([]string{"user"}, "sessions:revoke"))
+	// Callers without a verified service identity cannot probe permissions
+	assert.False(t, evaluator.ServiceAllowed("/AuthService/CheckPermission", ""))
+	assert.True(t, evaluator.ServiceAllowed("/AuthService/CheckPermission", "spiffe://bitbridge/gateway"))
+}
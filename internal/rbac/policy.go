@@ -0,0 +1,59 @@
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is the declarative access control policy. Roles grant permissions, users are assigned
+// roles, and gRPC methods and HTTP routes name the permission they require. gRPC methods may also be
+// restricted to internal services, whatever token the call carries.
+type Policy struct {
+	Roles    map[string]Role                `json:"roles" yaml:"roles"`       // Roles keyed by name
+	Users    map[string]map[string][]string `json:"users" yaml:"users"`       // Roles assigned to users keyed by tenant, "" for the default, and user ID, on top of those every user gets
+	Public   Public                         `json:"public" yaml:"public"`     // Endpoints that need no token
+	GRPC     map[string]string              `json:"grpc" yaml:"grpc"`         // Required permission keyed by full gRPC method name
+	HTTP     map[string]string              `json:"http" yaml:"http"`         // Required permission keyed by "METHOD /route/:param"
+	Services map[string][]string            `json:"services" yaml:"services"` // Service identities allowed keyed by full gRPC method name, any verified service when empty
+}
+
+// Role grants permissions directly and through the roles it inherits.
+type Role struct {
+	Permissions []string `json:"permissions" yaml:"permissions"`
+	Inherits    []string `json:"inherits" yaml:"inherits"`
+}
+
+// Public lists the endpoints anyone may call without a token.
+type Public struct {
+	GRPC []string `json:"grpc" yaml:"grpc"`
+	HTTP []string `json:"http" yaml:"http"`
+}
+
+// LoadPolicy reads a policy from a YAML or JSON file, chosen by the file extension.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePolicy(data, filepath.Ext(path))
+}
+
+// ParsePolicy decodes a policy. The format is ".json" for JSON and YAML otherwise.
+func ParsePolicy(data []byte, format string) (*Policy, error) {
+	policy := &Policy{}
+	var err error
+	if strings.EqualFold(format, ".json") {
+		err = json.Unmarshal(data, policy)
+	} else {
+		err = yaml.Unmarshal(data, policy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rbac policy: %w", err)
+	}
+	return policy, nil
+}
@@ -0,0 +1,41 @@
+package rbac
+
+import (
+	"context"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+)
+
+// IPermissionService lets other services check the permissions of an access token.
+type IPermissionService interface {
+	CheckPermission(ctx context.Context, accessToken string, permission string) (bool, error)
+}
+
+// PermissionService checks permissions of access tokens against the policy.
+type PermissionService struct {
+	TokenService token.ITokenService // Validates the access tokens
+	Evaluator    IEvaluator          // Evaluates the policy
+}
+
+// NewPermissionService initializes a new PermissionService with necessary dependencies.
+func NewPermissionService(tokenService token.ITokenService, evaluator IEvaluator) *PermissionService {
+	return &PermissionService{
+		TokenService: tokenService,
+		Evaluator:    evaluator,
+	}
+}
+
+// CheckPermission reports whether the roles in the access token grant the permission. Tokens
+// issued for another tenant than the request's are rejected.
+func (s *PermissionService) CheckPermission(ctx context.Context, accessToken string, permission string) (bool, error) {
+	claims, err := s.TokenService.ParseToken(ctx, accessToken)
+	if err != nil || claims.TokenType != token.AccessTokenType || !tenant.Matches(ctx, claims.Tenant) {
+		return false, common_error.NewServiceError(common_error.TokenInvalid, "Invalid access token", err)
+	}
+	return s.Evaluator.HasPermission(claims.Roles, permission), nil
+}
+
+// Ensure PermissionService implements IPermissionService.
+var _ IPermissionService = (*PermissionService)(nil)
@@ -0,0 +1,73 @@
+package rbac_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/rbac"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTokenService struct {
+	mock.Mock
+	token.ITokenService
+}
+
+func (m *MockTokenService) ParseToken(ctx context.Context, tokenString string) (*public_model.CustomClaims, error) {
+	args := m.Called(ctx, tokenString)
+	return args.Get(0).(*public_model.CustomClaims), args.Error(1)
+}
+
+func TestCheckPermission(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	mockTokenService.On("ParseToken", mock.Anything, "access_token").Return(&public_model.CustomClaims{Roles: []string{"user"}, TokenType: token.AccessTokenType}, nil)
+	svc := rbac.NewPermissionService(mockTokenService, newEvaluator(t))
+
+	allowed, err := svc.CheckPermission(context.TODO(), "access_token", "sessions:read")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = svc.CheckPermission(context.TODO(), "access_token", "sessions:revoke")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestCheckPermission_InvalidToken(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	mockTokenService.On("ParseToken", mock.Anything, "refresh_token").Return(&public_model.CustomClaims{Roles: []string{"user"}, TokenType: token.RefreshTokenType}, nil)
+	mockTokenService.On("ParseToken", mock.Anything, "service_token").Return(&public_model.CustomClaims{UserID: "-1", Roles: []string{"user"}}, nil)
+	mockTokenService.On("ParseToken", mock.Anything, "bad_token").Return((*public_model.CustomClaims)(nil), errors.New("bad token"))
+	svc := rbac.NewPermissionService(mockTokenService, newEvaluator(t))
+
+	for _, accessToken := range []string{"refresh_token", "service_token", "bad_token"} {
+		allowed, err := svc.CheckPermission(context.TODO(), accessToken, "sessions:read")
+
+		serviceErr, ok := err.(*common_error.ServiceError)
+		assert.True(t, ok)
+		assert.Equal(t, common_error.TokenInvalid, serviceErr.Code)
+		assert.False(t, allowed)
+	}
+}
+
+func TestCheckPermission_OtherTenant(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	mockTokenService.On("ParseToken", mock.Anything, "access_token").Return(&public_model.CustomClaims{Roles: []string{"user"}, TokenType: token.AccessTokenType, Tenant: "acme"}, nil)
+	svc := rbac.NewPermissionService(mockTokenService, newEvaluator(t))
+
+	allowed, err := svc.CheckPermission(tenant.WithTenant(context.TODO(), "globex"), "access_token", "sessions:read")
+
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.TokenInvalid, serviceErr.Code)
+	assert.False(t, allowed)
+
+	allowed, err = svc.CheckPermission(tenant.WithTenant(context.TODO(), "acme"), "access_token", "sessions:read")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
@@ -0,0 +1,71 @@
+package scope
+
+import (
+	"sort"
+	"strings"
+)
+
+// Parse splits a space-delimited scope string (RFC 6749 section 3.3) into its scope tokens,
+// dropping duplicates and empty entries.
+func Parse(scope string) []string {
+	fields := strings.Fields(scope)
+	seen := make(map[string]struct{}, len(fields))
+	scopes := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if _, ok := seen[field]; ok {
+			continue
+		}
+		seen[field] = struct{}{}
+		scopes = append(scopes, field)
+	}
+	return scopes
+}
+
+// Format joins scope tokens into a sorted, space-delimited scope string.
+func Format(scopes []string) string {
+	sorted := append([]string(nil), scopes...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, " ")
+}
+
+// Narrow returns the requested scopes if every one of them was granted. An empty request
+// keeps the whole grant. The second result names the first scope that was not granted.
+func Narrow(granted []string, requested []string) ([]string, string) {
+	if len(requested) == 0 {
+		return granted, ""
+	}
+
+	grantedSet := make(map[string]struct{}, len(granted))
+	for _, s := range granted {
+		grantedSet[s] = struct{}{}
+	}
+	for _, s := range requested {
+		if _, ok := grantedSet[s]; !ok {
+			return nil, s
+		}
+	}
+	return requested, ""
+}
+
+// Contains reports whether the space-delimited scope string includes the given scope.
+func Contains(scope string, want string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Valid reports whether each scope token uses only the characters RFC 6749 allows.
+func Valid(scope string) bool {
+	for _, r := range scope {
+		if r == ' ' {
+			continue
+		}
+		if r < 0x21 || r == 0x22 || r == 0x5C || r > 0x7E {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package scope_test
+
+import (
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/scope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	assert.Equal(t, []string{"profile", "sessions"}, scope.Parse("  profile sessions profile "))
+	assert.Empty(t, scope.Parse(""))
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "profile sessions", scope.Format([]string{"sessions", "profile"}))
+	assert.Equal(t, "", scope.Format(nil))
+}
+
+func TestNarrow(t *testing.T) {
+	granted := []string{"profile", "sessions"}
+
+	scopes, missing := scope.Narrow(granted, nil)
+	assert.Equal(t, granted, scopes)
+	assert.Empty(t, missing)
+
+	scopes, missing = scope.Narrow(granted, []string{"profile"})
+	assert.Equal(t, []string{"profile"}, scopes)
+	assert.Empty(t, missing)
+
+	scopes, missing = scope.Narrow(granted, []string{"profile", "admin"})
+	assert.Nil(t, scopes)
+	assert.Equal(t, "admin", missing)
+}
+
+func TestContains(t *testing.T) {
+	assert.True(t, scope.Contains("profile sessions", "sessions"))
+	assert.False(t, scope.Contains("profile sessions", "session"))
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, scope.Valid("profile read:sessions"))
+	assert.True(t, scope.Valid(""))
+	assert.False(t, scope.Valid(`profile "quoted"`))
+	assert.False(t, scope.Valid("profile\tsessions"))
+	assert.False(t, scope.Valid("prófile"))
+}
@@ -0,0 +1,162 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+// DefaultServiceAccountTokenPath is where Kubernetes mounts the service account token of a pod.
+const DefaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// Token is a Vault token obtained by an auth method.
+type Token struct {
+	Value     string
+	TTL       time.Duration // Zero for tokens that never expire
+	Renewable bool
+}
+
+// IAuthMethod defines how the service authenticates to Vault.
+type IAuthMethod interface {
+	// Login obtains a new token. The client carries no token, or an expired one.
+	Login(ctx context.Context, client *vault.Client) (*Token, error)
+}
+
+// StaticToken authenticates with a token handed to the service, for example through the environment.
+type StaticToken struct {
+	Token string
+}
+
+// Login implements IAuthMethod by looking the token up.
+func (s *StaticToken) Login(ctx context.Context, client *vault.Client) (*Token, error) {
+	return lookupToken(ctx, client, s.Token)
+}
+
+// TokenFile authenticates with a token read from a file, as written by a Vault agent. The file is read
+// again on every login, so a rotated token is picked up on re-authentication.
+type TokenFile struct {
+	Path string
+}
+
+// Login implements IAuthMethod.
+func (t *TokenFile) Login(ctx context.Context, client *vault.Client) (*Token, error) {
+	token, err := readFile(t.Path)
+	if err != nil {
+		return nil, err
+	}
+	return lookupToken(ctx, client, token)
+}
+
+// AppRole authenticates with the role ID and secret ID of an AppRole.
+type AppRole struct {
+	Mount        string // Mount path of the auth method, "approle" when empty
+	RoleID       string
+	SecretIDFile string // File holding the secret ID, read on every login
+}
+
+// Login implements IAuthMethod.
+func (a *AppRole) Login(ctx context.Context, client *vault.Client) (*Token, error) {
+	secretID, err := readFile(a.SecretIDFile)
+	if err != nil {
+		return nil, err
+	}
+	return login(ctx, client, mount(a.Mount, "approle"), map[string]interface{}{
+		"role_id":   a.RoleID,
+		"secret_id": secretID,
+	})
+}
+
+// Kubernetes authenticates with the service account token of the pod the service runs in.
+type Kubernetes struct {
+	Mount     string // Mount path of the auth method, "kubernetes" when empty
+	Role      string // Vault role bound to the service account
+	TokenPath string // Service account token, DefaultServiceAccountTokenPath when empty
+}
+
+// Login implements IAuthMethod. The service account token is read on every login, since Kubernetes
+// rotates projected tokens.
+func (k *Kubernetes) Login(ctx context.Context, client *vault.Client) (*Token, error) {
+	path := k.TokenPath
+	if path == "" {
+		path = DefaultServiceAccountTokenPath
+	}
+	jwt, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return login(ctx, client, mount(k.Mount, "kubernetes"), map[string]interface{}{
+		"role": k.Role,
+		"jwt":  jwt,
+	})
+}
+
+// login writes the credentials to the login endpoint of the auth method mounted at mountPath.
+func login(ctx context.Context, client *vault.Client, mountPath string, credentials map[string]interface{}) (*Token, error) {
+	client.ClearToken()
+	resp, err := client.Logical().WriteWithContext(ctx, "auth/"+mountPath+"/login", credentials)
+	if err != nil {
+		return nil, fmt.Errorf("vault login with %s: %w", mountPath, err)
+	}
+	if resp == nil || resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return nil, fmt.Errorf("vault login with %s: no token returned", mountPath)
+	}
+	return &Token{
+		Value:     resp.Auth.ClientToken,
+		TTL:       time.Duration(resp.Auth.LeaseDuration) * time.Second,
+		Renewable: resp.Auth.Renewable,
+	}, nil
+}
+
+// lookupToken checks the token and returns its remaining lifetime.
+func lookupToken(ctx context.Context, client *vault.Client, token string) (*Token, error) {
+	if token == "" {
+		return nil, errors.New("vault token is empty")
+	}
+	client.SetToken(token)
+	resp, err := client.Auth().Token().LookupSelfWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("vault token lookup: %w", err)
+	}
+	ttl, err := resp.TokenTTL()
+	if err != nil {
+		return nil, fmt.Errorf("vault token lookup: %w", err)
+	}
+	renewable, err := resp.TokenIsRenewable()
+	if err != nil {
+		return nil, fmt.Errorf("vault token lookup: %w", err)
+	}
+	return &Token{Value: token, TTL: ttl, Renewable: renewable}, nil
+}
+
+func mount(configured string, fallback string) string {
+	if configured = strings.Trim(configured, "/"); configured != "" {
+		return configured
+	}
+	return fallback
+}
+
+// readFile reads a credential from a file, trimming the trailing newline editors and tools add.
+func readFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return value, nil
+}
+
+// Ensure auth methods implement IAuthMethod.
+var (
+	_ IAuthMethod = (*StaticToken)(nil)
+	_ IAuthMethod = (*TokenFile)(nil)
+	_ IAuthMethod = (*AppRole)(nil)
+	_ IAuthMethod = (*Kubernetes)(nil)
+)
@@ -0,0 +1,122 @@
+package secret
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// EphemeralSecretSize is the size in bytes of the secrets generated by EphemeralProvider.
+const EphemeralSecretSize = 32
+
+// EnvProvider reads secrets from environment variables. A secret path maps to the variable named by
+// the prefix followed by the path in upper case, with every character other than a letter or digit
+// replaced by '_': "secret/data/jwt_secret" is read from SECRET_DATA_JWT_SECRET without a prefix.
+type EnvProvider struct {
+	Prefix    string
+	LookupEnv func(string) (string, bool)
+}
+
+// NewEnvProvider initializes a new EnvProvider reading variables through lookupEnv, usually os.LookupEnv.
+func NewEnvProvider(prefix string, lookupEnv func(string) (string, bool)) *EnvProvider {
+	return &EnvProvider{Prefix: prefix, LookupEnv: lookupEnv}
+}
+
+// Secret implements ISecretProvider.
+func (e *EnvProvider) Secret(ctx context.Context, path string) ([]byte, error) {
+	value, ok := e.LookupEnv(e.Variable(path))
+	if !ok || value == "" {
+		return nil, ErrNotFound
+	}
+	return []byte(value), nil
+}
+
+// Variable returns the name of the environment variable holding the secret at path.
+func (e *EnvProvider) Variable(path string) string {
+	return e.Prefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, path)
+}
+
+// FileProvider reads secrets from files below a directory, such as a mounted Kubernetes secret. The
+// secret at "secret/data/jwt_secret" is the file secret/data/jwt_secret in the directory. Files are
+// read on every lookup, so replaced files take effect immediately.
+type FileProvider struct {
+	Dir string
+}
+
+// NewFileProvider initializes a new FileProvider reading secrets below dir.
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{Dir: dir}
+}
+
+// Secret implements ISecretProvider. A trailing newline is not part of the secret.
+func (f *FileProvider) Secret(ctx context.Context, path string) ([]byte, error) {
+	if !filepath.IsLocal(path) {
+		return nil, fmt.Errorf("secret path %q leaves the secrets directory", path)
+	}
+	data, err := os.ReadFile(filepath.Join(f.Dir, filepath.FromSlash(path)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return nil, ErrNotFound
+	}
+	return []byte(value), nil
+}
+
+// EphemeralProvider generates a random secret for every path on first use and keeps it in memory. It
+// lets the service start without any secrets store, but everything signed with its secrets becomes
+// invalid on restart and cannot be verified by other instances. It is meant for local development only.
+type EphemeralProvider struct {
+	mu      sync.Mutex
+	secrets map[string][]byte
+}
+
+// NewEphemeralProvider initializes a new EphemeralProvider, warning that it is unsafe for production.
+func NewEphemeralProvider() *EphemeralProvider {
+	slog.Warn("Secrets are generated at random and kept in memory. This dev mode is NOT SAFE FOR PRODUCTION: " +
+		"tokens do not survive a restart and are not shared between instances. Configure the vault, env or file secrets provider instead.")
+	return &EphemeralProvider{secrets: map[string][]byte{}}
+}
+
+// Secret implements ISecretProvider.
+func (e *EphemeralProvider) Secret(ctx context.Context, path string) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if value, ok := e.secrets[path]; ok {
+		return value, nil
+	}
+	value := make([]byte, EphemeralSecretSize)
+	if _, err := rand.Read(value); err != nil {
+		return nil, err
+	}
+	slog.WarnContext(ctx, "Generated an ephemeral secret, dev mode is not safe for production", "path", path)
+	e.secrets[path] = value
+	return value, nil
+}
+
+// Ensure providers implement ISecretProvider.
+var (
+	_ ISecretProvider = (*EnvProvider)(nil)
+	_ ISecretProvider = (*FileProvider)(nil)
+	_ ISecretProvider = (*EphemeralProvider)(nil)
+)
@@ -0,0 +1,71 @@
+package secret_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/secret"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvProvider(t *testing.T) {
+	env := map[string]string{"AUTH_SECRET_DATA_JWT_SECRET": "signing-key", "AUTH_SECRET_DATA_EMPTY": ""}
+	provider := secret.NewEnvProvider("AUTH_", func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+
+	assert.Equal(t, "AUTH_SECRET_DATA_TENANTS_ACME_CORP_JWT_SECRET", provider.Variable("secret/data/tenants/acme-corp/jwt_secret"))
+
+	value, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("signing-key"), value)
+
+	_, err = provider.Secret(context.TODO(), "secret/data/empty")
+	assert.ErrorIs(t, err, secret.ErrNotFound)
+	_, err = provider.Secret(context.TODO(), "secret/data/missing")
+	assert.ErrorIs(t, err, secret.ErrNotFound)
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "secret", "data"), 0700))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "secret", "data", "jwt_secret"), []byte("signing-key\n"), 0600))
+	provider := secret.NewFileProvider(dir)
+
+	value, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("signing-key"), value)
+
+	_, err = provider.Secret(context.TODO(), "secret/data/missing")
+	assert.ErrorIs(t, err, secret.ErrNotFound)
+
+	_, err = provider.Secret(context.TODO(), "../outside")
+	assert.ErrorContains(t, err, "leaves the secrets directory")
+	_, err = provider.Secret(context.TODO(), "/etc/passwd")
+	assert.ErrorContains(t, err, "leaves the secrets directory")
+}
+
+func TestEphemeralProvider(t *testing.T) {
+	provider := secret.NewEphemeralProvider()
+
+	first, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.NoError(t, err)
+	assert.Len(t, first, secret.EphemeralSecretSize)
+
+	// The secret is stable for the life of the provider and distinct per path
+	again, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	other, err := provider.Secret(context.TODO(), "secret/data/webhook_secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, other)
+
+	// Another instance, like the service after a restart, has other secrets
+	restarted, err := secret.NewEphemeralProvider().Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, restarted)
+}
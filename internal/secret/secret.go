@@ -0,0 +1,86 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	internal_time "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/time"
+)
+
+// ErrNotFound is returned when a secret does not exist or holds no value.
+var ErrNotFound = errors.New("secret not found")
+
+// ISecretProvider defines a source of secrets, such as signing keys, addressed by path.
+type ISecretProvider interface {
+	Secret(ctx context.Context, path string) ([]byte, error)
+}
+
+// StaticProvider serves secrets from a fixed map. It suits tests.
+type StaticProvider map[string][]byte
+
+// Secret implements ISecretProvider.
+func (s StaticProvider) Secret(ctx context.Context, path string) ([]byte, error) {
+	value, ok := s[path]
+	if !ok || len(value) == 0 {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+// cachedSecret is a secret value and when it was fetched.
+type cachedSecret struct {
+	value     []byte
+	fetchedAt time.Time
+}
+
+// CachedProvider keeps secrets fetched from another provider for a while, so that consumers can look
+// up a secret per request without a round trip. Rotated secrets are picked up once the cache expires.
+// When refreshing fails, the previous value keeps being served so that an outage of the secrets store
+// does not take the service down.
+type CachedProvider struct {
+	Provider ISecretProvider          // Source of the secrets
+	TTL      time.Duration            // How long a fetched secret is served before it is fetched again
+	Time     internal_time.TimeSource // Source to get the current time
+
+	mu      sync.Mutex
+	secrets map[string]*cachedSecret
+}
+
+// NewCachedProvider initializes a new CachedProvider in front of provider.
+func NewCachedProvider(provider ISecretProvider, ttl time.Duration, time internal_time.TimeSource) *CachedProvider {
+	return &CachedProvider{Provider: provider, TTL: ttl, Time: time, secrets: map[string]*cachedSecret{}}
+}
+
+// Secret implements ISecretProvider.
+func (c *CachedProvider) Secret(ctx context.Context, path string) ([]byte, error) {
+	now := c.Time.Now()
+	c.mu.Lock()
+	cached, ok := c.secrets[path]
+	c.mu.Unlock()
+	if ok && now.Sub(cached.fetchedAt) < c.TTL {
+		return cached.value, nil
+	}
+
+	value, err := c.Provider.Secret(ctx, path)
+	if err != nil {
+		if ok && !errors.Is(err, ErrNotFound) {
+			slog.WarnContext(ctx, "Serving cached secret, refreshing it failed", "path", path, "error", err)
+			return cached.value, nil
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.secrets[path] = &cachedSecret{value: value, fetchedAt: now}
+	c.mu.Unlock()
+	return value, nil
+}
+
+// Ensure providers implement ISecretProvider.
+var (
+	_ ISecretProvider = StaticProvider(nil)
+	_ ISecretProvider = (*CachedProvider)(nil)
+)
@@ -0,0 +1,98 @@
+package secret_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTimeSource struct {
+	now time.Time
+}
+
+func (m *MockTimeSource) Now() time.Time {
+	return m.now
+}
+
+type MockSecretProvider struct {
+	mock.Mock
+}
+
+func (m *MockSecretProvider) Secret(ctx context.Context, path string) ([]byte, error) {
+	args := m.Called(ctx, path)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+var start = time.Date(2023, time.November, 1, 12, 0, 0, 0, time.UTC)
+
+func TestStaticProvider(t *testing.T) {
+	provider := secret.StaticProvider{"secret/data/jwt_secret": []byte("secret"), "secret/data/empty": nil}
+
+	value, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret"), value)
+
+	_, err = provider.Secret(context.TODO(), "secret/data/empty")
+	assert.ErrorIs(t, err, secret.ErrNotFound)
+	_, err = provider.Secret(context.TODO(), "secret/data/missing")
+	assert.ErrorIs(t, err, secret.ErrNotFound)
+}
+
+func TestCachedProvider(t *testing.T) {
+	provider := new(MockSecretProvider)
+	clock := &MockTimeSource{now: start}
+	cached := secret.NewCachedProvider(provider, time.Minute, clock)
+	provider.On("Secret", mock.Anything, "path").Return([]byte("first"), nil).Once()
+	provider.On("Secret", mock.Anything, "path").Return([]byte("rotated"), nil).Once()
+
+	// Served from the cache until it expires
+	for i := 0; i < 2; i++ {
+		value, err := cached.Secret(context.TODO(), "path")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("first"), value)
+	}
+
+	clock.now = start.Add(time.Minute)
+	value, err := cached.Secret(context.TODO(), "path")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("rotated"), value)
+	provider.AssertExpectations(t)
+}
+
+func TestCachedProvider_ServesStaleOnError(t *testing.T) {
+	provider := new(MockSecretProvider)
+	clock := &MockTimeSource{now: start}
+	cached := secret.NewCachedProvider(provider, time.Minute, clock)
+	provider.On("Secret", mock.Anything, "path").Return([]byte("first"), nil).Once()
+	provider.On("Secret", mock.Anything, "path").Return(nil, errors.New("vault down")).Once()
+	provider.On("Secret", mock.Anything, "path").Return(nil, secret.ErrNotFound).Once()
+
+	_, err := cached.Secret(context.TODO(), "path")
+	assert.NoError(t, err)
+
+	clock.now = start.Add(time.Hour)
+	value, err := cached.Secret(context.TODO(), "path")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("first"), value)
+
+	// A deleted secret is not served any more
+	_, err = cached.Secret(context.TODO(), "path")
+	assert.ErrorIs(t, err, secret.ErrNotFound)
+}
+
+func TestCachedProvider_Error(t *testing.T) {
+	provider := new(MockSecretProvider)
+	cached := secret.NewCachedProvider(provider, time.Minute, &MockTimeSource{now: start})
+	provider.On("Secret", mock.Anything, "path").Return(nil, errors.New("vault down"))
+
+	_, err := cached.Secret(context.TODO(), "path")
+	assert.EqualError(t, err, "vault down")
+}
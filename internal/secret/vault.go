@@ -0,0 +1,192 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	internal_time "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/time"
+	vault "github.com/hashicorp/vault/api"
+)
+
+// Bounds of the delay between failed attempts to renew or re-acquire the Vault token.
+const (
+	MinRetryDelay = time.Second
+	MaxRetryDelay = time.Minute
+)
+
+// VaultProvider reads secrets from a Vault KV version 2 engine. It logs in with its auth method on
+// first use, and Run keeps the token alive: the token is renewed before it expires, and a new one is
+// obtained once it can no longer be renewed. A read rejected with 403 logs in again and is retried once.
+type VaultProvider struct {
+	Client *vault.Client            // Vault client carrying the current token
+	Auth   IAuthMethod              // How to obtain a token
+	Time   internal_time.TimeSource // Source to get the current time
+
+	mu     sync.Mutex
+	token  *Token
+	issued time.Time
+}
+
+// NewVaultProvider initializes a new VaultProvider for the Vault server at address.
+func NewVaultProvider(address string, auth IAuthMethod, time internal_time.TimeSource) (*VaultProvider, error) {
+	vaultConfig := vault.DefaultConfig()
+	vaultConfig.Address = address
+	client, err := vault.NewClient(vaultConfig)
+	if err != nil {
+		return nil, err
+	}
+	// The client picks up VAULT_TOKEN from the environment; only the auth method may provide a token.
+	client.ClearToken()
+	return &VaultProvider{Client: client, Auth: auth, Time: time}, nil
+}
+
+// Secret implements ISecretProvider. The path names the secret as read through the API, such as
+// "secret/data/jwt_secret", and the secret holds its value under the "value" key.
+func (v *VaultProvider) Secret(ctx context.Context, path string) ([]byte, error) {
+	if err := v.ensureToken(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := v.Client.Logical().ReadWithContext(ctx, path)
+	if isPermissionDenied(err) {
+		// The token was revoked or expired behind our back.
+		if err := v.Login(ctx); err != nil {
+			return nil, err
+		}
+		resp, err = v.Client.Logical().ReadWithContext(ctx, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("vault read %s: %w", path, err)
+	}
+	if resp == nil {
+		return nil, ErrNotFound
+	}
+	data, ok := resp.Data["data"].(map[string]interface{})
+	if !ok {
+		return nil, ErrNotFound
+	}
+	value, ok := data["value"].(string)
+	if !ok || value == "" {
+		return nil, ErrNotFound
+	}
+	return []byte(value), nil
+}
+
+// Login obtains a new token with the auth method.
+func (v *VaultProvider) Login(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.login(ctx)
+}
+
+func (v *VaultProvider) login(ctx context.Context) error {
+	token, err := v.Auth.Login(ctx, v.Client)
+	if err != nil {
+		return err
+	}
+	v.Client.SetToken(token.Value)
+	v.token = token
+	v.issued = v.Time.Now()
+	return nil
+}
+
+// ensureToken logs in unless a token was already obtained.
+func (v *VaultProvider) ensureToken(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.token != nil {
+		return nil
+	}
+	return v.login(ctx)
+}
+
+// Refresh renews the token, or obtains a new one when it cannot be renewed. It returns how long
+// until the token should be refreshed again, which is two thirds of its lifetime, or zero when the
+// token never expires.
+func (v *VaultProvider) Refresh(ctx context.Context) (time.Duration, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.token == nil {
+		if err := v.login(ctx); err != nil {
+			return 0, err
+		}
+		return refreshAfter(v.token.TTL), nil
+	}
+	if v.token.Renewable {
+		resp, err := v.Client.Auth().Token().RenewSelfWithContext(ctx, int(v.token.TTL.Seconds()))
+		if err == nil && resp != nil && resp.Auth != nil {
+			ttl := time.Duration(resp.Auth.LeaseDuration) * time.Second
+			// A token reaching its max TTL is renewed for less than it asked for; log in again near the end.
+			if ttl >= v.token.TTL/3 {
+				v.token.TTL = ttl
+				v.token.Renewable = resp.Auth.Renewable
+				v.issued = v.Time.Now()
+				return refreshAfter(ttl), nil
+			}
+		} else if err != nil {
+			slog.WarnContext(ctx, "Renewing Vault token failed, logging in again", "error", err)
+		}
+	}
+	if err := v.login(ctx); err != nil {
+		return 0, err
+	}
+	return refreshAfter(v.token.TTL), nil
+}
+
+// Run keeps the token alive until ctx is cancelled. Failures are logged and retried with a backoff.
+func (v *VaultProvider) Run(ctx context.Context) {
+	delay := v.nextRefresh()
+	retryDelay := MinRetryDelay
+	for {
+		if delay == 0 {
+			// Tokens that never expire need no upkeep.
+			return
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		next, err := v.Refresh(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Refreshing Vault token failed", "retry_in", retryDelay, "error", err)
+			delay = retryDelay
+			retryDelay = min(retryDelay*2, MaxRetryDelay)
+			continue
+		}
+		delay = next
+		retryDelay = MinRetryDelay
+	}
+}
+
+// nextRefresh returns how long until the current token should be refreshed.
+func (v *VaultProvider) nextRefresh() time.Duration {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.token == nil {
+		return MinRetryDelay
+	}
+	if v.token.TTL == 0 {
+		return 0
+	}
+	remaining := refreshAfter(v.token.TTL) - v.Time.Now().Sub(v.issued)
+	return max(remaining, MinRetryDelay)
+}
+
+func refreshAfter(ttl time.Duration) time.Duration {
+	return ttl * 2 / 3
+}
+
+func isPermissionDenied(err error) bool {
+	var responseErr *vault.ResponseError
+	return errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusForbidden
+}
+
+// Ensure VaultProvider implements ISecretProvider.
+var _ ISecretProvider = (*VaultProvider)(nil)
@@ -0,0 +1,310 @@
+package secret_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/secret"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVault serves the parts of the Vault HTTP API the provider uses.
+type fakeVault struct {
+	mu        sync.Mutex
+	secrets   map[string]string // KV version 2 values, keyed by API path
+	tokens    map[string]bool   // Valid tokens
+	ttl       int               // Lifetime of issued tokens in seconds
+	renewable bool
+	renewTTL  int // Lifetime granted on renewal in seconds
+	logins    int
+	renewals  int
+	issued    int
+	roleID    string
+	secretID  string
+	role      string
+	jwt       string
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	vault := &fakeVault{
+		secrets:   map[string]string{"/v1/secret/data/jwt_secret": "signing-key"},
+		tokens:    map[string]bool{},
+		ttl:       3600,
+		renewable: true,
+		renewTTL:  3600,
+		roleID:    "role-id",
+		secretID:  "secret-id",
+		role:      "auth-service",
+		jwt:       "service-account-jwt",
+	}
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+	return vault, server
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	token := r.Header.Get("X-Vault-Token")
+
+	switch r.URL.Path {
+	case "/v1/auth/approle/login":
+		if body["role_id"] != f.roleID || body["secret_id"] != f.secretID {
+			f.deny(w)
+			return
+		}
+		f.login(w)
+	case "/v1/auth/kubernetes/login":
+		if body["role"] != f.role || body["jwt"] != f.jwt {
+			f.deny(w)
+			return
+		}
+		f.login(w)
+	case "/v1/auth/token/lookup-self":
+		if !f.tokens[token] {
+			f.deny(w)
+			return
+		}
+		f.write(w, map[string]interface{}{"data": map[string]interface{}{"id": token, "ttl": f.ttl, "renewable": f.renewable}})
+	case "/v1/auth/token/renew-self":
+		if !f.tokens[token] || !f.renewable {
+			f.deny(w)
+			return
+		}
+		f.renewals++
+		f.write(w, map[string]interface{}{"auth": map[string]interface{}{"client_token": token, "lease_duration": f.renewTTL, "renewable": f.renewable}})
+	default:
+		if !f.tokens[token] {
+			f.deny(w)
+			return
+		}
+		value, ok := f.secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			f.write(w, map[string]interface{}{"errors": []string{}})
+			return
+		}
+		f.write(w, map[string]interface{}{"data": map[string]interface{}{"data": map[string]interface{}{"value": value}}})
+	}
+}
+
+func (f *fakeVault) login(w http.ResponseWriter) {
+	f.logins++
+	f.issued++
+	token := fmt.Sprintf("token-%d", f.issued)
+	f.tokens[token] = true
+	f.write(w, map[string]interface{}{"auth": map[string]interface{}{"client_token": token, "lease_duration": f.ttl, "renewable": f.renewable}})
+}
+
+func (f *fakeVault) deny(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusForbidden)
+	f.write(w, map[string]interface{}{"errors": []string{"permission denied"}})
+}
+
+func (f *fakeVault) write(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// revokeAll invalidates every token issued so far.
+func (f *fakeVault) revokeAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens = map[string]bool{}
+}
+
+func (f *fakeVault) counts() (logins int, renewals int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.logins, f.renewals
+}
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "credential")
+	assert.NoError(t, os.WriteFile(path, []byte(content+"\n"), 0600))
+	return path
+}
+
+func newVaultProvider(t *testing.T, address string, auth secret.IAuthMethod) *secret.VaultProvider {
+	provider, err := secret.NewVaultProvider(address, auth, &MockTimeSource{now: start})
+	assert.NoError(t, err)
+	return provider
+}
+
+func TestVaultProvider_AppRole(t *testing.T) {
+	vault, server := newFakeVault(t)
+	provider := newVaultProvider(t, server.URL, &secret.AppRole{RoleID: "role-id", SecretIDFile: writeFile(t, "secret-id")})
+
+	for i := 0; i < 2; i++ {
+		value, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("signing-key"), value)
+	}
+
+	// The token is reused between reads
+	logins, _ := vault.counts()
+	assert.Equal(t, 1, logins)
+}
+
+func TestVaultProvider_AppRole_WrongSecretID(t *testing.T) {
+	_, server := newFakeVault(t)
+	provider := newVaultProvider(t, server.URL, &secret.AppRole{RoleID: "role-id", SecretIDFile: writeFile(t, "wrong")})
+
+	_, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.ErrorContains(t, err, "vault login with approle")
+}
+
+func TestVaultProvider_Kubernetes(t *testing.T) {
+	_, server := newFakeVault(t)
+	provider := newVaultProvider(t, server.URL, &secret.Kubernetes{Role: "auth-service", TokenPath: writeFile(t, "service-account-jwt")})
+
+	value, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("signing-key"), value)
+}
+
+func TestVaultProvider_Kubernetes_CustomMount(t *testing.T) {
+	_, server := newFakeVault(t)
+	provider := newVaultProvider(t, server.URL, &secret.Kubernetes{Mount: "k8s-prod", Role: "auth-service", TokenPath: writeFile(t, "service-account-jwt")})
+
+	_, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.ErrorContains(t, err, "vault login with k8s-prod")
+}
+
+func TestVaultProvider_TokenFile_Rotated(t *testing.T) {
+	vault, server := newFakeVault(t)
+	vault.tokens["agent-token-1"] = true
+	path := writeFile(t, "agent-token-1")
+	provider := newVaultProvider(t, server.URL, &secret.TokenFile{Path: path})
+
+	_, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.NoError(t, err)
+
+	// The agent replaces the token, the old one stops working and the read logs in again
+	vault.revokeAll()
+	vault.tokens["agent-token-2"] = true
+	assert.NoError(t, os.WriteFile(path, []byte("agent-token-2"), 0600))
+
+	value, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("signing-key"), value)
+	assert.Equal(t, "agent-token-2", provider.Client.Token())
+}
+
+func TestVaultProvider_StaticToken_Invalid(t *testing.T) {
+	_, server := newFakeVault(t)
+	provider := newVaultProvider(t, server.URL, &secret.StaticToken{Token: "unknown"})
+
+	_, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.ErrorContains(t, err, "vault token lookup")
+}
+
+func TestVaultProvider_ReloginOnForbidden(t *testing.T) {
+	vault, server := newFakeVault(t)
+	provider := newVaultProvider(t, server.URL, &secret.AppRole{RoleID: "role-id", SecretIDFile: writeFile(t, "secret-id")})
+
+	_, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.NoError(t, err)
+	vault.revokeAll()
+
+	value, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("signing-key"), value)
+	logins, _ := vault.counts()
+	assert.Equal(t, 2, logins)
+}
+
+func TestVaultProvider_NotFound(t *testing.T) {
+	_, server := newFakeVault(t)
+	provider := newVaultProvider(t, server.URL, &secret.AppRole{RoleID: "role-id", SecretIDFile: writeFile(t, "secret-id")})
+
+	_, err := provider.Secret(context.TODO(), "secret/data/missing")
+	assert.ErrorIs(t, err, secret.ErrNotFound)
+}
+
+func TestVaultProvider_Refresh_Renews(t *testing.T) {
+	vault, server := newFakeVault(t)
+	provider := newVaultProvider(t, server.URL, &secret.AppRole{RoleID: "role-id", SecretIDFile: writeFile(t, "secret-id")})
+	assert.NoError(t, provider.Login(context.TODO()))
+
+	next, err := provider.Refresh(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 40*time.Minute, next)
+
+	logins, renewals := vault.counts()
+	assert.Equal(t, 1, logins)
+	assert.Equal(t, 1, renewals)
+}
+
+func TestVaultProvider_Refresh_ReloginNearMaxTTL(t *testing.T) {
+	vault, server := newFakeVault(t)
+	provider := newVaultProvider(t, server.URL, &secret.AppRole{RoleID: "role-id", SecretIDFile: writeFile(t, "secret-id")})
+	assert.NoError(t, provider.Login(context.TODO()))
+	vault.renewTTL = 60
+
+	_, err := provider.Refresh(context.TODO())
+	assert.NoError(t, err)
+
+	logins, renewals := vault.counts()
+	assert.Equal(t, 2, logins)
+	assert.Equal(t, 1, renewals)
+	assert.Equal(t, "token-2", provider.Client.Token())
+}
+
+func TestVaultProvider_Refresh_NotRenewable(t *testing.T) {
+	vault, server := newFakeVault(t)
+	vault.renewable = false
+	provider := newVaultProvider(t, server.URL, &secret.Kubernetes{Role: "auth-service", TokenPath: writeFile(t, "service-account-jwt")})
+	assert.NoError(t, provider.Login(context.TODO()))
+
+	_, err := provider.Refresh(context.TODO())
+	assert.NoError(t, err)
+
+	logins, renewals := vault.counts()
+	assert.Equal(t, 2, logins)
+	assert.Equal(t, 0, renewals)
+}
+
+func TestVaultProvider_Refresh_RenewalFails(t *testing.T) {
+	vault, server := newFakeVault(t)
+	provider := newVaultProvider(t, server.URL, &secret.AppRole{RoleID: "role-id", SecretIDFile: writeFile(t, "secret-id")})
+	assert.NoError(t, provider.Login(context.TODO()))
+	vault.revokeAll()
+
+	_, err := provider.Refresh(context.TODO())
+	assert.NoError(t, err)
+
+	value, err := provider.Secret(context.TODO(), "secret/data/jwt_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("signing-key"), value)
+}
+
+func TestVaultProvider_Run_StopsOnCancel(t *testing.T) {
+	_, server := newFakeVault(t)
+	provider := newVaultProvider(t, server.URL, &secret.AppRole{RoleID: "role-id", SecretIDFile: writeFile(t, "secret-id")})
+	assert.NoError(t, provider.Login(context.TODO()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		provider.Run(ctx)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+}
@@ -0,0 +1,185 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	internal_time "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/time"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+)
+
+// ISessionService defines methods for managing login sessions.
+type ISessionService interface {
+	Create(ctx context.Context, tenantID string, userID string) (*Session, error)
+	Refresh(ctx context.Context, tenantID string, userID string, sessionID string, refreshTokenID string) (*Session, error)
+	List(ctx context.Context, tenantID string, userID string) ([]*Session, error)
+	Revoke(ctx context.Context, tenantID string, userID string, sessionID string) error
+	RevokeAll(ctx context.Context, tenantID string, userID string) error
+}
+
+// Timeouts bound how long a session stays usable.
+type Timeouts struct {
+	Idle     time.Duration // A session expires when it is not refreshed within this window
+	Absolute time.Duration // A session expires this long after it started, however often it is refreshed
+}
+
+// SessionService contains fields necessary for session operations.
+type SessionService struct {
+	Store    IStore                   // Persists sessions
+	Time     internal_time.TimeSource // Source to get the current time
+	Timeouts Timeouts                 // Idle and absolute session lifetimes
+}
+
+// NewSessionService initializes a new SessionService with necessary dependencies.
+func NewSessionService(store IStore, time internal_time.TimeSource, timeouts Timeouts) *SessionService {
+	return &SessionService{
+		Store:    store,
+		Time:     time,
+		Timeouts: timeouts,
+	}
+}
+
+// Create starts a new session for the user of the tenant, recording the client the request came from.
+func (s *SessionService) Create(ctx context.Context, tenantID string, userID string) (*Session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	refreshTokenID, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Time.Now()
+	clientInfo := ClientInfoFromContext(ctx)
+	session := &Session{
+		ID:              id,
+		Tenant:          tenantID,
+		UserID:          userID,
+		CreatedAt:       now,
+		LastRefreshedAt: now,
+		IP:              clientInfo.IP,
+		UserAgent:       clientInfo.UserAgent,
+		RefreshTokenID:  refreshTokenID,
+	}
+
+	if err := s.Store.Save(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Refresh records a token refresh on the session and rotates its refresh token ID. It fails if the session
+// was revoked, has timed out, or belongs to another user or tenant. A refresh token other than the latest
+// one of the session has been used before, so the family may have leaked: the session is revoked.
+func (s *SessionService) Refresh(ctx context.Context, tenantID string, userID string, sessionID string, refreshTokenID string) (*Session, error) {
+	session, err := s.Store.Get(ctx, sessionID)
+	if errors.Is(err, ErrNotFound) || (err == nil && !session.ownedBy(tenantID, userID)) {
+		return nil, common_error.NewServiceError(common_error.SessionExpired, "Session has been revoked", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Time.Now()
+	if s.expired(session, now) {
+		if err := s.Store.Delete(ctx, sessionID); err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return nil, common_error.NewServiceError(common_error.SessionExpired, "Session has expired", nil)
+	}
+
+	if session.RefreshTokenID != refreshTokenID {
+		return nil, s.revokeReused(ctx, sessionID)
+	}
+
+	session.RefreshTokenID, err = newID()
+	if err != nil {
+		return nil, err
+	}
+	session.LastRefreshedAt = now
+	err = s.Store.Rotate(ctx, session, refreshTokenID)
+	if errors.Is(err, ErrStale) {
+		// Refreshed with the same token meanwhile
+		return nil, s.revokeReused(ctx, sessionID)
+	}
+	if errors.Is(err, ErrNotFound) {
+		return nil, common_error.NewServiceError(common_error.SessionExpired, "Session has been revoked", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// revokeReused revokes a session whose refresh token was used twice, and returns the error the refresh fails with.
+func (s *SessionService) revokeReused(ctx context.Context, sessionID string) error {
+	if err := s.Store.Delete(ctx, sessionID); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return common_error.NewServiceError(common_error.SessionExpired, "Refresh token has been reused", nil)
+}
+
+// List returns the active sessions of the user of the tenant. Timed out sessions are left out.
+func (s *SessionService) List(ctx context.Context, tenantID string, userID string) ([]*Session, error) {
+	sessions, err := s.Store.ListByUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Time.Now()
+	active := make([]*Session, 0, len(sessions))
+	for _, session := range sessions {
+		if !s.expired(session, now) {
+			active = append(active, session)
+		}
+	}
+
+	return active, nil
+}
+
+// Revoke ends one of the user's sessions. Sessions of other users or tenants are reported as not found.
+func (s *SessionService) Revoke(ctx context.Context, tenantID string, userID string, sessionID string) error {
+	session, err := s.Store.Get(ctx, sessionID)
+	if errors.Is(err, ErrNotFound) || (err == nil && !session.ownedBy(tenantID, userID)) {
+		return common_error.NewServiceError(common_error.NotFound, "Session not found", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.Store.Delete(ctx, sessionID)
+}
+
+// RevokeAll ends every session of the user of the tenant.
+func (s *SessionService) RevokeAll(ctx context.Context, tenantID string, userID string) error {
+	return s.Store.DeleteByUser(ctx, tenantID, userID)
+}
+
+// expired reports whether the session exceeded its idle or absolute timeout at the given time.
+func (s *SessionService) expired(session *Session, now time.Time) bool {
+	if s.Timeouts.Idle > 0 && now.Sub(session.LastRefreshedAt) > s.Timeouts.Idle {
+		return true
+	}
+	if s.Timeouts.Absolute > 0 && now.Sub(session.CreatedAt) > s.Timeouts.Absolute {
+		return true
+	}
+	return false
+}
+
+// newID generates a random 128-bit identifier for a session or refresh token.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Ensure SessionService implements ISessionService.
+var _ ISessionService = (*SessionService)(nil)
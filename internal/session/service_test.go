@@ -0,0 +1,271 @@
+package session_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockTimeSource struct {
+	now time.Time
+}
+
+func (m *MockTimeSource) Now() time.Time {
+	return m.now
+}
+
+func newService() (*session.SessionService, *MockTimeSource) {
+	timeSource := &MockTimeSource{now: time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)}
+	timeouts := session.Timeouts{Idle: 24 * time.Hour, Absolute: 30 * 24 * time.Hour}
+	return session.NewSessionService(session.NewMemoryStore(), timeSource, timeouts), timeSource
+}
+
+func TestCreate_RecordsClientInfo(t *testing.T) {
+	svc, timeSource := newService()
+	ctx := session.WithClientInfo(context.TODO(), session.ClientInfo{IP: "127.0.0.1", UserAgent: "test-agent"})
+
+	created, err := svc.Create(ctx, "acme", "user")
+
+	assert.NoError(t, err)
+	assert.Len(t, created.ID, 32)
+	assert.Equal(t, "acme", created.Tenant)
+	assert.Equal(t, "user", created.UserID)
+	assert.Equal(t, timeSource.now, created.CreatedAt)
+	assert.Equal(t, timeSource.now, created.LastRefreshedAt)
+	assert.Equal(t, "127.0.0.1", created.IP)
+	assert.Equal(t, "test-agent", created.UserAgent)
+}
+
+func TestRefresh_UpdatesLastRefresh(t *testing.T) {
+	svc, timeSource := newService()
+	created, _ := svc.Create(context.TODO(), "acme", "user")
+
+	timeSource.now = timeSource.now.Add(time.Hour)
+	refreshed, err := svc.Refresh(context.TODO(), "acme", "user", created.ID, created.RefreshTokenID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, created.CreatedAt, refreshed.CreatedAt)
+	assert.Equal(t, timeSource.now, refreshed.LastRefreshedAt)
+	assert.Len(t, refreshed.RefreshTokenID, 32)
+	assert.NotEqual(t, created.RefreshTokenID, refreshed.RefreshTokenID)
+}
+
+func TestRefresh_ReusedToken(t *testing.T) {
+	svc, _ := newService()
+	created, _ := svc.Create(context.TODO(), "acme", "user")
+	refreshed, err := svc.Refresh(context.TODO(), "acme", "user", created.ID, created.RefreshTokenID)
+	assert.NoError(t, err)
+
+	reused, err := svc.Refresh(context.TODO(), "acme", "user", created.ID, created.RefreshTokenID)
+
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.SessionExpired, serviceErr.Code)
+	assert.Equal(t, "Refresh token has been reused", serviceErr.Message)
+	assert.Nil(t, reused)
+
+	// The whole family is revoked, the latest token included
+	_, err = svc.Refresh(context.TODO(), "acme", "user", created.ID, refreshed.RefreshTokenID)
+	assert.Error(t, err)
+	sessions, _ := svc.List(context.TODO(), "acme", "user")
+	assert.Empty(t, sessions)
+}
+
+// racingStore rotates every session right after it is read, as a refresh with the same token racing with
+// this one would.
+type racingStore struct {
+	*session.MemoryStore
+}
+
+func (s racingStore) Get(ctx context.Context, sessionID string) (*session.Session, error) {
+	stored, err := s.MemoryStore.Get(ctx, sessionID)
+	if err == nil {
+		raced := *stored
+		raced.RefreshTokenID = "raced"
+		_ = s.MemoryStore.Rotate(ctx, &raced, stored.RefreshTokenID)
+	}
+	return stored, err
+}
+
+func TestRefresh_ConcurrentReuse(t *testing.T) {
+	svc, _ := newService()
+	created, _ := svc.Create(context.TODO(), "acme", "user")
+	svc.Store = racingStore{svc.Store.(*session.MemoryStore)}
+
+	refreshed, err := svc.Refresh(context.TODO(), "acme", "user", created.ID, created.RefreshTokenID)
+
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, "Refresh token has been reused", serviceErr.Message)
+	assert.Nil(t, refreshed)
+	sessions, _ := svc.List(context.TODO(), "acme", "user")
+	assert.Empty(t, sessions)
+}
+
+func TestRefresh_RevokedSession(t *testing.T) {
+	svc, _ := newService()
+	created, _ := svc.Create(context.TODO(), "acme", "user")
+	assert.NoError(t, svc.Revoke(context.TODO(), "acme", "user", created.ID))
+
+	refreshed, err := svc.Refresh(context.TODO(), "acme", "user", created.ID, created.RefreshTokenID)
+
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.SessionExpired, serviceErr.Code)
+	assert.Nil(t, refreshed)
+}
+
+func TestRefresh_OtherUser(t *testing.T) {
+	svc, _ := newService()
+	created, _ := svc.Create(context.TODO(), "acme", "user")
+
+	refreshed, err := svc.Refresh(context.TODO(), "acme", "other-user", created.ID, created.RefreshTokenID)
+
+	assert.Error(t, err)
+	assert.Nil(t, refreshed)
+}
+
+func TestRefresh_OtherTenant(t *testing.T) {
+	svc, _ := newService()
+	created, _ := svc.Create(context.TODO(), "acme", "user")
+
+	refreshed, err := svc.Refresh(context.TODO(), "globex", "user", created.ID, created.RefreshTokenID)
+
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.SessionExpired, serviceErr.Code)
+	assert.Nil(t, refreshed)
+}
+
+func TestRefresh_IdleTimeout(t *testing.T) {
+	svc, timeSource := newService()
+	created, _ := svc.Create(context.TODO(), "acme", "user")
+
+	timeSource.now = timeSource.now.Add(25 * time.Hour)
+	refreshed, err := svc.Refresh(context.TODO(), "acme", "user", created.ID, created.RefreshTokenID)
+
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.SessionExpired, serviceErr.Code)
+	assert.Equal(t, "Session has expired", serviceErr.Message)
+	assert.Nil(t, refreshed)
+
+	sessions, _ := svc.Store.ListByUser(context.TODO(), "acme", "user")
+	assert.Empty(t, sessions)
+}
+
+func TestRefresh_AbsoluteTimeout(t *testing.T) {
+	svc, timeSource := newService()
+	created, _ := svc.Create(context.TODO(), "acme", "user")
+
+	// Refreshing daily keeps the session from going idle, but not past its absolute lifetime
+	refreshTokenID := created.RefreshTokenID
+	for i := 0; i < 31; i++ {
+		timeSource.now = timeSource.now.Add(23 * time.Hour)
+		refreshed, err := svc.Refresh(context.TODO(), "acme", "user", created.ID, refreshTokenID)
+		assert.NoError(t, err)
+		refreshTokenID = refreshed.RefreshTokenID
+	}
+
+	timeSource.now = timeSource.now.Add(23 * time.Hour)
+	refreshed, err := svc.Refresh(context.TODO(), "acme", "user", created.ID, refreshTokenID)
+
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.SessionExpired, serviceErr.Code)
+	assert.Nil(t, refreshed)
+}
+
+func TestList_HidesExpiredSessions(t *testing.T) {
+	svc, timeSource := newService()
+	stale, _ := svc.Create(context.TODO(), "acme", "user")
+
+	timeSource.now = timeSource.now.Add(20 * time.Hour)
+	fresh, _ := svc.Create(context.TODO(), "acme", "user")
+
+	timeSource.now = timeSource.now.Add(5 * time.Hour)
+	sessions, err := svc.List(context.TODO(), "acme", "user")
+
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, fresh.ID, sessions[0].ID)
+	assert.NotEqual(t, stale.ID, sessions[0].ID)
+}
+
+func TestList_OnlyOwnSessions(t *testing.T) {
+	svc, timeSource := newService()
+	first, _ := svc.Create(context.TODO(), "acme", "user")
+	timeSource.now = timeSource.now.Add(time.Minute)
+	second, _ := svc.Create(context.TODO(), "acme", "user")
+	_, _ = svc.Create(context.TODO(), "acme", "other-user")
+
+	sessions, err := svc.List(context.TODO(), "acme", "user")
+
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, second.ID, sessions[0].ID)
+	assert.Equal(t, first.ID, sessions[1].ID)
+}
+
+func TestRevoke_OtherUser(t *testing.T) {
+	svc, _ := newService()
+	created, _ := svc.Create(context.TODO(), "acme", "user")
+
+	err := svc.Revoke(context.TODO(), "acme", "other-user", created.ID)
+
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.NotFound, serviceErr.Code)
+
+	sessions, _ := svc.List(context.TODO(), "acme", "user")
+	assert.Len(t, sessions, 1)
+}
+
+func TestSessions_ScopedToTenant(t *testing.T) {
+	svc, _ := newService()
+	acme, _ := svc.Create(context.TODO(), "acme", "user")
+	globex, _ := svc.Create(context.TODO(), "globex", "user")
+
+	// The same user ID in another tenant is another user
+	sessions, err := svc.List(context.TODO(), "acme", "user")
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, acme.ID, sessions[0].ID)
+
+	err = svc.Revoke(context.TODO(), "acme", "user", globex.ID)
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.NotFound, serviceErr.Code)
+
+	assert.NoError(t, svc.RevokeAll(context.TODO(), "acme", "user"))
+	sessions, _ = svc.List(context.TODO(), "globex", "user")
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, globex.ID, sessions[0].ID)
+}
+
+func TestRevokeAll(t *testing.T) {
+	svc, _ := newService()
+	_, _ = svc.Create(context.TODO(), "acme", "user")
+	_, _ = svc.Create(context.TODO(), "acme", "user")
+	_, _ = svc.Create(context.TODO(), "acme", "other-user")
+
+	err := svc.RevokeAll(context.TODO(), "acme", "user")
+
+	assert.NoError(t, err)
+	sessions, _ := svc.List(context.TODO(), "acme", "user")
+	assert.Empty(t, sessions)
+	sessions, _ = svc.List(context.TODO(), "acme", "other-user")
+	assert.Len(t, sessions, 1)
+}
+
+func TestToSessionModel(t *testing.T) {
+	s := &session.Session{ID: "current", IP: "127.0.0.1"}
+
+	assert.True(t, s.ToSessionModel("current").Current)
+	assert.False(t, s.ToSessionModel("other").Current)
+	assert.Equal(t, "127.0.0.1", s.ToSessionModel("current").IP)
+}
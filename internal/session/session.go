@@ -0,0 +1,59 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+)
+
+// Session is a login session, shared by every token in one refresh-token family.
+type Session struct {
+	ID              string
+	Tenant          string
+	UserID          string
+	CreatedAt       time.Time
+	LastRefreshedAt time.Time
+	IP              string
+	UserAgent       string
+	RefreshTokenID  string // ID (jti) of the one refresh token of the family that may still be used
+}
+
+// ToSessionModel converts the session into its public representation.
+func (s *Session) ToSessionModel(currentSessionID string) *public_model.SessionModel {
+	return &public_model.SessionModel{
+		ID:              s.ID,
+		CreatedAt:       s.CreatedAt,
+		LastRefreshedAt: s.LastRefreshedAt,
+		IP:              s.IP,
+		UserAgent:       s.UserAgent,
+		Current:         s.ID == currentSessionID,
+	}
+}
+
+// ownedBy reports whether the session belongs to the user of the tenant. User IDs are only unique
+// within a tenant.
+func (s *Session) ownedBy(tenantID string, userID string) bool {
+	return s.Tenant == tenantID && s.UserID == userID
+}
+
+// ClientInfo describes the client a request came from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type contextKey string
+
+const clientInfoKey contextKey = "client_info"
+
+// WithClientInfo returns a copy of ctx carrying the given client info.
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey, info)
+}
+
+// ClientInfoFromContext returns the client info stored in ctx, if any.
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey).(ClientInfo)
+	return info
+}
@@ -0,0 +1,122 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrNotFound is returned by a store when a session does not exist.
+var ErrNotFound = errors.New("session not found")
+
+// ErrStale is returned by Rotate when the refresh token of the session was rotated meanwhile.
+var ErrStale = errors.New("session refresh token rotated")
+
+// IStore defines methods for persisting sessions.
+type IStore interface {
+	Save(ctx context.Context, session *Session) error
+	Get(ctx context.Context, sessionID string) (*Session, error)
+	// Rotate saves the session, provided the refresh token ID stored for it is still previousTokenID,
+	// so that two refreshes with the same token cannot both succeed.
+	Rotate(ctx context.Context, session *Session, previousTokenID string) error
+	ListByUser(ctx context.Context, tenantID string, userID string) ([]*Session, error)
+	Delete(ctx context.Context, sessionID string) error
+	DeleteByUser(ctx context.Context, tenantID string, userID string) error
+	// Ping reports whether the store is reachable. Readiness depends on it, since revoked sessions
+	// cannot be told apart from live ones without the store.
+	Ping(ctx context.Context) error
+}
+
+// MemoryStore keeps sessions in memory. Sessions do not survive a restart.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]Session
+}
+
+// NewMemoryStore initializes an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]Session)}
+}
+
+// Ping implements IStore. The memory store is always reachable.
+func (m *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Save implements IStore.
+func (m *MemoryStore) Save(ctx context.Context, session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = *session
+	return nil
+}
+
+// Get implements IStore.
+func (m *MemoryStore) Get(ctx context.Context, sessionID string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	session, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+// Rotate implements IStore.
+func (m *MemoryStore) Rotate(ctx context.Context, session *Session, previousTokenID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.sessions[session.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.RefreshTokenID != previousTokenID {
+		return ErrStale
+	}
+	m.sessions[session.ID] = *session
+	return nil
+}
+
+// ListByUser implements IStore. Sessions are returned newest first.
+func (m *MemoryStore) ListByUser(ctx context.Context, tenantID string, userID string) ([]*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sessions := []*Session{}
+	for _, session := range m.sessions {
+		if session.Tenant == tenantID && session.UserID == userID {
+			session := session
+			sessions = append(sessions, &session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+// Delete implements IStore.
+func (m *MemoryStore) Delete(ctx context.Context, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[sessionID]; !ok {
+		return ErrNotFound
+	}
+	delete(m.sessions, sessionID)
+	return nil
+}
+
+// DeleteByUser implements IStore.
+func (m *MemoryStore) DeleteByUser(ctx context.Context, tenantID string, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, session := range m.sessions {
+		if session.Tenant == tenantID && session.UserID == userID {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+// Ensure MemoryStore implements IStore.
+var _ IStore = (*MemoryStore)(nil)
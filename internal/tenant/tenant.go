@@ -0,0 +1,125 @@
+package tenant
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strings"
+
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+)
+
+// DefaultHeader is the header, or gRPC metadata key, that names the tenant of a request.
+const DefaultHeader = "X-Tenant-ID"
+
+// MaxIDLength is the longest tenant ID, that of a DNS label.
+const MaxIDLength = 63
+
+var idPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+type contextKey string
+
+const (
+	tenantKey   contextKey = "tenant"
+	resolverKey contextKey = "resolver"
+)
+
+// ValidID reports whether tenantID is a lower-case DNS label, so that it can double as a subdomain.
+func ValidID(tenantID string) bool {
+	return len(tenantID) <= MaxIDLength && idPattern.MatchString(tenantID)
+}
+
+// WithTenant returns a copy of ctx carrying the tenant the request was made for.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantID)
+}
+
+// FromContext returns the tenant the request was made for, or an empty string for the default tenant.
+func FromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantKey).(string)
+	return tenantID
+}
+
+// WithResolver returns a copy of ctx carrying the resolver the tenant was derived with, so that Select
+// only accepts the tenants it knows.
+func WithResolver(ctx context.Context, resolver *Resolver) context.Context {
+	return context.WithValue(ctx, resolverKey, resolver)
+}
+
+// Select returns the tenant a request body names, falling back to the tenant derived from the
+// request itself. A body naming a different tenant than the request, or a tenant the request's
+// resolver does not know, is rejected.
+func Select(ctx context.Context, requested string) (string, error) {
+	derived := FromContext(ctx)
+	if requested == "" {
+		return derived, nil
+	}
+	if derived != "" && derived != requested {
+		return "", common_error.NewServiceError(common_error.BadRequest, "Tenant does not match the request", nil)
+	}
+	if resolver, ok := ctx.Value(resolverKey).(*Resolver); !ValidID(requested) || ok && !resolver.Known(requested) {
+		return "", unknownTenant()
+	}
+	return requested, nil
+}
+
+// Matches reports whether a token issued to tokenTenant may be used for the tenant of the request.
+func Matches(ctx context.Context, tokenTenant string) bool {
+	return tokenTenant == FromContext(ctx)
+}
+
+// Resolver derives the tenant of a request from a header or from the host it was sent to.
+type Resolver struct {
+	Header  string              // Header naming the tenant of requests to hosts of no tenant
+	Hosts   map[string]string   // Tenant IDs keyed by lower-case host name
+	Tenants map[string]struct{} // Tenants requests may name, those the hosts map to
+}
+
+// NewResolver initializes a new Resolver. An empty header selects DefaultHeader.
+func NewResolver(header string, hosts map[string]string) *Resolver {
+	if header == "" {
+		header = DefaultHeader
+	}
+	normalized := make(map[string]string, len(hosts))
+	tenants := map[string]struct{}{}
+	for host, tenantID := range hosts {
+		normalized[strings.ToLower(host)] = tenantID
+		tenants[tenantID] = struct{}{}
+	}
+	return &Resolver{Header: header, Hosts: normalized, Tenants: tenants}
+}
+
+// Known reports whether requests may name the tenant. The default tenant, named by an empty ID, is
+// always known.
+func (r *Resolver) Known(tenantID string) bool {
+	if tenantID == "" {
+		return true
+	}
+	_, ok := r.Tenants[tenantID]
+	return ok
+}
+
+// Resolve returns the tenant mapped to the host, or else the tenant named by the header value. The
+// host may carry a port. A host's tenant is authoritative: a header naming another tenant is rejected,
+// as is one naming an unknown tenant. Requests matching neither belong to the default tenant.
+func (r *Resolver) Resolve(host string, header string) (string, error) {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	hostTenant, matched := r.Hosts[strings.ToLower(host)]
+	header = strings.TrimSpace(header)
+	switch {
+	case header == "":
+		return hostTenant, nil
+	case !ValidID(header) || !r.Known(header):
+		return "", unknownTenant()
+	case matched && header != hostTenant:
+		return "", common_error.NewServiceError(common_error.BadRequest, "Tenant does not match the host", nil)
+	}
+	return header, nil
+}
+
+// unknownTenant returns the error for a request naming a tenant that is not configured.
+func unknownTenant() error {
+	return common_error.NewServiceError(common_error.BadRequest, "Unknown tenant", nil)
+}
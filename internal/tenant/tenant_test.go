@@ -0,0 +1,76 @@
+package tenant_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	resolver := tenant.NewResolver("", map[string]string{"Acme.Example.com": "acme", "globex.example.com": "globex"})
+	assert.Equal(t, tenant.DefaultHeader, resolver.Header)
+
+	for _, tc := range []struct {
+		host, header, tenantID string
+		valid                  bool
+	}{
+		{"acme.example.com:443", "", "acme", true},
+		{"acme.example.com", " acme ", "acme", true},
+		{"example.com", " globex ", "globex", true},
+		{"example.com", "", "", true},
+		// The host's tenant is authoritative
+		{"acme.example.com", "globex", "", false},
+		{"example.com", "initech", "", false},
+		{"example.com", "Acme", "", false},
+	} {
+		tenantID, err := resolver.Resolve(tc.host, tc.header)
+		assert.Equal(t, tc.tenantID, tenantID, tc.host+" "+tc.header)
+		if tc.valid {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, common_error.BadRequest, err.(*common_error.ServiceError).Code)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	ctx := tenant.WithTenant(context.TODO(), "acme")
+
+	selected, err := tenant.Select(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", selected)
+
+	selected, err = tenant.Select(context.TODO(), "globex")
+	assert.NoError(t, err)
+	assert.Equal(t, "globex", selected)
+
+	_, err = tenant.Select(ctx, "globex")
+	assert.Equal(t, common_error.BadRequest, err.(*common_error.ServiceError).Code)
+
+	_, err = tenant.Select(context.TODO(), "Globex")
+	assert.Equal(t, common_error.BadRequest, err.(*common_error.ServiceError).Code)
+}
+
+func TestSelect_UnknownTenant(t *testing.T) {
+	resolver := tenant.NewResolver("", map[string]string{"acme.example.com": "acme"})
+	ctx := tenant.WithResolver(context.TODO(), resolver)
+
+	selected, err := tenant.Select(ctx, "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", selected)
+
+	_, err = tenant.Select(ctx, "globex")
+	assert.Equal(t, common_error.BadRequest, err.(*common_error.ServiceError).Code)
+}
+
+func TestMatches(t *testing.T) {
+	ctx := tenant.WithTenant(context.TODO(), "acme")
+
+	assert.True(t, tenant.Matches(ctx, "acme"))
+	assert.False(t, tenant.Matches(ctx, ""))
+	assert.False(t, tenant.Matches(context.TODO(), "acme"))
+	assert.True(t, tenant.Matches(context.TODO(), ""))
+}
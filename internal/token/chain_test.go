@@ -0,0 +1,108 @@
+package token_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// enricherFunc adapts a function to token.ClaimsEnricher
+type enricherFunc func(ctx context.Context, subject *token.Subject, claims *public_model.CustomClaims) error
+
+func (f enricherFunc) Enrich(ctx context.Context, subject *token.Subject, claims *public_model.CustomClaims) error {
+	return f(ctx, subject, claims)
+}
+
+// setExtra returns an enricher that sets one extra claim
+func setExtra(name string, value interface{}) token.ClaimsEnricher {
+	return enricherFunc(func(ctx context.Context, subject *token.Subject, claims *public_model.CustomClaims) error {
+		if claims.Extra == nil {
+			claims.Extra = map[string]interface{}{}
+		}
+		claims.Extra[name] = value
+		return nil
+	})
+}
+
+// failing returns an enricher that sets a claim and then fails
+func failing() token.ClaimsEnricher {
+	return enricherFunc(func(ctx context.Context, subject *token.Subject, claims *public_model.CustomClaims) error {
+		claims.Roles = append(claims.Roles, "partial")
+		return errors.New("backend down")
+	})
+}
+
+// blocking returns an enricher that waits until its context is done
+func blocking() token.ClaimsEnricher {
+	return enricherFunc(func(ctx context.Context, subject *token.Subject, claims *public_model.CustomClaims) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+}
+
+func TestEnricherChain_RunsInOrder(t *testing.T) {
+	chain := token.NewEnricherChain(
+		token.EnricherStep{Name: "first", Enricher: setExtra("plan", "free")},
+		token.EnricherStep{Name: "second", Enricher: setExtra("plan", "pro")},
+	)
+	claims := &public_model.CustomClaims{}
+
+	err := chain.Enrich(context.TODO(), &token.Subject{}, claims)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pro", claims.Extra["plan"])
+}
+
+func TestEnricherChain_FailOpen(t *testing.T) {
+	chain := token.NewEnricherChain(
+		token.EnricherStep{Name: "flaky", Enricher: failing(), OnFailure: token.FailOpen},
+		token.EnricherStep{Name: "plan", Enricher: setExtra("plan", "pro")},
+	)
+	claims := &public_model.CustomClaims{}
+
+	err := chain.Enrich(context.TODO(), &token.Subject{}, claims)
+
+	assert.NoError(t, err)
+	assert.Empty(t, claims.Roles)
+	assert.Equal(t, "pro", claims.Extra["plan"])
+}
+
+func TestEnricherChain_FailClosed(t *testing.T) {
+	chain := token.NewEnricherChain(
+		token.EnricherStep{Name: "plan", Enricher: setExtra("plan", "pro")},
+		token.EnricherStep{Name: "flaky", Enricher: failing(), OnFailure: token.FailClosed},
+	)
+	claims := &public_model.CustomClaims{}
+
+	err := chain.Enrich(context.TODO(), &token.Subject{}, claims)
+
+	assert.EqualError(t, err, `claims enricher "flaky": backend down`)
+}
+
+func TestEnricherChain_Timeout(t *testing.T) {
+	chain := token.NewEnricherChain(
+		token.EnricherStep{Name: "slow", Enricher: blocking(), Timeout: 10 * time.Millisecond, OnFailure: token.FailClosed},
+	)
+
+	err := chain.Enrich(context.TODO(), &token.Subject{}, &public_model.CustomClaims{})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestEnricherChain_TimeoutFailOpen(t *testing.T) {
+	chain := token.NewEnricherChain(
+		token.EnricherStep{Name: "slow", Enricher: blocking(), Timeout: 10 * time.Millisecond, OnFailure: token.FailOpen},
+		token.EnricherStep{Name: "plan", Enricher: setExtra("plan", "pro")},
+	)
+	claims := &public_model.CustomClaims{}
+
+	err := chain.Enrich(context.TODO(), &token.Subject{}, claims)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pro", claims.Extra["plan"])
+}
@@ -0,0 +1,127 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/scope"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	"github.com/Bit-Bridge-Source/BitBridge-UserService-Go/proto/pb"
+)
+
+// Subject describes the user a token pair is issued for.
+type Subject struct {
+	Session  *session.Session           // Session the tokens are bound to
+	User     *pb.UserResponse           // Profile from the user service, set when a session starts
+	Scopes   []string                   // Scopes the client asked for, empty for everything granted
+	Tenant   string                     // Tenant the session belongs to, empty for the default tenant
+	Previous *public_model.CustomClaims // Claims of the refresh token being exchanged, nil when a session starts
+}
+
+// ClaimsEnricher adds claims, such as the granted scopes and roles, to the tokens issued
+// when a session starts. Refreshed tokens carry over the claims of the refresh token instead.
+type ClaimsEnricher interface {
+	Enrich(ctx context.Context, subject *Subject, claims *public_model.CustomClaims) error
+}
+
+// StaticGrant is a ClaimsEnricher that grants the same scopes and roles to every user.
+type StaticGrant struct {
+	Scopes []string
+	Roles  []string
+}
+
+// Enrich implements ClaimsEnricher.
+func (g *StaticGrant) Enrich(ctx context.Context, subject *Subject, claims *public_model.CustomClaims) error {
+	claims.Scope = scope.Format(scope.Parse(claims.Scope + " " + strings.Join(g.Scopes, " ")))
+	claims.Roles = append(claims.Roles, g.Roles...)
+	return nil
+}
+
+// Ensure StaticGrant implements ClaimsEnricher.
+var _ ClaimsEnricher = (*StaticGrant)(nil)
+
+// IRoleSource looks up the roles assigned to a user of a tenant.
+type IRoleSource interface {
+	UserRoles(tenantID string, userID string) []string
+}
+
+// AssignedRoles is a ClaimsEnricher that adds the roles assigned to the session's user, such as
+// support or admin, to those the user already holds.
+type AssignedRoles struct {
+	Source IRoleSource
+}
+
+// Enrich implements ClaimsEnricher.
+func (a *AssignedRoles) Enrich(ctx context.Context, subject *Subject, claims *public_model.CustomClaims) error {
+	for _, role := range a.Source.UserRoles(subject.Tenant, subject.Session.UserID) {
+		if !slices.Contains(claims.Roles, role) {
+			claims.Roles = append(claims.Roles, role)
+		}
+	}
+	return nil
+}
+
+// Ensure AssignedRoles implements ClaimsEnricher.
+var _ ClaimsEnricher = (*AssignedRoles)(nil)
+
+// userFields reads the fields of a user service profile that UserFieldsEnricher can copy.
+var userFields = map[string]func(user *pb.UserResponse) string{
+	"id":         (*pb.UserResponse).GetId,
+	"username":   (*pb.UserResponse).GetUsername,
+	"email":      (*pb.UserResponse).GetEmail,
+	"created_at": (*pb.UserResponse).GetCreatedAt,
+	"updated_at": (*pb.UserResponse).GetUpdatedAt,
+}
+
+// UserFieldsEnricher is a ClaimsEnricher that copies selected fields of the user service
+// profile into extra claims. It never copies the password hash.
+type UserFieldsEnricher struct {
+	Fields map[string]string // Claim name keyed by user field name
+}
+
+// NewUserFieldsEnricher initializes a new UserFieldsEnricher. Fields maps user field names
+// (id, username, email, created_at, updated_at) to the claim names they are copied to.
+func NewUserFieldsEnricher(fields map[string]string) (*UserFieldsEnricher, error) {
+	for field, claim := range fields {
+		if _, ok := userFields[field]; !ok {
+			return nil, fmt.Errorf("user fields enricher: unknown user field %q", field)
+		}
+		if public_model.IsRegisteredClaim(claim) {
+			return nil, fmt.Errorf("user fields enricher: claim %q is reserved", claim)
+		}
+	}
+	return &UserFieldsEnricher{Fields: fields}, nil
+}
+
+// Enrich implements ClaimsEnricher.
+func (e *UserFieldsEnricher) Enrich(ctx context.Context, subject *Subject, claims *public_model.CustomClaims) error {
+	if subject.User == nil {
+		return nil
+	}
+	for field, claim := range e.Fields {
+		value := userFields[field](subject.User)
+		if value == "" {
+			continue
+		}
+		if claims.Extra == nil {
+			claims.Extra = make(map[string]interface{})
+		}
+		claims.Extra[claim] = value
+	}
+	return nil
+}
+
+// Ensure UserFieldsEnricher implements ClaimsEnricher.
+var _ ClaimsEnricher = (*UserFieldsEnricher)(nil)
+
+// APIKeyGrant describes what an API key may do. Its access tokens carry exactly these claims.
+type APIKeyGrant struct {
+	KeyID  string   // ID of the API key
+	UserID string   // Owner of the API key
+	Tenant string   // Tenant of the owner, empty for the default tenant
+	Scopes []string // Scopes granted to the key
+	Roles  []string // Roles of the owner when the key was issued
+}
@@ -0,0 +1,103 @@
+package token_test
+
+import (
+	"context"
+	"testing"
+
+	internal_jwt "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/jwt"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	"github.com/Bit-Bridge-Source/BitBridge-UserService-Go/proto/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticGrant(t *testing.T) {
+	grant := &token.StaticGrant{Scopes: []string{"sessions", "profile"}, Roles: []string{"user"}}
+	claims := &public_model.CustomClaims{Scope: "profile"}
+
+	err := grant.Enrich(context.TODO(), &token.Subject{}, claims)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "profile sessions", claims.Scope)
+	assert.Equal(t, []string{"user"}, claims.Roles)
+}
+
+// roleSource assigns roles by tenant and user ID.
+type roleSource map[string]map[string][]string
+
+func (r roleSource) UserRoles(tenantID string, userID string) []string {
+	return r[tenantID][userID]
+}
+
+func TestAssignedRoles(t *testing.T) {
+	enricher := &token.AssignedRoles{Source: roleSource{"acme": {"admin-user": {"user", "admin"}}}}
+
+	claims := &public_model.CustomClaims{Roles: []string{"user"}}
+	err := enricher.Enrich(context.TODO(), &token.Subject{Session: &session.Session{UserID: "admin-user"}, Tenant: "acme"}, claims)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user", "admin"}, claims.Roles)
+
+	claims = &public_model.CustomClaims{Roles: []string{"user"}}
+	err = enricher.Enrich(context.TODO(), &token.Subject{Session: &session.Session{UserID: "other-user"}, Tenant: "acme"}, claims)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user"}, claims.Roles)
+
+	// The same user ID in another tenant is another user
+	claims = &public_model.CustomClaims{Roles: []string{"user"}}
+	err = enricher.Enrich(context.TODO(), &token.Subject{Session: &session.Session{UserID: "admin-user"}, Tenant: "globex"}, claims)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user"}, claims.Roles)
+}
+
+func TestUserFieldsEnricher(t *testing.T) {
+	enricher, err := token.NewUserFieldsEnricher(map[string]string{"username": "preferred_username", "email": "email"})
+	assert.NoError(t, err)
+
+	claims := &public_model.CustomClaims{}
+	user := &pb.UserResponse{Id: "user", Username: "test", Email: "test@test.com", Hash: "hash"}
+
+	err = enricher.Enrich(context.TODO(), &token.Subject{User: user}, claims)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"preferred_username": "test", "email": "test@test.com"}, claims.Extra)
+}
+
+func TestUserFieldsEnricher_NoUser(t *testing.T) {
+	enricher, _ := token.NewUserFieldsEnricher(map[string]string{"username": "preferred_username"})
+	claims := &public_model.CustomClaims{}
+
+	err := enricher.Enrich(context.TODO(), &token.Subject{}, claims)
+
+	assert.NoError(t, err)
+	assert.Nil(t, claims.Extra)
+}
+
+func TestNewUserFieldsEnricher_Invalid(t *testing.T) {
+	_, err := token.NewUserFieldsEnricher(map[string]string{"hash": "hash"})
+	assert.Error(t, err)
+
+	_, err = token.NewUserFieldsEnricher(map[string]string{"username": "sub"})
+	assert.Error(t, err)
+}
+
+func TestExtraClaims_RoundTrip(t *testing.T) {
+	jwtHandler := internal_jwt.NewSimpleJWTHandler([]byte("secret"))
+	claims := public_model.CustomClaims{
+		UserID: "user",
+		Scope:  "profile",
+		// Extra claims cannot shadow registered ones
+		Extra: map[string]interface{}{"plan": "pro", "user_id": "admin"},
+	}
+
+	signed, err := jwtHandler.Generate(claims)
+	assert.NoError(t, err)
+
+	parsed := &public_model.CustomClaims{}
+	_, err = jwtHandler.Parse(signed, parsed)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user", parsed.UserID)
+	assert.Equal(t, "profile", parsed.Scope)
+	assert.Equal(t, map[string]interface{}{"plan": "pro"}, parsed.Extra)
+}
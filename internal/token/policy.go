@@ -0,0 +1,121 @@
+package token
+
+import (
+	"fmt"
+	"time"
+)
+
+// Policy controls the lifetimes of the tokens issued to a client.
+type Policy struct {
+	Audience   string        // Audience claim of the issued tokens, empty for the default policy
+	AccessTTL  time.Duration // Lifetime of access tokens
+	RefreshTTL time.Duration // Lifetime of refresh tokens
+	MaxSession time.Duration // No token outlives its session start by more than this
+	Leeway     time.Duration // Allowed clock skew when checking exp, nbf and iat
+}
+
+// Policies holds the default token policy together with per-client overrides.
+type Policies struct {
+	Default         Policy            // Applies to requests that do not name a client
+	ServiceTokenTTL time.Duration     // Lifetime of the tokens the service uses to call the user service
+	APIKeyTokenTTL  time.Duration     // Lifetime of the access tokens API keys are exchanged for
+	ImpersonateTTL  time.Duration     // Lifetime of the access tokens issued to admins acting as a user
+	Clients         map[string]Policy // Overrides keyed by client ID, which doubles as the token audience
+}
+
+// DefaultPolicies returns the lifetimes the service used before they became configurable, and
+// short lifetimes for the tokens API keys are exchanged for and for impersonation tokens.
+func DefaultPolicies() Policies {
+	return Policies{
+		Default: Policy{
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 7 * 24 * time.Hour,
+			MaxSession: 30 * 24 * time.Hour,
+			Leeway:     30 * time.Second,
+		},
+		ServiceTokenTTL: 15 * time.Minute,
+		APIKeyTokenTTL:  5 * time.Minute,
+		ImpersonateTTL:  10 * time.Minute,
+	}
+}
+
+// For returns the policy of the given client. Durations a client override leaves unset are
+// taken from the default policy. The second result is false for unknown clients.
+func (p Policies) For(clientID string) (Policy, bool) {
+	if clientID == "" {
+		return p.Default, true
+	}
+
+	override, ok := p.Clients[clientID]
+	if !ok {
+		return p.Default, false
+	}
+
+	policy := p.Default
+	policy.Audience = clientID
+	if override.AccessTTL > 0 {
+		policy.AccessTTL = override.AccessTTL
+	}
+	if override.RefreshTTL > 0 {
+		policy.RefreshTTL = override.RefreshTTL
+	}
+	if override.MaxSession > 0 {
+		policy.MaxSession = override.MaxSession
+	}
+	if override.Leeway > 0 {
+		policy.Leeway = override.Leeway
+	}
+	return policy, true
+}
+
+// LongestSession returns the longest maximum session lifetime of any policy. No session can be
+// refreshed past it, whichever client it was started by.
+func (p Policies) LongestSession() time.Duration {
+	longest := p.Default.MaxSession
+	for _, override := range p.Clients {
+		if override.MaxSession > longest {
+			longest = override.MaxSession
+		}
+	}
+	return longest
+}
+
+// Validate checks that every resolved policy has usable lifetimes.
+func (p Policies) Validate() error {
+	if p.ServiceTokenTTL <= 0 {
+		return fmt.Errorf("token policy: service token TTL must be positive")
+	}
+	if p.APIKeyTokenTTL <= 0 {
+		return fmt.Errorf("token policy: API key token TTL must be positive")
+	}
+	if p.ImpersonateTTL <= 0 {
+		return fmt.Errorf("token policy: impersonation TTL must be positive")
+	}
+	if err := p.Default.validate(); err != nil {
+		return fmt.Errorf("token policy: default: %w", err)
+	}
+	for clientID := range p.Clients {
+		policy, _ := p.For(clientID)
+		if err := policy.validate(); err != nil {
+			return fmt.Errorf("token policy: client %q: %w", clientID, err)
+		}
+	}
+	return nil
+}
+
+// validate checks the lifetimes of a single policy.
+func (p Policy) validate() error {
+	switch {
+	case p.AccessTTL <= 0:
+		return fmt.Errorf("access TTL must be positive")
+	case p.RefreshTTL <= 0:
+		return fmt.Errorf("refresh TTL must be positive")
+	case p.MaxSession <= 0:
+		return fmt.Errorf("max session must be positive")
+	case p.AccessTTL > p.RefreshTTL:
+		return fmt.Errorf("access TTL %v exceeds refresh TTL %v", p.AccessTTL, p.RefreshTTL)
+	case p.Leeway < 0:
+		return fmt.Errorf("leeway must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package token_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoliciesFor_Default(t *testing.T) {
+	policies := token.DefaultPolicies()
+
+	policy, ok := policies.For("")
+
+	assert.True(t, ok)
+	assert.Equal(t, policies.Default, policy)
+	assert.Empty(t, policy.Audience)
+}
+
+func TestPoliciesFor_ClientOverride(t *testing.T) {
+	policies := token.DefaultPolicies()
+	policies.Clients = map[string]token.Policy{
+		"mobile": {RefreshTTL: 60 * 24 * time.Hour, MaxSession: 90 * 24 * time.Hour},
+	}
+
+	policy, ok := policies.For("mobile")
+
+	assert.True(t, ok)
+	assert.Equal(t, "mobile", policy.Audience)
+	assert.Equal(t, policies.Default.AccessTTL, policy.AccessTTL)
+	assert.Equal(t, 60*24*time.Hour, policy.RefreshTTL)
+	assert.Equal(t, 90*24*time.Hour, policy.MaxSession)
+	assert.Equal(t, policies.Default.Leeway, policy.Leeway)
+}
+
+func TestPoliciesFor_UnknownClient(t *testing.T) {
+	policies := token.DefaultPolicies()
+
+	_, ok := policies.For("unknown")
+
+	assert.False(t, ok)
+}
+
+func TestPolicies_LongestSession(t *testing.T) {
+	policies := token.DefaultPolicies()
+	assert.Equal(t, policies.Default.MaxSession, policies.LongestSession())
+
+	policies.Clients = map[string]token.Policy{
+		"mobile": {MaxSession: 90 * 24 * time.Hour},
+		"kiosk":  {MaxSession: time.Hour},
+		"web":    {AccessTTL: time.Minute},
+	}
+	assert.Equal(t, 90*24*time.Hour, policies.LongestSession())
+}
+
+func TestPoliciesValidate(t *testing.T) {
+	assert.NoError(t, token.DefaultPolicies().Validate())
+
+	policies := token.DefaultPolicies()
+	policies.Default.AccessTTL = 0
+	assert.EqualError(t, policies.Validate(), "token policy: default: access TTL must be positive")
+
+	policies = token.DefaultPolicies()
+	policies.Clients = map[string]token.Policy{"cli": {AccessTTL: 30 * 24 * time.Hour}}
+	assert.ErrorContains(t, policies.Validate(), `client "cli": access TTL`)
+
+	policies = token.DefaultPolicies()
+	policies.ServiceTokenTTL = 0
+	assert.Error(t, policies.Validate())
+}
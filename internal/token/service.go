@@ -3,62 +3,209 @@ package token
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	internal_jwt "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/jwt"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/scope"
 	internal_time "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/time"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tracing"
 	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
 	"github.com/golang-jwt/jwt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Token types carried in the typ claim.
+const (
+	AccessTokenType  = "access"
+	RefreshTokenType = "refresh"
 )
 
 // ITokenService defines methods for handling token operations.
 type ITokenService interface {
 	CreateToken(ctx context.Context, userID string, duration time.Duration) (string, error)
-	CreateTokenPair(ctx context.Context, userID string) (*public_model.TokenModel, error)
-	RefreshToken(ctx context.Context, refreshToken string) (*public_model.TokenModel, error)
+	CreateServiceToken(ctx context.Context) (string, error)
+	CreateAPIKeyToken(ctx context.Context, grant *APIKeyGrant) (*public_model.AccessTokenModel, error)
+	CreateImpersonationToken(ctx context.Context, subject *Subject, actor *public_model.CustomClaims) (*public_model.AccessTokenModel, error)
+	Policy(clientID string) (Policy, error)
+	CreateTokenPair(ctx context.Context, subject *Subject, policy Policy) (*public_model.TokenModel, error)
+	ParseToken(ctx context.Context, tokenString string) (*public_model.CustomClaims, error)
 }
 
 // TokenService contains fields necessary for token operations.
 type TokenService struct {
-	Time internal_time.TimeSource // Source to get the current time
-	JWT  internal_jwt.JWTHandler  // Handler to manage JWT tokens
+	Time     internal_time.TimeSource // Source to get the current time
+	JWT      internal_jwt.JWTHandler  // Handler to manage JWT tokens
+	Policies Policies                 // Token lifetimes per client
+	Enricher ClaimsEnricher           // Adds claims when a session starts, may be nil
 }
 
 // NewTokenService initializes a new TokenService with necessary dependencies.
-func NewTokenService(time internal_time.TimeSource, jwt internal_jwt.JWTHandler) *TokenService {
+func NewTokenService(time internal_time.TimeSource, jwt internal_jwt.JWTHandler, policies Policies, enricher ClaimsEnricher) *TokenService {
 	return &TokenService{
-		Time: time,
-		JWT:  jwt,
+		Time:     time,
+		JWT:      jwt,
+		Policies: policies,
+		Enricher: enricher,
 	}
 }
 
 // CreateToken generates a new JWT token with custom claims.
 func (t *TokenService) CreateToken(ctx context.Context, userID string, duration time.Duration) (string, error) {
-	expiration := t.Time.Now().Add(duration).Unix()
+	claims := public_model.CustomClaims{UserID: userID}
+	claims.ExpiresAt = t.Time.Now().Add(duration).Unix()
+	return t.sign(ctx, claims)
+}
 
+// CreateServiceToken generates the short-lived token the service presents to the user service.
+func (t *TokenService) CreateServiceToken(ctx context.Context) (string, error) {
+	return t.CreateToken(ctx, "-1", t.Policies.ServiceTokenTTL)
+}
+
+// CreateAPIKeyToken issues a short-lived access token on behalf of an API key. The token carries the
+// key's scopes and roles, is signed for the key's tenant, and cannot be refreshed.
+func (t *TokenService) CreateAPIKeyToken(ctx context.Context, grant *APIKeyGrant) (*public_model.AccessTokenModel, error) {
 	claims := public_model.CustomClaims{
-		UserID: userID,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expiration,
-		},
+		UserID:    grant.UserID,
+		TokenType: AccessTokenType,
+		Scope:     scope.Format(grant.Scopes),
+		Roles:     grant.Roles,
+		Tenant:    grant.Tenant,
+		APIKeyID:  grant.KeyID,
 	}
 
-	tokenString, err := t.JWT.Generate(claims)
+	accessToken, err := t.createToken(ctx, claims, t.Policies.APIKeyTokenTTL, t.Policies.Default)
+	if errors.Is(err, internal_jwt.ErrUnknownTenant) {
+		return nil, common_error.NewServiceError(common_error.BadRequest, "Unknown tenant", err)
+	}
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return tokenString, nil
+	return &public_model.AccessTokenModel{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(t.Policies.APIKeyTokenTTL / time.Second),
+	}, nil
 }
 
-// CreateTokenPair generates a pair of access and refresh tokens.
-func (t *TokenService) CreateTokenPair(ctx context.Context, userID string) (*public_model.TokenModel, error) {
-	accessToken, err := t.CreateToken(ctx, userID, 15*time.Minute)
+// CreateImpersonationToken issues a short-lived access token that lets the actor act as the subject's
+// user. The token carries the claims a login of the user would get, names the actor in its act claim,
+// and cannot be refreshed.
+func (t *TokenService) CreateImpersonationToken(ctx context.Context, subject *Subject, actor *public_model.CustomClaims) (*public_model.AccessTokenModel, error) {
+	claims := public_model.CustomClaims{
+		UserID:    subject.User.GetId(),
+		TokenType: AccessTokenType,
+		Tenant:    subject.Tenant,
+		Actor:     &public_model.Actor{UserID: actor.UserID},
+	}
+	if t.Enricher != nil {
+		if err := t.Enricher.Enrich(ctx, subject, &claims); err != nil {
+			return nil, err
+		}
+	}
+
+	accessToken, err := t.createToken(ctx, claims, t.Policies.ImpersonateTTL, t.Policies.Default)
+	if errors.Is(err, internal_jwt.ErrUnknownTenant) {
+		return nil, common_error.NewServiceError(common_error.BadRequest, "Unknown tenant", err)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := t.CreateToken(ctx, userID, 24*7*time.Hour)
+	return &public_model.AccessTokenModel{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(t.Policies.ImpersonateTTL / time.Second),
+	}, nil
+}
+
+// Policy returns the token policy of the given client. An empty client ID selects the default policy.
+func (t *TokenService) Policy(clientID string) (Policy, error) {
+	policy, ok := t.Policies.For(clientID)
+	if !ok {
+		return Policy{}, common_error.NewServiceError(common_error.BadRequest, "Unknown client", nil)
+	}
+	return policy, nil
+}
+
+// createToken sets the audience and expiration on the given claims and signs them. Tokens
+// bound to a session never expire after the session's maximum lifetime.
+func (t *TokenService) createToken(ctx context.Context, claims public_model.CustomClaims, duration time.Duration, policy Policy) (string, error) {
+	claims.Audience = policy.Audience
+	claims.ExpiresAt = t.Time.Now().Add(duration).Unix()
+	if claims.AuthTime != 0 {
+		sessionEnd := time.Unix(claims.AuthTime, 0).Add(policy.MaxSession).Unix()
+		if sessionEnd < claims.ExpiresAt {
+			claims.ExpiresAt = sessionEnd
+		}
+	}
+
+	return t.sign(ctx, claims)
+}
+
+// sign signs the claims in a span of its own, so that signing shows apart from key lookups and enrichment.
+func (t *TokenService) sign(ctx context.Context, claims public_model.CustomClaims) (string, error) {
+	_, span := tracing.Start(ctx, "TokenService.Sign", trace.WithAttributes(attribute.String("token.type", claims.TokenType)))
+	defer span.End()
+
+	tokenString, err := t.JWT.Generate(claims)
+	tracing.Fail(span, err)
+	return tokenString, err
+}
+
+// CreateTokenPair generates a pair of access and refresh tokens bound to the subject's session,
+// with lifetimes taken from the policy. Sessions past the policy's maximum lifetime get no tokens.
+// The tokens carry the requested scopes, which must all have been granted, and are signed for the
+// subject's tenant. Refreshed tokens stay in the tenant of the refresh token.
+func (t *TokenService) CreateTokenPair(ctx context.Context, subject *Subject, policy Policy) (*public_model.TokenModel, error) {
+	ctx, span := tracing.Start(ctx, "TokenService.CreateTokenPair")
+	defer span.End()
+
+	userSession := subject.Session
+	if !t.Time.Now().Before(userSession.CreatedAt.Add(policy.MaxSession)) {
+		return nil, common_error.NewServiceError(common_error.SessionExpired, "Session has expired", nil)
+	}
+
+	claims := public_model.CustomClaims{
+		UserID:    userSession.UserID,
+		SessionID: userSession.ID,
+		AuthTime:  userSession.CreatedAt.Unix(),
+		Tenant:    subject.Tenant,
+	}
+	if subject.Previous != nil {
+		claims.Tenant = subject.Previous.Tenant
+		claims.Scope = subject.Previous.Scope
+		claims.Roles = subject.Previous.Roles
+		claims.Extra = subject.Previous.Extra
+	} else if t.Enricher != nil {
+		if err := t.Enricher.Enrich(ctx, subject, &claims); err != nil {
+			return nil, err
+		}
+	}
+
+	scopes, missing := scope.Narrow(scope.Parse(claims.Scope), subject.Scopes)
+	if missing != "" {
+		return nil, common_error.NewServiceError(common_error.BadRequest, fmt.Sprintf("Scope %q was not granted", missing), nil)
+	}
+	claims.Scope = scope.Format(scopes)
+
+	accessClaims := claims
+	accessClaims.TokenType = AccessTokenType
+	accessToken, err := t.createToken(ctx, accessClaims, policy.AccessTTL, policy)
+	if errors.Is(err, internal_jwt.ErrUnknownTenant) {
+		return nil, common_error.NewServiceError(common_error.BadRequest, "Unknown tenant", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	refreshClaims := claims
+	refreshClaims.TokenType = RefreshTokenType
+	refreshClaims.Id = userSession.RefreshTokenID
+	refreshToken, err := t.createToken(ctx, refreshClaims, policy.RefreshTTL, policy)
 	if err != nil {
 		return nil, err
 	}
@@ -71,25 +218,47 @@ func (t *TokenService) CreateTokenPair(ctx context.Context, userID string) (*pub
 	return tokenModel, nil
 }
 
-// RefreshToken validates the refresh token and generates a new token pair if valid.
-func (t *TokenService) RefreshToken(ctx context.Context, refreshToken string) (*public_model.TokenModel, error) {
-	claims := &public_model.CustomClaims{}
+// ParseToken validates a token and returns its claims.
+func (t *TokenService) ParseToken(ctx context.Context, tokenString string) (*public_model.CustomClaims, error) {
+	_, span := tracing.Start(ctx, "TokenService.ParseToken")
+	defer span.End()
 
-	token, err := t.JWT.Parse(refreshToken, claims)
+	claims := &public_model.CustomClaims{}
+	token, err := t.JWT.Parse(tokenString, claims)
 	if err != nil {
-		return nil, err
-	}
-
-	if !token.Valid {
+		// Time-based failures are checked again below, with the client's leeway
+		var validationErr *jwt.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Errors&^timeValidationErrors != 0 {
+			return nil, err
+		}
+	} else if !token.Valid {
 		return nil, errors.New("invalid token")
 	}
 
-	tokenModel, err := t.CreateTokenPair(ctx, claims.UserID)
-	if err != nil {
+	policy, _ := t.Policies.For(claims.Audience)
+	if err := t.verifyTimes(claims, policy.Leeway); err != nil {
 		return nil, err
 	}
 
-	return tokenModel, nil
+	return claims, nil
+}
+
+// timeValidationErrors are the claim validation failures that depend on the clock.
+const timeValidationErrors = jwt.ValidationErrorExpired | jwt.ValidationErrorNotValidYet | jwt.ValidationErrorIssuedAt
+
+// verifyTimes checks the exp, nbf and iat claims against the service clock, tolerating the given skew.
+func (t *TokenService) verifyTimes(claims *public_model.CustomClaims, leeway time.Duration) error {
+	now := t.Time.Now()
+	if claims.ExpiresAt != 0 && now.Add(-leeway).Unix() > claims.ExpiresAt {
+		return errors.New("token is expired")
+	}
+	if claims.NotBefore != 0 && now.Add(leeway).Unix() < claims.NotBefore {
+		return errors.New("token is not valid yet")
+	}
+	if claims.IssuedAt != 0 && now.Add(leeway).Unix() < claims.IssuedAt {
+		return errors.New("token used before issued")
+	}
+	return nil
 }
 
 // Ensure that TokenService implements ITokenService.
@@ -5,8 +5,13 @@ import (
 	"testing"
 	"time"
 
+	internal_jwt "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/jwt"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/secret"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/session"
 	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/token"
 	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	common_error "github.com/Bit-Bridge-Source/BitBridge-CommonService-Go/public/error"
+	"github.com/Bit-Bridge-Source/BitBridge-UserService-Go/proto/pb"
 	"github.com/golang-jwt/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -32,10 +37,18 @@ func (m *MockJWTHandler) Parse(tokenString string, claims jwt.Claims) (*jwt.Toke
 	return args.Get(0).(*jwt.Token), args.Error(1)
 }
 
+var policies = token.DefaultPolicies()
+
+var maxSessionLifetime = policies.Default.MaxSession
+
+func testSession() *session.Session {
+	return &session.Session{ID: "test-session", UserID: "test-user", CreatedAt: time.Now()}
+}
+
 func TestCreateToken_Success(t *testing.T) {
 	timeSource := &MockTimeSource{}
 	jwtHandler := new(MockJWTHandler)
-	svc := token.NewTokenService(timeSource, jwtHandler)
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, nil)
 
 	jwtHandler.On("Generate", mock.Anything).Return("mockToken", nil)
 
@@ -49,7 +62,7 @@ func TestCreateToken_Success(t *testing.T) {
 func TestCreateToken_Error(t *testing.T) {
 	timeSource := &MockTimeSource{}
 	jwtHandler := new(MockJWTHandler)
-	svc := token.NewTokenService(timeSource, jwtHandler)
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, nil)
 
 	jwtHandler.On("Generate", mock.Anything).Return("", assert.AnError)
 
@@ -63,11 +76,11 @@ func TestCreateToken_Error(t *testing.T) {
 func TestCreateTokenPair_Success(t *testing.T) {
 	timeSource := &MockTimeSource{}
 	jwtHandler := new(MockJWTHandler)
-	svc := token.NewTokenService(timeSource, jwtHandler)
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, nil)
 
 	jwtHandler.On("Generate", mock.Anything).Return("mockToken", nil).Twice()
 
-	tokenPair, err := svc.CreateTokenPair(context.TODO(), "test-user")
+	tokenPair, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: testSession()}, policies.Default)
 
 	assert.NoError(t, err)
 
@@ -84,101 +97,364 @@ func TestCreateTokenPair_Success(t *testing.T) {
 func TestCreateTokenPair_Error(t *testing.T) {
 	timeSource := &MockTimeSource{}
 	jwtHandler := new(MockJWTHandler)
-	svc := token.NewTokenService(timeSource, jwtHandler)
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, nil)
 
 	jwtHandler.On("Generate", mock.Anything).Return("", assert.AnError).Once()
 
-	tokenPair, err := svc.CreateTokenPair(context.TODO(), "test-user")
+	tokenPair, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: testSession()}, policies.Default)
 
 	assert.Error(t, err)
 	assert.Nil(t, tokenPair)
 	jwtHandler.AssertExpectations(t)
 }
 
-func TestRefreshToken_Success(t *testing.T) {
+func TestCreateTokenPair_CreateTokenError_Generate(t *testing.T) {
+	mockJWTHandler := new(MockJWTHandler)
+	mockTimeSource := &MockTimeSource{}
+	svc := token.NewTokenService(mockTimeSource, mockJWTHandler, policies, nil)
+
+	// Mock Generate to return success for the first call and error for the second call
+	mockJWTHandler.On("Generate", mock.Anything).Return("mockToken", nil).Once()
+	mockJWTHandler.On("Generate", mock.Anything).Return("", assert.AnError).Once()
+
+	_, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: testSession()}, policies.Default)
+
+	assert.Error(t, err)
+	mockJWTHandler.AssertExpectations(t)
+}
+
+func TestCreateTokenPair_Claims(t *testing.T) {
 	timeSource := &MockTimeSource{}
 	jwtHandler := new(MockJWTHandler)
-	svc := token.NewTokenService(timeSource, jwtHandler)
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, nil)
 
-	token := &jwt.Token{Valid: true}
+	userSession := testSession()
+	userSession.RefreshTokenID = "test-refresh"
+	jwtHandler.On("Generate", mock.MatchedBy(func(claims public_model.CustomClaims) bool {
+		return claims.UserID == "test-user" && claims.SessionID == "test-session" && claims.AuthTime == userSession.CreatedAt.Unix() && claims.TokenType == token.AccessTokenType && claims.Id == ""
+	})).Return("accessToken", nil).Once()
+	// Only the refresh token carries the ID the session rotates on every refresh
+	jwtHandler.On("Generate", mock.MatchedBy(func(claims public_model.CustomClaims) bool {
+		return claims.UserID == "test-user" && claims.SessionID == "test-session" && claims.AuthTime == userSession.CreatedAt.Unix() && claims.TokenType == token.RefreshTokenType && claims.Id == "test-refresh"
+	})).Return("refreshToken", nil).Once()
 
-	// Mock the Parse method to return a valid token and claims
-	jwtHandler.On("Parse", mock.Anything, mock.Anything).Return(token, nil).Once()
-	// Mock the Generate method to return mock tokens
-	jwtHandler.On("Generate", mock.Anything).Return("newMockToken", nil).Twice()
-
-	newTokenPair, err := svc.RefreshToken(context.TODO(), "valid-refresh-token")
+	tokenPair, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: userSession}, policies.Default)
 
 	assert.NoError(t, err)
+	assert.Equal(t, "accessToken", tokenPair.AccessToken)
+	assert.Equal(t, "refreshToken", tokenPair.RefreshToken)
+	jwtHandler.AssertExpectations(t)
+}
 
-	expectedNewTokenPair := &public_model.TokenModel{
-		AccessToken:  "newMockToken",
-		RefreshToken: "newMockToken",
-	}
+func TestParseToken_Success(t *testing.T) {
+	timeSource := &MockTimeSource{}
+	jwtHandler := new(MockJWTHandler)
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, nil)
+
+	jwtHandler.On("Parse", "valid-token", mock.Anything).Run(func(args mock.Arguments) {
+		claims := args.Get(1).(*public_model.CustomClaims)
+		claims.UserID = "test-user"
+		claims.SessionID = "test-session"
+	}).Return(&jwt.Token{Valid: true}, nil)
 
-	assert.Equal(t, expectedNewTokenPair, newTokenPair)
+	claims, err := svc.ParseToken(context.TODO(), "valid-token")
 
+	assert.NoError(t, err)
+	assert.Equal(t, "test-user", claims.UserID)
+	assert.Equal(t, "test-session", claims.SessionID)
 	jwtHandler.AssertExpectations(t)
 }
 
-func TestRefreshToken_Error(t *testing.T) {
+func TestParseToken_Invalid(t *testing.T) {
 	timeSource := &MockTimeSource{}
 	jwtHandler := new(MockJWTHandler)
-	svc := token.NewTokenService(timeSource, jwtHandler)
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, nil)
 
-	jwtHandler.On("Parse", mock.Anything, mock.Anything).Return((*jwt.Token)(nil), assert.AnError)
+	jwtHandler.On("Parse", "invalid-token", mock.Anything).Return(&jwt.Token{}, nil)
 
-	tokenPair, err := svc.RefreshToken(context.TODO(), "bad-refresh-token")
+	claims, err := svc.ParseToken(context.TODO(), "invalid-token")
 
 	assert.Error(t, err)
-	assert.Nil(t, tokenPair)
+	assert.Nil(t, claims)
 	jwtHandler.AssertExpectations(t)
 }
 
-func TestRefreshToken_Invalid(t *testing.T) {
+func TestCreateTokenPair_CappedBySessionLifetime(t *testing.T) {
 	timeSource := &MockTimeSource{}
 	jwtHandler := new(MockJWTHandler)
-	svc := token.NewTokenService(timeSource, jwtHandler)
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, nil)
 
-	token := &jwt.Token{}
-	jwtHandler.On("Parse", mock.Anything, mock.Anything).Return(token, nil)
+	// The session ends in one hour, well before the refresh token's own lifetime
+	userSession := testSession()
+	userSession.CreatedAt = time.Now().Add(-maxSessionLifetime + time.Hour)
+	sessionEnd := userSession.CreatedAt.Add(maxSessionLifetime).Unix()
 
-	tokenPair, err := svc.RefreshToken(context.TODO(), "invalid token")
+	jwtHandler.On("Generate", mock.MatchedBy(func(claims public_model.CustomClaims) bool {
+		return claims.TokenType == token.AccessTokenType && claims.ExpiresAt < sessionEnd
+	})).Return("accessToken", nil).Once()
+	jwtHandler.On("Generate", mock.MatchedBy(func(claims public_model.CustomClaims) bool {
+		return claims.TokenType == token.RefreshTokenType && claims.ExpiresAt == sessionEnd
+	})).Return("refreshToken", nil).Once()
 
-	assert.Error(t, err)
+	_, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: userSession}, policies.Default)
+
+	assert.NoError(t, err)
+	jwtHandler.AssertExpectations(t)
+}
+
+func TestCreateTokenPair_ClientPolicy(t *testing.T) {
+	timeSource := &MockTimeSource{}
+	jwtHandler := new(MockJWTHandler)
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, nil)
+
+	policy := token.Policy{Audience: "mobile", AccessTTL: 5 * time.Minute, RefreshTTL: time.Hour, MaxSession: maxSessionLifetime}
+	now := time.Now()
+
+	jwtHandler.On("Generate", mock.MatchedBy(func(claims public_model.CustomClaims) bool {
+		return claims.TokenType == token.AccessTokenType && claims.Audience == "mobile" &&
+			claims.ExpiresAt >= now.Add(5*time.Minute).Unix() && claims.ExpiresAt < now.Add(6*time.Minute).Unix()
+	})).Return("accessToken", nil).Once()
+	jwtHandler.On("Generate", mock.MatchedBy(func(claims public_model.CustomClaims) bool {
+		return claims.TokenType == token.RefreshTokenType && claims.Audience == "mobile" &&
+			claims.ExpiresAt >= now.Add(time.Hour).Unix() && claims.ExpiresAt < now.Add(time.Hour+time.Minute).Unix()
+	})).Return("refreshToken", nil).Once()
+
+	_, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: testSession()}, policy)
+
+	assert.NoError(t, err)
+	jwtHandler.AssertExpectations(t)
+}
+
+func TestCreateTokenPair_SessionPastMaxLifetime(t *testing.T) {
+	timeSource := &MockTimeSource{}
+	jwtHandler := new(MockJWTHandler)
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, nil)
+
+	userSession := testSession()
+	userSession.CreatedAt = time.Now().Add(-maxSessionLifetime)
+
+	tokenPair, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: userSession}, policies.Default)
+
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.SessionExpired, serviceErr.Code)
 	assert.Nil(t, tokenPair)
+	jwtHandler.AssertNotCalled(t, "Generate", mock.Anything)
+}
+
+func TestCreateServiceToken(t *testing.T) {
+	timeSource := &MockTimeSource{}
+	jwtHandler := new(MockJWTHandler)
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, nil)
+
+	jwtHandler.On("Generate", mock.MatchedBy(func(claims public_model.CustomClaims) bool {
+		return claims.UserID == "-1" && claims.ExpiresAt <= time.Now().Add(policies.ServiceTokenTTL).Unix()
+	})).Return("serviceToken", nil).Once()
+
+	serviceToken, err := svc.CreateServiceToken(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "serviceToken", serviceToken)
+}
+
+func TestPolicy_UnknownClient(t *testing.T) {
+	svc := token.NewTokenService(&MockTimeSource{}, new(MockJWTHandler), policies, nil)
+
+	_, err := svc.Policy("unknown")
+
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.BadRequest, serviceErr.Code)
+}
+
+func TestParseToken_Leeway(t *testing.T) {
+	jwtHandler := internal_jwt.NewSimpleJWTHandler([]byte("secret"))
+	svc := token.NewTokenService(&MockTimeSource{}, jwtHandler, policies, nil)
+
+	claims := public_model.CustomClaims{UserID: "test-user"}
+	claims.ExpiresAt = time.Now().Add(-policies.Default.Leeway / 2).Unix()
+	withinLeeway, _ := jwtHandler.Generate(claims)
+
+	parsed, err := svc.ParseToken(context.TODO(), withinLeeway)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "test-user", parsed.UserID)
+
+	claims.ExpiresAt = time.Now().Add(-2 * policies.Default.Leeway).Unix()
+	expired, _ := jwtHandler.Generate(claims)
+
+	parsed, err = svc.ParseToken(context.TODO(), expired)
+
+	assert.Error(t, err)
+	assert.Nil(t, parsed)
+}
+
+func TestParseToken_BadSignature(t *testing.T) {
+	svc := token.NewTokenService(&MockTimeSource{}, internal_jwt.NewSimpleJWTHandler([]byte("secret")), policies, nil)
+
+	claims := public_model.CustomClaims{UserID: "test-user"}
+	claims.ExpiresAt = time.Now().Add(-policies.Default.Leeway / 2).Unix()
+	forged, _ := internal_jwt.NewSimpleJWTHandler([]byte("other")).Generate(claims)
+
+	parsed, err := svc.ParseToken(context.TODO(), forged)
+
+	assert.Error(t, err)
+	assert.Nil(t, parsed)
+}
+
+func TestCreateTokenPair_EnrichesNewSession(t *testing.T) {
+	timeSource := &MockTimeSource{}
+	jwtHandler := new(MockJWTHandler)
+	grant := &token.StaticGrant{Scopes: []string{"sessions", "profile"}, Roles: []string{"user"}}
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, grant)
+
+	jwtHandler.On("Generate", mock.MatchedBy(func(claims public_model.CustomClaims) bool {
+		return claims.Scope == "profile" && assert.ObjectsAreEqual([]string{"user"}, claims.Roles)
+	})).Return("token", nil).Twice()
+
+	_, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: testSession(), Scopes: []string{"profile"}}, policies.Default)
+
+	assert.NoError(t, err)
 	jwtHandler.AssertExpectations(t)
 }
 
-func TestCreateTokenPair_CreateTokenError_Generate(t *testing.T) {
-	mockJWTHandler := new(MockJWTHandler)
-	mockTimeSource := &MockTimeSource{}
-	svc := token.NewTokenService(mockTimeSource, mockJWTHandler)
+func TestCreateTokenPair_ScopeNotGranted(t *testing.T) {
+	timeSource := &MockTimeSource{}
+	jwtHandler := new(MockJWTHandler)
+	grant := &token.StaticGrant{Scopes: []string{"profile"}}
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, grant)
 
-	// Mock Generate to return success for the first call and error for the second call
-	mockJWTHandler.On("Generate", mock.Anything).Return("mockToken", nil).Once()
-	mockJWTHandler.On("Generate", mock.Anything).Return("", assert.AnError).Once()
+	tokenPair, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: testSession(), Scopes: []string{"admin"}}, policies.Default)
 
-	_, err := svc.CreateTokenPair(context.TODO(), "test-user")
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.BadRequest, serviceErr.Code)
+	assert.Nil(t, tokenPair)
+	jwtHandler.AssertNotCalled(t, "Generate", mock.Anything)
+}
 
+func TestCreateTokenPair_CarriesPreviousClaims(t *testing.T) {
+	timeSource := &MockTimeSource{}
+	jwtHandler := new(MockJWTHandler)
+	// The grant would widen the scope again if it ran on refresh
+	grant := &token.StaticGrant{Scopes: []string{"profile", "sessions"}, Roles: []string{"user"}}
+	svc := token.NewTokenService(timeSource, jwtHandler, policies, grant)
+
+	previous := &public_model.CustomClaims{Scope: "profile", Roles: []string{"user"}}
+	jwtHandler.On("Generate", mock.MatchedBy(func(claims public_model.CustomClaims) bool {
+		return claims.Scope == "profile" && assert.ObjectsAreEqual([]string{"user"}, claims.Roles)
+	})).Return("token", nil).Twice()
+
+	_, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: testSession(), Previous: previous}, policies.Default)
+	assert.NoError(t, err)
+
+	_, err = svc.CreateTokenPair(context.TODO(), &token.Subject{Session: testSession(), Previous: previous, Scopes: []string{"sessions"}}, policies.Default)
 	assert.Error(t, err)
-	mockJWTHandler.AssertExpectations(t)
+	jwtHandler.AssertExpectations(t)
 }
 
-func TestRefreshToken_CreateTokenPairError(t *testing.T) {
-	mockJWTHandler := new(MockJWTHandler)
-	mockTimeSource := &MockTimeSource{}
-	svc := token.NewTokenService(mockTimeSource, mockJWTHandler)
+func TestCreateTokenPair_CarriesExtraClaims(t *testing.T) {
+	jwtHandler := internal_jwt.NewSimpleJWTHandler([]byte("secret"))
+	svc := token.NewTokenService(&MockTimeSource{}, jwtHandler, policies, token.NewEnricherChain(
+		token.EnricherStep{Name: "plan", Enricher: setExtra("plan", "pro")},
+	))
 
-	// Mock Parse to return a valid token
-	mockToken := &jwt.Token{Valid: true}
-	mockJWTHandler.On("Parse", mock.Anything, mock.Anything).Return(mockToken, nil).Once()
+	tokenPair, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: testSession()}, policies.Default)
+	assert.NoError(t, err)
 
-	// Mock Generate to return an error for CreateTokenPair calls
-	mockJWTHandler.On("Generate", mock.Anything).Return("", assert.AnError).Once()
+	previous, err := svc.ParseToken(context.TODO(), tokenPair.RefreshToken)
+	assert.NoError(t, err)
+	refreshed, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: testSession(), Previous: previous}, policies.Default)
+	assert.NoError(t, err)
 
-	_, err := svc.RefreshToken(context.TODO(), "someValidRefreshToken")
+	claims, err := svc.ParseToken(context.TODO(), refreshed.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "pro", claims.Extra["plan"])
+}
 
+// newTenantJWTHandler returns a handler that knows the acme tenant, whose key differs from the default one.
+func newTenantJWTHandler() *internal_jwt.TenantJWTHandler {
+	secrets := secret.StaticProvider{
+		"secret/data/jwt_secret":                []byte("secret"),
+		"secret/data/tenants/acme/jwt_secret":   []byte("acme-secret"),
+		"secret/data/tenants/globex/jwt_secret": []byte("globex-secret"),
+	}
+	return internal_jwt.NewTenantJWTHandler(secrets, "secret/data/jwt_secret", map[string]string{
+		"acme": "secret/data/tenants/acme/jwt_secret",
+	})
+}
+
+func TestCreateTokenPair_Tenant(t *testing.T) {
+	jwtHandler := newTenantJWTHandler()
+	svc := token.NewTokenService(&MockTimeSource{}, jwtHandler, policies, nil)
+
+	tokenPair, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: testSession(), Tenant: "acme"}, policies.Default)
+	assert.NoError(t, err)
+
+	claims, err := svc.ParseToken(context.TODO(), tokenPair.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", claims.Tenant)
+
+	// The token is signed with the tenant's key, not the default one
+	_, err = internal_jwt.NewSimpleJWTHandler([]byte("secret")).Parse(tokenPair.AccessToken, &public_model.CustomClaims{})
 	assert.Error(t, err)
-	mockJWTHandler.AssertExpectations(t)
+
+	// Relabelling a token of another tenant breaks its signature
+	globex, _ := internal_jwt.NewSimpleJWTHandler([]byte("globex-secret")).Generate(public_model.CustomClaims{UserID: "test-user", Tenant: "acme"})
+	_, err = svc.ParseToken(context.TODO(), globex)
+	assert.Error(t, err)
+}
+
+func TestCreateTokenPair_UnknownTenant(t *testing.T) {
+	jwtHandler := newTenantJWTHandler()
+	svc := token.NewTokenService(&MockTimeSource{}, jwtHandler, policies, nil)
+
+	tokenPair, err := svc.CreateTokenPair(context.TODO(), &token.Subject{Session: testSession(), Tenant: "globex"}, policies.Default)
+
+	serviceErr, ok := err.(*common_error.ServiceError)
+	assert.True(t, ok)
+	assert.Equal(t, common_error.BadRequest, serviceErr.Code)
+	assert.Nil(t, tokenPair)
+}
+
+func TestCreateAPIKeyToken(t *testing.T) {
+	jwtHandler := newTenantJWTHandler()
+	svc := token.NewTokenService(&MockTimeSource{}, jwtHandler, policies, nil)
+
+	accessToken, err := svc.CreateAPIKeyToken(context.TODO(), &token.APIKeyGrant{
+		KeyID:  "key",
+		UserID: "test-user",
+		Tenant: "acme",
+		Scopes: []string{"sessions", "profile"},
+		Roles:  []string{"user"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(policies.APIKeyTokenTTL/time.Second), accessToken.ExpiresIn)
+
+	claims, err := svc.ParseToken(context.TODO(), accessToken.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "key", claims.APIKeyID)
+	assert.Equal(t, "acme", claims.Tenant)
+	assert.Equal(t, "profile sessions", claims.Scope)
+	assert.Equal(t, token.AccessTokenType, claims.TokenType)
+	assert.Empty(t, claims.SessionID)
+	assert.LessOrEqual(t, claims.ExpiresAt, time.Now().Add(policies.APIKeyTokenTTL).Unix())
+}
+
+func TestCreateImpersonationToken(t *testing.T) {
+	jwtHandler := internal_jwt.NewSimpleJWTHandler([]byte("secret"))
+	grant := &token.StaticGrant{Scopes: []string{"profile"}, Roles: []string{"user"}}
+	svc := token.NewTokenService(&MockTimeSource{}, jwtHandler, policies, grant)
+
+	accessToken, err := svc.CreateImpersonationToken(context.TODO(), &token.Subject{User: &pb.UserResponse{Id: "test-user"}}, &public_model.CustomClaims{UserID: "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(policies.ImpersonateTTL/time.Second), accessToken.ExpiresIn)
+
+	claims, err := svc.ParseToken(context.TODO(), accessToken.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-user", claims.UserID)
+	assert.Equal(t, &public_model.Actor{UserID: "admin"}, claims.Actor)
+	assert.Equal(t, "profile", claims.Scope)
+	assert.Equal(t, []string{"user"}, claims.Roles)
+	assert.Empty(t, claims.SessionID)
 }
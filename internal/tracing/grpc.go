@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataCarrier adapts gRPC metadata for the propagator.
+type MetadataCarrier metadata.MD
+
+// Get implements propagation.TextMapCarrier.
+func (m MetadataCarrier) Get(key string) string {
+	values := metadata.MD(m).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Set implements propagation.TextMapCarrier.
+func (m MetadataCarrier) Set(key string, value string) {
+	metadata.MD(m).Set(key, value)
+}
+
+// Keys implements propagation.TextMapCarrier.
+func (m MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Extract returns ctx with the trace context the caller sent in the incoming metadata.
+func Extract(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return otel.GetTextMapPropagator().Extract(ctx, MetadataCarrier(md))
+}
+
+// Inject returns ctx with the trace context of its span added to the outgoing metadata.
+func Inject(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, MetadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// RPCAttributes describes a call to the full gRPC method, such as "/AuthService/Login".
+func RPCAttributes(fullMethod string) []attribute.KeyValue {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return []attribute.KeyValue{semconv.RPCSystemGRPC, semconv.RPCService(service), semconv.RPCMethod(method)}
+}
+
+// SpanName names the span of a call to the full gRPC method the way OpenTelemetry does, as "AuthService/Login".
+func SpanName(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+// EndRPC records the outcome of a gRPC call on its span and ends it.
+func EndRPC(span trace.Span, err error) {
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(status.Code(err))))
+	Fail(span, err)
+	span.End()
+}
+
+// UserServiceInterceptor records a client span for every call to the user service and propagates the
+// trace to it. It is installed on the client connection to the user service.
+func UserServiceInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := Start(ctx, SpanName(method), trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(RPCAttributes(method)...))
+		err := invoker(Inject(ctx), method, req, reply, cc, opts...)
+		EndRPC(span, err)
+		return err
+	}
+}
@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName names the tracer the spans of the service are recorded with.
+const TracerName = "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go"
+
+// ShutdownTimeout bounds how long Close waits for pending spans to be exported.
+const ShutdownTimeout = 5 * time.Second
+
+// Tracer returns the tracer of the service. It records with the global tracer provider, so spans are
+// dropped until Install is called.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Start starts a span as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// Fail marks the span as failed with err. It does nothing when err is nil.
+func Fail(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Propagator reads and writes W3C trace context and baggage, the headers and metadata keys callers
+// propagate traces with.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// Provider records the spans of the service and hands them to an exporter in batches.
+type Provider struct {
+	*sdktrace.TracerProvider
+	Closers []io.Closer // Closed once the spans are flushed, such as the file the exporter writes to
+}
+
+// NewProvider creates a provider exporting the spans of serviceName with exporter. A sampleRatio of 1
+// records every trace that callers did not decide about, and sampled callers are always followed.
+func NewProvider(exporter sdktrace.SpanExporter, serviceName string, sampleRatio float64, closers ...io.Closer) *Provider {
+	return &Provider{
+		TracerProvider: sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+			sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+		),
+		Closers: closers,
+	}
+}
+
+// Install makes the provider the global tracer provider that Tracer records with.
+func (p *Provider) Install() {
+	otel.SetTracerProvider(p.TracerProvider)
+}
+
+// Close exports the pending spans within ShutdownTimeout and stops the exporter.
+func (p *Provider) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	errs := []error{p.Shutdown(ctx)}
+	for _, closer := range p.Closers {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Ensure Provider can be closed with the other resources of the application.
+var _ io.Closer = (*Provider)(nil)
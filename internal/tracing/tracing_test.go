@@ -0,0 +1,126 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// recordSpans installs a tracer provider recording every span until the test ends.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(tracing.Propagator())
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestUserServiceInterceptor(t *testing.T) {
+	recorder := recordSpans(t)
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token")
+
+	var sent metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return status.Error(grpc_codes.Unavailable, "down")
+	}
+	err := tracing.UserServiceInterceptor()(ctx, "/UserService/CreateUser", nil, nil, nil, invoker)
+	parent.End()
+
+	assert.Equal(t, grpc_codes.Unavailable, status.Code(err))
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "UserService/CreateUser", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Contains(t, span.Attributes(), tracing.RPCAttributes("/UserService/CreateUser")[1])
+
+	// The trace continues in the user service, next to the metadata the caller set
+	assert.Equal(t, []string{"Bearer token"}, sent.Get("authorization"))
+	assert.Len(t, sent.Get("traceparent"), 1)
+	assert.Contains(t, sent.Get("traceparent")[0], span.SpanContext().SpanID().String())
+}
+
+func TestExtract(t *testing.T) {
+	recordSpans(t)
+	ctx, span := tracing.Start(context.Background(), "caller")
+	outgoing, _ := metadata.FromOutgoingContext(tracing.Inject(ctx))
+	span.End()
+
+	extracted := tracing.Extract(metadata.NewIncomingContext(context.Background(), outgoing))
+
+	remote := trace.SpanContextFromContext(extracted)
+	assert.True(t, remote.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), remote.TraceID())
+
+	// Calls without trace context start a trace of their own
+	assert.False(t, trace.SpanContextFromContext(tracing.Extract(context.Background())).IsValid())
+}
+
+func TestFail(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, span := tracing.Start(context.Background(), "ok")
+	tracing.Fail(span, nil)
+	span.End()
+	_, span = tracing.Start(context.Background(), "failed")
+	tracing.Fail(span, errors.New("boom"))
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "boom", spans[1].Status().Description)
+	assert.Len(t, spans[1].Events(), 1)
+}
+
+// keptExporter keeps the exported spans after shutdown, which the in-memory exporter clears.
+type keptExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keptExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestProvider(t *testing.T) {
+	exporter := keptExporter{tracetest.NewInMemoryExporter()}
+	provider := tracing.NewProvider(exporter, "auth-service", 1)
+
+	_, span := provider.Tracer("test").Start(context.Background(), "span")
+	span.End()
+
+	// Spans are exported in batches, so they only show once the provider is closed
+	assert.NoError(t, provider.Close())
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	serviceName, _ := spans[0].Resource.Set().Value("service.name")
+	assert.Equal(t, "auth-service", serviceName.AsString())
+}
+
+func TestProvider_SampleRatio(t *testing.T) {
+	exporter := keptExporter{tracetest.NewInMemoryExporter()}
+	provider := tracing.NewProvider(exporter, "auth-service", 0)
+
+	_, span := provider.Tracer("test").Start(context.Background(), "span")
+	span.End()
+
+	assert.NoError(t, provider.Close())
+	assert.Empty(t, exporter.GetSpans())
+}
@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"strings"
+
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeEmail trims and case-folds an email address.
+func NormalizeEmail(email string) string {
+	return cases.Fold().String(strings.TrimSpace(email))
+}
+
+// NormalizeUsername trims, applies Unicode NFKC and case-folds a username.
+func NormalizeUsername(username string) string {
+	return cases.Fold().String(norm.NFKC.String(strings.TrimSpace(username)))
+}
+
+// NormalizeIdentifier normalizes a login identifier as an email when it contains an '@',
+// and as a username otherwise.
+func NormalizeIdentifier(identifier string) string {
+	if strings.Contains(identifier, "@") {
+		return NormalizeEmail(identifier)
+	}
+	return NormalizeUsername(identifier)
+}
+
+// NormalizeLogin resolves the login identifier into Identifier and normalizes it.
+func NormalizeLogin(loginModel *public_model.LoginModel) {
+	loginModel.Identifier = NormalizeIdentifier(loginModel.LoginIdentifier())
+	loginModel.Email = ""
+}
+
+// NormalizeImpersonate normalizes the identifier of the user to impersonate as a login identifier, so
+// that it names the same user a login with it would.
+func NormalizeImpersonate(impersonateModel *public_model.ImpersonateModel) {
+	impersonateModel.Identifier = NormalizeIdentifier(impersonateModel.Identifier)
+}
+
+// NormalizeRegister normalizes the email and username of a registration.
+func NormalizeRegister(registerModel *public_model.RegisterModel) {
+	registerModel.Email = NormalizeEmail(registerModel.Email)
+	registerModel.Username = NormalizeUsername(registerModel.Username)
+}
@@ -0,0 +1,65 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/validation"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeIdentifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier string
+		expected   string
+	}{
+		{"email", "  Test@Test.COM ", "test@test.com"},
+		{"username", " Test_User\t", "test_user"},
+		{"fullwidth username", "ＴＥＳＴ", "test"},
+		{"compatibility ligature", "ﬁne", "fine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, validation.NormalizeIdentifier(tt.identifier))
+		})
+	}
+}
+
+func TestNormalizeLogin_DeprecatedEmail(t *testing.T) {
+	loginModel := &public_model.LoginModel{Email: " Test@Test.com", Password: "password"}
+
+	validation.NormalizeLogin(loginModel)
+
+	assert.Equal(t, "test@test.com", loginModel.Identifier)
+	assert.Equal(t, "", loginModel.Email)
+	assert.Equal(t, "password", loginModel.Password)
+}
+
+func TestNormalizeLogin_IdentifierTakesPrecedence(t *testing.T) {
+	loginModel := &public_model.LoginModel{Identifier: "Test", Email: "test@test.com", Password: "password"}
+
+	validation.NormalizeLogin(loginModel)
+
+	assert.Equal(t, "test", loginModel.Identifier)
+}
+
+func TestNormalizeImpersonate(t *testing.T) {
+	impersonateModel := &public_model.ImpersonateModel{Identifier: "Alice@Example.com ", Reason: " support ticket "}
+
+	validation.NormalizeImpersonate(impersonateModel)
+
+	assert.Equal(t, "alice@example.com", impersonateModel.Identifier)
+	assert.Equal(t, " support ticket ", impersonateModel.Reason)
+}
+
+func TestNormalizeRegister(t *testing.T) {
+	registerModel := &public_model.RegisterModel{Email: " Test@Test.com ", Username: " Test ", Password: " password "}
+
+	validation.NormalizeRegister(registerModel)
+
+	assert.Equal(t, "test@test.com", registerModel.Email)
+	assert.Equal(t, "test", registerModel.Username)
+	assert.Equal(t, " password ", registerModel.Password)
+}
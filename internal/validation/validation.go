@@ -0,0 +1,249 @@
+package validation
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/audit"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/scope"
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/tenant"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+)
+
+// Size limits applied to incoming request fields.
+const (
+	MaxEmailLength    = 254 // RFC 5321 limit for a forward path
+	MinUsernameLength = 3
+	MaxUsernameLength = 32
+	MinPasswordLength = 8
+	MaxPasswordLength = 72 // bcrypt ignores anything past 72 bytes
+	MaxScopeLength    = 1024
+	MaxTenantLength   = tenant.MaxIDLength
+	MaxKeyNameLength  = 64
+	MaxAPIKeyLength   = 128
+	MaxReasonLength   = 500
+	MaxAuditLimit     = audit.MaxQueryLimit
+
+	// MaxRequestBodySize is the largest request body or message accepted by the servers.
+	MaxRequestBodySize = 4 * 1024
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// FieldViolation describes a single invalid field in a request.
+type FieldViolation struct {
+	Field       string `json:"name"`
+	Description string `json:"reason"`
+}
+
+// Error is returned when a request fails validation. It carries every violation found.
+type Error struct {
+	Violations []FieldViolation
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Field+": "+v.Description)
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// violations collects field violations and turns them into an *Error when non-empty.
+type violations []FieldViolation
+
+func (v *violations) add(field, description string) {
+	*v = append(*v, FieldViolation{Field: field, Description: description})
+}
+
+func (v violations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return &Error{Violations: v}
+}
+
+// ValidateLogin checks a LoginModel before it reaches the auth service. The identifier
+// is checked as an email when it contains an '@', and as a username otherwise.
+func ValidateLogin(loginModel *public_model.LoginModel) error {
+	var v violations
+	identifier := loginModel.LoginIdentifier()
+	if strings.Contains(identifier, "@") {
+		validateEmail(&v, "identifier", identifier)
+	} else {
+		validateUsername(&v, "identifier", identifier)
+	}
+	if loginModel.Password == "" {
+		v.add("password", "must not be empty")
+	} else if len(loginModel.Password) > MaxPasswordLength {
+		v.add("password", "must be at most 72 bytes")
+	}
+	validateScope(&v, "scope", loginModel.Scope)
+	validateTenant(&v, "tenant_id", loginModel.TenantID)
+	return v.err()
+}
+
+// ValidateRegister checks a RegisterModel before it reaches the auth service.
+func ValidateRegister(registerModel *public_model.RegisterModel) error {
+	var v violations
+	validateEmail(&v, "email", registerModel.Email)
+	validateUsername(&v, "username", registerModel.Username)
+	validatePassword(&v, "password", registerModel.Password)
+	validateScope(&v, "scope", registerModel.Scope)
+	validateTenant(&v, "tenant_id", registerModel.TenantID)
+	return v.err()
+}
+
+// ValidateRefresh checks a TokenRefreshModel before it reaches the auth service.
+func ValidateRefresh(refreshModel *public_model.TokenRefreshModel) error {
+	var v violations
+	if refreshModel.Token == "" {
+		v.add("token", "must not be empty")
+	}
+	validateScope(&v, "scope", refreshModel.Scope)
+	validateTenant(&v, "tenant_id", refreshModel.TenantID)
+	return v.err()
+}
+
+// ValidateCreateAPIKey checks a CreateAPIKeyModel before it reaches the API key service.
+func ValidateCreateAPIKey(createModel *public_model.CreateAPIKeyModel) error {
+	var v violations
+	switch name := strings.TrimSpace(createModel.Name); {
+	case name == "":
+		v.add("name", "must not be empty")
+	case utf8.RuneCountInString(name) > MaxKeyNameLength:
+		v.add("name", "must be at most 64 characters")
+	}
+	validateScope(&v, "scope", createModel.Scope)
+	if createModel.ExpiresInDays < 0 {
+		v.add("expires_in_days", "must not be negative")
+	}
+	return v.err()
+}
+
+// ValidateAPIKeyExchange checks an APIKeyExchangeModel before it reaches the API key service.
+func ValidateAPIKeyExchange(exchangeModel *public_model.APIKeyExchangeModel) error {
+	var v violations
+	switch {
+	case exchangeModel.Key == "":
+		v.add("api_key", "must not be empty")
+	case len(exchangeModel.Key) > MaxAPIKeyLength:
+		v.add("api_key", "must be at most 128 characters")
+	}
+	return v.err()
+}
+
+// ValidateImpersonate checks an ImpersonateModel before it reaches the auth service.
+func ValidateImpersonate(impersonateModel *public_model.ImpersonateModel) error {
+	var v violations
+	if strings.Contains(impersonateModel.Identifier, "@") {
+		validateEmail(&v, "identifier", impersonateModel.Identifier)
+	} else {
+		validateUsername(&v, "identifier", impersonateModel.Identifier)
+	}
+	switch reason := strings.TrimSpace(impersonateModel.Reason); {
+	case reason == "":
+		v.add("reason", "must not be empty")
+	case utf8.RuneCountInString(reason) > MaxReasonLength:
+		v.add("reason", "must be at most 500 characters")
+	}
+	return v.err()
+}
+
+// ValidateAuditQuery checks an AuditQueryModel before it reaches the audit service.
+func ValidateAuditQuery(queryModel *public_model.AuditQueryModel) error {
+	var v violations
+	if queryModel.Type != "" && !audit.EventType(queryModel.Type).Valid() {
+		v.add("type", "must be a known event type")
+	}
+	if queryModel.Outcome != "" && !audit.Outcome(queryModel.Outcome).Valid() {
+		v.add("outcome", "must be success or failure")
+	}
+	since := validateTime(&v, "since", queryModel.Since)
+	until := validateTime(&v, "until", queryModel.Until)
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		v.add("until", "must be after since")
+	}
+	if queryModel.Limit < 0 || queryModel.Limit > MaxAuditLimit {
+		v.add("limit", "must be between 0 and 500")
+	}
+	return v.err()
+}
+
+func validateEmail(v *violations, field, email string) {
+	switch {
+	case email == "":
+		v.add(field, "must not be empty")
+	case len(email) > MaxEmailLength:
+		v.add(field, "must be at most 254 characters")
+	default:
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email {
+			v.add(field, "must be a valid email address")
+		}
+	}
+}
+
+func validateUsername(v *violations, field, username string) {
+	length := utf8.RuneCountInString(username)
+	switch {
+	case username == "":
+		v.add(field, "must not be empty")
+	case length < MinUsernameLength || length > MaxUsernameLength:
+		v.add(field, "must be between 3 and 32 characters")
+	case !usernamePattern.MatchString(username):
+		v.add(field, "may only contain letters, digits, '.', '_' and '-'")
+	}
+}
+
+func validatePassword(v *violations, field, password string) {
+	switch {
+	case password == "":
+		v.add(field, "must not be empty")
+	case len(password) < MinPasswordLength:
+		v.add(field, "must be at least 8 characters")
+	case len(password) > MaxPasswordLength:
+		v.add(field, "must be at most 72 bytes")
+	}
+}
+
+func validateScope(v *violations, field, s string) {
+	switch {
+	case len(s) > MaxScopeLength:
+		v.add(field, "must be at most 1024 characters")
+	case !scope.Valid(s):
+		v.add(field, "must be space-separated scope tokens")
+	}
+}
+
+// validateTenant checks an optional tenant ID, which is a DNS label so that it can double as a subdomain.
+func validateTenant(v *violations, field, tenantID string) {
+	switch {
+	case tenantID == "":
+	case len(tenantID) > MaxTenantLength:
+		v.add(field, "must be at most 63 characters")
+	case !tenant.ValidID(tenantID):
+		v.add(field, "may only contain lower-case letters, digits and inner '-'")
+	}
+}
+
+// ValidTenantID reports whether tenantID names a tenant that requests can select.
+func ValidTenantID(tenantID string) bool {
+	return tenant.ValidID(tenantID)
+}
+
+// validateTime checks an optional RFC 3339 time and returns it, or the zero time when absent or invalid.
+func validateTime(v *violations, field, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		v.add(field, "must be an RFC 3339 time")
+	}
+	return t
+}
@@ -0,0 +1,166 @@
+package validation_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/internal/validation"
+	public_model "github.com/Bit-Bridge-Source/BitBridge-AuthService-Go/public/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLogin_Success(t *testing.T) {
+	err := validation.ValidateLogin(&public_model.LoginModel{Email: "test@test.com", Password: "password"})
+
+	assert.NoError(t, err)
+}
+
+func TestValidateLogin_Empty(t *testing.T) {
+	err := validation.ValidateLogin(&public_model.LoginModel{})
+
+	var validationErr *validation.Error
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []validation.FieldViolation{
+		{Field: "identifier", Description: "must not be empty"},
+		{Field: "password", Description: "must not be empty"},
+	}, validationErr.Violations)
+}
+
+func TestValidateLogin_Username(t *testing.T) {
+	err := validation.ValidateLogin(&public_model.LoginModel{Identifier: "test_user", Password: "password"})
+
+	assert.NoError(t, err)
+}
+
+func TestValidateLogin_InvalidIdentifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier string
+		violation  string
+	}{
+		{"malformed email", "test@", "must be a valid email address"},
+		{"username charset", "te st", "may only contain letters, digits, '.', '_' and '-'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validation.ValidateLogin(&public_model.LoginModel{Identifier: tt.identifier, Password: "password"})
+
+			var validationErr *validation.Error
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, []validation.FieldViolation{{Field: "identifier", Description: tt.violation}}, validationErr.Violations)
+		})
+	}
+}
+
+func TestValidateRegister_Success(t *testing.T) {
+	err := validation.ValidateRegister(&public_model.RegisterModel{Email: "test@test.com", Username: "test_user", Password: "password"})
+
+	assert.NoError(t, err)
+}
+
+func TestValidateRegister_InvalidFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     public_model.RegisterModel
+		field     string
+		violation string
+	}{
+		{"malformed email", public_model.RegisterModel{Email: "test", Username: "test", Password: "password"}, "email", "must be a valid email address"},
+		{"display name email", public_model.RegisterModel{Email: "Test <test@test.com>", Username: "test", Password: "password"}, "email", "must be a valid email address"},
+		{"long email", public_model.RegisterModel{Email: strings.Repeat("a", 250) + "@t.co", Username: "test", Password: "password"}, "email", "must be at most 254 characters"},
+		{"short username", public_model.RegisterModel{Email: "test@test.com", Username: "ab", Password: "password"}, "username", "must be between 3 and 32 characters"},
+		{"long username", public_model.RegisterModel{Email: "test@test.com", Username: strings.Repeat("a", 33), Password: "password"}, "username", "must be between 3 and 32 characters"},
+		{"username charset", public_model.RegisterModel{Email: "test@test.com", Username: "te st", Password: "password"}, "username", "may only contain letters, digits, '.', '_' and '-'"},
+		{"short password", public_model.RegisterModel{Email: "test@test.com", Username: "test", Password: "pass"}, "password", "must be at least 8 characters"},
+		{"long password", public_model.RegisterModel{Email: "test@test.com", Username: "test", Password: strings.Repeat("a", 73)}, "password", "must be at most 72 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validation.ValidateRegister(&tt.model)
+
+			var validationErr *validation.Error
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, []validation.FieldViolation{{Field: tt.field, Description: tt.violation}}, validationErr.Violations)
+		})
+	}
+}
+
+func TestError_Message(t *testing.T) {
+	err := &validation.Error{Violations: []validation.FieldViolation{
+		{Field: "email", Description: "must not be empty"},
+		{Field: "password", Description: "must not be empty"},
+	}}
+
+	assert.Equal(t, "validation failed: email: must not be empty; password: must not be empty", err.Error())
+}
+
+func TestValidateScope(t *testing.T) {
+	err := validation.ValidateRefresh(&public_model.TokenRefreshModel{Token: "token", Scope: "profile \"admin\""})
+
+	var validationErr *validation.Error
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "scope", validationErr.Violations[0].Field)
+
+	assert.NoError(t, validation.ValidateRefresh(&public_model.TokenRefreshModel{Token: "token", Scope: "profile sessions"}))
+}
+
+func TestValidateTenant(t *testing.T) {
+	for _, tenantID := range []string{"Acme", "-acme", "acme-", "acme_corp", strings.Repeat("a", 64)} {
+		err := validation.ValidateRefresh(&public_model.TokenRefreshModel{Token: "token", TenantID: tenantID})
+
+		var validationErr *validation.Error
+		assert.True(t, errors.As(err, &validationErr), tenantID)
+		assert.Equal(t, "tenant_id", validationErr.Violations[0].Field)
+	}
+
+	assert.NoError(t, validation.ValidateRefresh(&public_model.TokenRefreshModel{Token: "token", TenantID: "acme-corp"}))
+	assert.NoError(t, validation.ValidateRefresh(&public_model.TokenRefreshModel{Token: "token"}))
+}
+
+func TestValidateCreateAPIKey(t *testing.T) {
+	err := validation.ValidateCreateAPIKey(&public_model.CreateAPIKeyModel{Name: " ", Scope: "\"", ExpiresInDays: -1})
+
+	var validationErr *validation.Error
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []string{"name", "scope", "expires_in_days"}, []string{
+		validationErr.Violations[0].Field, validationErr.Violations[1].Field, validationErr.Violations[2].Field,
+	})
+
+	assert.Error(t, validation.ValidateCreateAPIKey(&public_model.CreateAPIKeyModel{Name: strings.Repeat("a", 65)}))
+	assert.NoError(t, validation.ValidateCreateAPIKey(&public_model.CreateAPIKeyModel{Name: "ci", Scope: "profile", ExpiresInDays: 30}))
+}
+
+func TestValidateAPIKeyExchange(t *testing.T) {
+	assert.Error(t, validation.ValidateAPIKeyExchange(&public_model.APIKeyExchangeModel{}))
+	assert.Error(t, validation.ValidateAPIKeyExchange(&public_model.APIKeyExchangeModel{Key: strings.Repeat("a", 129)}))
+	assert.NoError(t, validation.ValidateAPIKeyExchange(&public_model.APIKeyExchangeModel{Key: "bbk_id_secret"}))
+}
+
+func TestValidateImpersonate(t *testing.T) {
+	assert.Error(t, validation.ValidateImpersonate(&public_model.ImpersonateModel{Reason: "support ticket"}))
+	assert.Error(t, validation.ValidateImpersonate(&public_model.ImpersonateModel{Identifier: "target"}))
+	assert.Error(t, validation.ValidateImpersonate(&public_model.ImpersonateModel{Identifier: "target", Reason: strings.Repeat("a", 501)}))
+	assert.NoError(t, validation.ValidateImpersonate(&public_model.ImpersonateModel{Identifier: "target", Reason: "support ticket"}))
+	assert.NoError(t, validation.ValidateImpersonate(&public_model.ImpersonateModel{Identifier: "target@example.com", Reason: "support ticket"}))
+}
+
+func TestValidateAuditQuery(t *testing.T) {
+	assert.NoError(t, validation.ValidateAuditQuery(&public_model.AuditQueryModel{}))
+	assert.NoError(t, validation.ValidateAuditQuery(&public_model.AuditQueryModel{
+		Type:    "login_failure",
+		Outcome: "failure",
+		Since:   "2023-11-01T00:00:00Z",
+		Until:   "2023-11-02T00:00:00Z",
+		Limit:   500,
+	}))
+
+	err := validation.ValidateAuditQuery(&public_model.AuditQueryModel{Type: "unknown", Outcome: "maybe", Since: "yesterday", Limit: 501})
+	validationError, ok := err.(*validation.Error)
+	assert.True(t, ok)
+	assert.Len(t, validationError.Violations, 4)
+
+	assert.Error(t, validation.ValidateAuditQuery(&public_model.AuditQueryModel{Since: "2023-11-02T00:00:00Z", Until: "2023-11-01T00:00:00Z"}))
+}
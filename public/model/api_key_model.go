@@ -0,0 +1,35 @@
+package public_model
+
+import "time"
+
+type CreateAPIKeyModel struct {
+	Name          string `json:"name"`
+	Scope         string `json:"scope,omitempty"`           // Space-delimited subset of the caller's scopes, empty for all
+	ExpiresInDays int    `json:"expires_in_days,omitempty"` // Lifetime of the key, zero for the default
+}
+
+type APIKeyModel struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scope      string     `json:"scope"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// CreatedAPIKeyModel is returned once when a key is issued. Key cannot be recovered afterwards.
+type CreatedAPIKeyModel struct {
+	APIKeyModel
+	Key string `json:"key"`
+}
+
+type APIKeyExchangeModel struct {
+	Key string `json:"api_key"`
+}
+
+type AccessTokenModel struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"` // Seconds until the access token expires
+}
@@ -0,0 +1,28 @@
+package public_model
+
+import "time"
+
+type AuditEventModel struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	Time      time.Time         `json:"time"`
+	Outcome   string            `json:"outcome"`
+	ActorID   string            `json:"actor_id,omitempty"`
+	SubjectID string            `json:"subject_id,omitempty"`
+	Tenant    string            `json:"tenant,omitempty"`
+	IP        string            `json:"ip,omitempty"`
+	UserAgent string            `json:"user_agent,omitempty"`
+	Reason    string            `json:"reason,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// AuditQueryModel selects audit events. Empty fields match every event.
+type AuditQueryModel struct {
+	Type      string `query:"type"`
+	ActorID   string `query:"actor_id"`
+	SubjectID string `query:"subject_id"`
+	Outcome   string `query:"outcome"`
+	Since     string `query:"since"` // RFC 3339 time of the oldest event returned
+	Until     string `query:"until"` // RFC 3339 time events must precede
+	Limit     int    `query:"limit"` // Most events returned, zero for the default
+}
@@ -0,0 +1,6 @@
+package public_model
+
+type ImpersonateModel struct {
+	Identifier string `json:"identifier"` // Email address or username of the user to act as
+	Reason     string `json:"reason"`     // Why the user is impersonated, recorded in the audit log
+}
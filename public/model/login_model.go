@@ -1,6 +1,19 @@
 package public_model
 
 type LoginModel struct {
+	Identifier string `json:"identifier"`
+	// Deprecated: use Identifier. Kept for clients that still send an email.
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	ClientID string `json:"client_id,omitempty"` // Selects the client's token policy, empty for the default
+	Scope    string `json:"scope,omitempty"`     // Space-delimited subset of the granted scopes, empty for all
+	TenantID string `json:"tenant_id,omitempty"` // Tenant of the account, empty for the tenant derived from the request
+}
+
+// LoginIdentifier returns the identifier the user logs in with, falling back to the deprecated Email field.
+func (loginModel *LoginModel) LoginIdentifier() string {
+	if loginModel.Identifier != "" {
+		return loginModel.Identifier
+	}
+	return loginModel.Email
 }
@@ -6,6 +6,9 @@ type RegisterModel struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	Password string `json:"password"`
+	ClientID string `json:"client_id,omitempty"` // Selects the client's token policy, empty for the default
+	Scope    string `json:"scope,omitempty"`     // Space-delimited subset of the granted scopes, empty for all
+	TenantID string `json:"tenant_id,omitempty"` // Tenant of the account, empty for the tenant derived from the request
 }
 
 func (registerModel *RegisterModel) ToCreatedUserRequest() *pb.RegisterRequest {
@@ -13,5 +16,8 @@ func (registerModel *RegisterModel) ToCreatedUserRequest() *pb.RegisterRequest {
 		Email:    registerModel.Email,
 		Username: registerModel.Username,
 		Password: registerModel.Password,
+		ClientId: registerModel.ClientID,
+		Scope:    registerModel.Scope,
+		TenantId: registerModel.TenantID,
 	}
 }
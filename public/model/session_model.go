@@ -0,0 +1,12 @@
+package public_model
+
+import "time"
+
+type SessionModel struct {
+	ID              string    `json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	LastRefreshedAt time.Time `json:"last_refreshed_at"`
+	IP              string    `json:"ip"`
+	UserAgent       string    `json:"user_agent"`
+	Current         bool      `json:"current"`
+}
@@ -1,6 +1,10 @@
 package public_model
 
-import "github.com/golang-jwt/jwt"
+import (
+	"encoding/json"
+
+	"github.com/golang-jwt/jwt"
+)
 
 type TokenModel struct {
 	AccessToken  string `json:"access_token"`
@@ -8,10 +12,95 @@ type TokenModel struct {
 }
 
 type TokenRefreshModel struct {
-	Token string `json:"token"`
+	Token    string `json:"token"`
+	Scope    string `json:"scope,omitempty"`     // Space-delimited subset of the granted scopes
+	TenantID string `json:"tenant_id,omitempty"` // Tenant the request is made for, empty for the tenant derived from the request
 }
 
 type CustomClaims struct {
-	UserID string `json:"user_id"`
+	UserID    string   `json:"user_id"`
+	SessionID string   `json:"sid,omitempty"`
+	AuthTime  int64    `json:"auth_time,omitempty"` // Unix time the session started
+	TokenType string   `json:"typ,omitempty"`
+	Scope     string   `json:"scope,omitempty"` // Space-delimited granted scopes
+	Roles     []string `json:"roles,omitempty"`
+	Tenant    string   `json:"tenant,omitempty"` // Tenant the session belongs to, empty for the default tenant
+	APIKeyID  string   `json:"key_id,omitempty"` // API key the token was issued for, empty for session tokens
+	Actor     *Actor   `json:"act,omitempty"`    // Admin acting as the user, set on impersonation tokens
 	jwt.StandardClaims
+
+	// Extra holds additional top-level claims added by claims enrichers, such as plan.
+	Extra map[string]interface{} `json:"-"`
+}
+
+// Actor names the party acting on behalf of the token's user, as in the RFC 8693 act claim.
+type Actor struct {
+	UserID string `json:"sub"`
+}
+
+// TenantID returns the tenant the claims were issued for. Signing key selection relies on it.
+func (c CustomClaims) TenantID() string {
+	return c.Tenant
+}
+
+// registeredClaims are the claim names backed by CustomClaims fields. Extra claims cannot override them.
+var registeredClaims = map[string]struct{}{
+	"user_id": {}, "sid": {}, "auth_time": {}, "typ": {}, "scope": {}, "roles": {}, "tenant": {}, "key_id": {}, "act": {},
+	"aud": {}, "exp": {}, "jti": {}, "iat": {}, "iss": {}, "nbf": {}, "sub": {},
+}
+
+// IsRegisteredClaim reports whether the claim name is backed by a CustomClaims field.
+func IsRegisteredClaim(name string) bool {
+	_, ok := registeredClaims[name]
+	return ok
+}
+
+// plainClaims has the fields of CustomClaims without its JSON methods.
+type plainClaims CustomClaims
+
+// MarshalJSON flattens Extra into the top-level claims.
+func (c CustomClaims) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(plainClaims(c))
+	if err != nil || len(c.Extra) == 0 {
+		return data, err
+	}
+
+	fields := make(map[string]interface{}, len(c.Extra))
+	for name, value := range c.Extra {
+		if !IsRegisteredClaim(name) {
+			fields[name] = value
+		}
+	}
+	var registered map[string]json.RawMessage
+	if err := json.Unmarshal(data, &registered); err != nil {
+		return nil, err
+	}
+	for name, value := range registered {
+		fields[name] = value
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON fills the claim fields and collects every other top-level claim into Extra.
+func (c *CustomClaims) UnmarshalJSON(data []byte) error {
+	var plain plainClaims
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for name := range fields {
+		if IsRegisteredClaim(name) {
+			delete(fields, name)
+		}
+	}
+	if len(fields) > 0 {
+		plain.Extra = fields
+	}
+
+	*c = CustomClaims(plain)
+	return nil
 }